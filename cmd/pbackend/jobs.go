@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// jobs provides inspection of the dead-letter list of the job queue:
+//
+//	pbackend jobs dead           - lists jobs which failed all attempts
+//	pbackend jobs redrive [ID]   - moves a job (or all jobs) back into the queue
+func jobs() {
+	ctx := context.Background()
+	config.Initialize("config/api.env", "config/worker.env")
+
+	logging.InitializeStdout()
+	logger := log.Logger
+	ctx = logger.WithContext(ctx)
+
+	if len(os.Args) < 3 {
+		jobsUsage()
+	}
+
+	// the database is needed by the postgres job queue
+	err := db.Initialize(ctx, "public")
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error initializing database")
+	}
+	defer db.Close()
+
+	err = jq.Initialize(ctx, &logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Error initializing job queue")
+	}
+	// handlers are not started, registration is needed to decode job arguments
+	jq.RegisterJobs(&logger)
+
+	switch os.Args[2] {
+	case "dead":
+		deadJobs, err := jq.DeadLetters(ctx)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Error listing dead-letter jobs")
+		}
+		for _, job := range deadJobs {
+			fmt.Printf("%s\t%s\taccount=%d\tattempts=%d\t%+v\n", job.ID, job.Type, job.AccountID, job.Attempt, job.Args)
+		}
+	case "redrive":
+		id := uuid.Nil
+		if len(os.Args) > 3 {
+			id, err = uuid.Parse(os.Args[3])
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Invalid job ID")
+			}
+		}
+		count, err := jq.Redrive(ctx, id)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Error redriving dead-letter jobs")
+		}
+		fmt.Printf("Moved %d job(s) back into the queue\n", count)
+	default:
+		jobsUsage()
+	}
+}
+
+func jobsUsage() {
+	fmt.Println("Usage: pbackend jobs [dead|redrive [ID]]")
+	os.Exit(1)
+}
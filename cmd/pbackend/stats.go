@@ -12,8 +12,10 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/background"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/kafka"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
 	"github.com/RHEnVision/provisioning-backend/internal/queue/jq"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
 	"github.com/go-chi/chi/v5"
@@ -34,8 +36,28 @@ func stats() {
 	tel := telemetry.Initialize(&log.Logger)
 	defer tel.Close(ctx)
 
+	// initialize the database
+	logger.Debug().Msg("Initializing database connection")
+	err := db.Initialize(ctx, "public")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error initializing database")
+	}
+	defer db.Close()
+
+	// initialize platform kafka and notifications (failed launch of stuck reservations)
+	if config.Kafka.Enabled {
+		err = kafka.InitializeKafkaBroker(ctx)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Unable to initialize the platform kafka")
+		}
+
+		if config.Application.Notifications.Enabled {
+			notifications.Initialize(ctx)
+		}
+	}
+
 	// initialize the job queue but don't register any workers
-	err := jq.Initialize(ctx, &logger)
+	err = jq.Initialize(ctx, &logger)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error initializing job queue")
 	}
@@ -73,14 +95,6 @@ func stats() {
 
 	metrics.RegisterStatsMetrics()
 
-	// initialize the database
-	logger.Debug().Msg("Initializing database connection")
-	err = db.Initialize(ctx, "public")
-	if err != nil {
-		log.Fatal().Err(err).Msg("Error initializing database")
-	}
-	defer db.Close()
-
 	// initialize background goroutines
 	bgCtx, bgCancel := context.WithCancel(ctx)
 	background.InitializeStats(bgCtx)
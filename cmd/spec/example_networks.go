@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+)
+
+var NetworkListResponse = payloads.NetworkListResponse{
+	Data: []*payloads.NetworkResponse{
+		{
+			ID:   "vpc-0a4caa2cf5b097ce1",
+			Name: "production",
+			CIDR: "10.0.0.0/16",
+		},
+	},
+}
+
+var SubnetListResponse = payloads.SubnetListResponse{
+	Data: []*payloads.SubnetResponse{
+		{
+			ID:        "subnet-06e6b4eb3f8b6a0fb",
+			Name:      "production-a",
+			NetworkID: "vpc-0a4caa2cf5b097ce1",
+			CIDR:      "10.0.1.0/24",
+			Zone:      "us-east-1a",
+		},
+	},
+}
+
+var SecurityGroupListResponse = payloads.SecurityGroupListResponse{
+	Data: []*payloads.SecurityGroupResponse{
+		{
+			ID:          "sg-0d2e7b9f3c1a8e4b5",
+			Name:        "ssh",
+			NetworkID:   "vpc-0a4caa2cf5b097ce1",
+			Description: "Allow SSH",
+		},
+	},
+}
@@ -83,6 +83,15 @@ var AwsReservationRequestPayloadExample = payloads.AWSReservationRequest{
 	LaunchTemplateID: "",
 	Name:             "my-instance",
 	PowerOff:         false,
+	Volumes: &models.Volumes{
+		RootSizeGB: 200,
+		RootType:   "gp3",
+		DataDisks:  []models.DataDisk{{SizeGB: 500, Type: "st1"}},
+		Encrypted:  true,
+	},
+	Tags: map[string]string{
+		"cost-center": "42",
+	},
 }
 
 var AwsReservationResponsePayloadPendingExample = payloads.AWSReservationResponse{
@@ -94,6 +103,7 @@ var AwsReservationResponsePayloadPendingExample = payloads.AWSReservationRespons
 	ImageID:          "ami-7846387643232",
 	LaunchTemplateID: "",
 	Name:             "my-instance",
+	PricingModel:     models.PricingModelOnDemand,
 	PowerOff:         false,
 }
 
@@ -108,6 +118,7 @@ var AwsReservationResponsePayloadDoneExample = payloads.AWSReservationResponse{
 	LaunchTemplateID: "",
 	AWSReservationID: "r-3743243324231",
 	Name:             "my-instance",
+	PricingModel:     models.PricingModelOnDemand,
 	PowerOff:         false,
 	Instances: []payloads.InstanceResponse{
 		{InstanceID: "i-2324343212", Detail: models.ReservationInstanceDetail{
@@ -115,6 +126,18 @@ var AwsReservationResponsePayloadDoneExample = payloads.AWSReservationResponse{
 			PublicIPv4:  "184.73.141.211",
 			PrivateIPv4: "172.31.36.10",
 			PrivateIPv6: "2001:0db8:85a3:0000:0000:8a2e:0370:7334",
+			State:       models.InstanceStateRunning,
+		}},
+	},
+}
+
+var InstanceListResponseExample = payloads.InstanceListResponse{
+	Data: []*payloads.InstanceResponse{
+		{InstanceID: "i-2324343212", Detail: models.ReservationInstanceDetail{
+			PublicDNS:   "ec2-184-73-141-211.compute-1.amazonaws.com",
+			PublicIPv4:  "184.73.141.211",
+			PrivateIPv4: "172.31.36.10",
+			State:       models.InstanceStateRunning,
 		}},
 	},
 }
@@ -128,7 +151,12 @@ var AzureReservationRequestPayloadExample = payloads.AzureReservationRequest{
 	Amount:        1,
 	ImageID:       "composer-api-081fc867-838f-44a5-af03-8b8def808431",
 	Name:          "my-instance",
-	PowerOff:      false,
+	SubnetID:      "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet/subnets/workloads",
+	Spot: &models.SpotOptions{
+		MaxPrice:           0.05,
+		InterruptionAction: models.InterruptionActionStop,
+	},
+	PowerOff: false,
 }
 
 var AzureReservationResponsePayloadPendingExample = payloads.AzureReservationResponse{
@@ -140,8 +168,13 @@ var AzureReservationResponsePayloadPendingExample = payloads.AzureReservationRes
 	Amount:       1,
 	ImageID:      "composer-api-081fc867-838f-44a5-af03-8b8def808431",
 	Name:         "my-instance",
-	PowerOff:     false,
-	Instances:    nil,
+	PricingModel: models.PricingModelSpot,
+	Spot: &models.SpotOptions{
+		MaxPrice:           0.05,
+		InterruptionAction: models.InterruptionActionStop,
+	},
+	PowerOff:  false,
+	Instances: nil,
 }
 
 var AzureReservationResponsePayloadDoneExample = payloads.AzureReservationResponse{
@@ -153,13 +186,19 @@ var AzureReservationResponsePayloadDoneExample = payloads.AzureReservationRespon
 	Amount:       1,
 	ImageID:      "composer-api-081fc867-838f-44a5-af03-8b8def808431",
 	Name:         "my-instance",
-	PowerOff:     false,
+	PricingModel: models.PricingModelSpot,
+	Spot: &models.SpotOptions{
+		MaxPrice:           0.05,
+		InterruptionAction: models.InterruptionActionStop,
+	},
+	PowerOff: false,
 	Instances: []payloads.InstanceResponse{{
 		InstanceID: "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/redhat-deployed/providers/Microsoft.Compute/images/composer-api-92ea98f8-7697-472e-80b1-7454fa0e7fa7",
 		Detail: models.ReservationInstanceDetail{
 			PublicDNS:   "",
 			PublicIPv4:  "10.0.0.88",
 			PrivateIPv4: "172.22.0.1",
+			State:       models.InstanceStateRunning,
 		},
 	}},
 }
@@ -173,6 +212,10 @@ var GCPReservationRequestPayloadExample = payloads.GCPReservationRequest{
 	Amount:           1,
 	ImageID:          "08a48fed-de87-40ab-a571-f64e30bd0aa8",
 	LaunchTemplateID: "",
+	Placement: &models.Placement{
+		Strategy: models.PlacementStrategySpread,
+		Zones:    []string{"us-east4-a", "us-east4-b", "us-east4-c"},
+	},
 }
 
 var GCPReservationResponsePayloadPendingExample = payloads.GCPReservationResponse{
@@ -183,6 +226,7 @@ var GCPReservationResponsePayloadPendingExample = payloads.GCPReservationRespons
 	MachineType:      "e2-micro",
 	Amount:           1,
 	NamePattern:      "my-instance",
+	PricingModel:     models.PricingModelOnDemand,
 	ImageID:          "08a48fed-de87-40ab-a571-f64e30bd0aa8",
 	LaunchTemplateID: "4883371230199373111",
 	GCPOperationName: "operation-1686646674436-5fdff07e43209-66146b7e-f3f65ec5",
@@ -199,6 +243,7 @@ var GCPReservationResponsePayloadDoneExample = payloads.GCPReservationResponse{
 	ImageID:          "08a48fed-de87-40ab-a571-f64e30bd0aa8",
 	LaunchTemplateID: "4883371230199373111",
 	NamePattern:      "my-instance",
+	PricingModel:     models.PricingModelOnDemand,
 	GCPOperationName: "operation-1686646674436-5fdff07e43209-66146b7e-f3f65ec5",
 	PowerOff:         false,
 	Instances: []payloads.InstanceResponse{
@@ -206,6 +251,7 @@ var GCPReservationResponsePayloadDoneExample = payloads.GCPReservationResponse{
 			PublicDNS:   "",
 			PublicIPv4:  "10.0.0.88",
 			PrivateIPv4: "10.198.0.2",
+			State:       models.InstanceStateRunning,
 		}},
 	},
 }
@@ -16,6 +16,9 @@ import (
 // addPayloads - MAKE SURE THE TYPE HAS JSON/YAML Go STRUCT TAGS (or "map key XXX not found" error occurs)
 func addPayloads(gen *APISchemaGen) {
 	gen.addSchema("v1.PubkeyRequest", &payloads.PubkeyRequest{})
+	gen.addSchema("v1.PubkeyUpdateRequest", &payloads.PubkeyUpdateRequest{})
+	gen.addSchema("v1.PubkeyGenerateRequest", &payloads.PubkeyGenerateRequest{})
+	gen.addSchema("v1.PubkeyGenerateResponse", &payloads.PubkeyGenerateResponse{})
 	gen.addSchema("v1.PubkeyResponse", &payloads.PubkeyResponse{})
 	gen.addSchema("v1.SourceResponse", &payloads.SourceResponse{})
 	gen.addSchema("v1.InstanceTypeResponse", &payloads.InstanceTypeResponse{})
@@ -31,22 +34,36 @@ func addPayloads(gen *APISchemaGen) {
 	gen.addSchema("v1.AccountIDTypeResponse", &payloads.AccountIdentityResponse{})
 	gen.addSchema("v1.SourceUploadInfoResponse", &payloads.SourceUploadInfoResponse{})
 	gen.addSchema("v1.LaunchTemplatesResponse", &payloads.LaunchTemplateResponse{})
+	gen.addSchema("v1.NetworkResponse", &payloads.NetworkResponse{})
+	gen.addSchema("v1.SubnetResponse", &payloads.SubnetResponse{})
+	gen.addSchema("v1.SecurityGroupResponse", &payloads.SecurityGroupResponse{})
 
 	gen.addSchema("v1.ListSourceResponse", &payloads.SourceListResponse{})
 	gen.addSchema("v1.ListPubkeyResponse", &payloads.PubkeyListResponse{})
 	gen.addSchema("v1.ListInstaceTypeResponse", &payloads.InstanceTypeListResponse{})
 	gen.addSchema("v1.ListGenericReservationResponse", &payloads.GenericReservationListResponse{})
 	gen.addSchema("v1.ListLaunchTemplateResponse", &payloads.LaunchTemplateListResponse{})
+	gen.addSchema("v1.ListInstanceResponse", &payloads.InstanceListResponse{})
+	gen.addSchema("v1.ListNetworkResponse", &payloads.NetworkListResponse{})
+	gen.addSchema("v1.ListSubnetResponse", &payloads.SubnetListResponse{})
+	gen.addSchema("v1.ListSecurityGroupResponse", &payloads.SecurityGroupListResponse{})
 }
 
 func addExamples(gen *APISchemaGen) {
 	gen.addExample("v1.PubkeyRequestExample", PubkeyRequest)
+	gen.addExample("v1.PubkeyUpdateRequestExample", PubkeyUpdateRequest)
+	gen.addExample("v1.PubkeyGenerateRequestExample", PubkeyGenerateRequest)
+	gen.addExample("v1.PubkeyGenerateResponseExample", PubkeyGenerateResponse)
 	gen.addExample("v1.PubkeyResponseExample", PubkeyResponse)
+	gen.addExample("v1.PubkeyDetailResponseExample", PubkeyDetailResponse)
 	gen.addExample("v1.PubkeyListResponseExample", PubkeyListResponse)
 	gen.addExample("v1.SourceListResponseExample", SourceListResponse)
 	gen.addExample("v1.SourceUploadInfoAWSResponse", SourceUploadInfoAWSResponse)
 	gen.addExample("v1.SourceUploadInfoAzureResponse", SourceUploadInfoAzureResponse)
 	gen.addExample("v1.LaunchTemplateListResponse", LaunchTemplateListResponse)
+	gen.addExample("v1.NetworkListResponse", NetworkListResponse)
+	gen.addExample("v1.SubnetListResponse", SubnetListResponse)
+	gen.addExample("v1.SecurityGroupListResponse", SecurityGroupListResponse)
 	gen.addExample("v1.AvailabilityStatusRequest", AvailabilityStatusRequest)
 	gen.addExample("v1.GenericReservationResponsePayloadSuccessExample", GenericReservationResponsePayloadSuccessExample)
 	gen.addExample("v1.GenericReservationResponsePayloadPendingExample", GenericReservationResponsePayloadPendingExample)
@@ -62,6 +79,7 @@ func addExamples(gen *APISchemaGen) {
 	gen.addExample("v1.GCPReservationResponsePayloadPendingExample", GCPReservationResponsePayloadPendingExample)
 	gen.addExample("v1.GCPReservationResponsePayloadDoneExample", GCPReservationResponsePayloadDoneExample)
 	gen.addExample("v1.NoopReservationResponsePayloadExample", NoopReservationResponsePayloadExample)
+	gen.addExample("v1.InstanceListResponseExample", InstanceListResponseExample)
 	gen.addExample("v1.InstanceTypesAWSResponse", InstanceTypesAWSResponse)
 	gen.addExample("v1.InstanceTypesAzureResponse", InstanceTypesAzureResponse)
 	gen.addExample("v1.InstanceTypesGCPResponse", InstanceTypesGCPResponse)
@@ -71,6 +89,16 @@ func addParameters(gen *APISchemaGen) {
 	gen.addQueryParameter("Limit", LimitQueryParam)
 	gen.addQueryParameter("Offset", OffsetQueryParam)
 	gen.addQueryParameter("Token", TokenQueryParam)
+	gen.addQueryParameter("Cursor", CursorQueryParam)
+	gen.addQueryParameter("IdempotencyKey", IdempotencyKeyHeaderParam)
+	gen.addQueryParameter("Provider", ProviderQueryParam)
+	gen.addQueryParameter("Status", StatusQueryParam)
+	gen.addQueryParameter("SourceID", SourceIDQueryParam)
+	gen.addQueryParameter("InstanceID", InstanceIDQueryParam)
+	gen.addQueryParameter("CreatedAfter", CreatedAfterQueryParam)
+	gen.addQueryParameter("CreatedBefore", CreatedBeforeQueryParam)
+	gen.addQueryParameter("Sort", SortQueryParam)
+	gen.addQueryParameter("NetworkID", NetworkIDQueryParam)
 }
 
 // addErrorSchemas all generic errors, that can be returned.
@@ -111,13 +139,17 @@ func NewSchemaGenerator() *APISchemaGen {
 
 // Schema customizer allowing tagging with description and nullable to work
 var enableNullableAndDescriptionOpts = openapi3gen.SchemaCustomizer(
-	func(_name string, _t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
+	func(_name string, t reflect.Type, tag reflect.StructTag, schema *openapi3.Schema) error {
 		if tag.Get("nullable") == "true" {
 			schema.Nullable = true
 		}
 		if desc, ok := tag.Lookup("description"); ok && desc != "-" {
 			schema.Description = desc
 		}
+		// additionalProperties cannot be marshalled into YAML, maps are free-form objects
+		if t.Kind() == reflect.Map {
+			schema.AdditionalProperties = openapi3.AdditionalProperties{}
+		}
 		return nil
 	},
 )
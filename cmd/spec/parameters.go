@@ -35,3 +35,93 @@ var TokenQueryParam = Parameter{
 	Required:    false,
 	In:          "query",
 }
+
+var CursorQueryParam = Parameter{
+	Name:        "cursor",
+	Description: "Opaque cursor for keyset pagination returned in the next page link; an empty cursor lists the first page. When present, offset is ignored and total is not returned.",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var IdempotencyKeyHeaderParam = Parameter{
+	Name:        "Idempotency-Key",
+	Description: "Unique key (max. 255 characters) which makes the request idempotent; a repeated request with the same key returns the original reservation instead of creating a new one",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "header",
+}
+
+var ProviderQueryParam = Parameter{
+	Name:        "provider",
+	Description: "Filter reservations by provider type: aws, azure, gcp or noop.",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var StatusQueryParam = Parameter{
+	Name:        "status",
+	Description: "Filter reservations by result: pending, success or failure.",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var SourceIDQueryParam = Parameter{
+	Name:        "source_id",
+	Description: "Filter reservations by source ID.",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var InstanceIDQueryParam = Parameter{
+	Name:        "instance_id",
+	Description: "Filter reservations which launched the instance ID.",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var CreatedAfterQueryParam = Parameter{
+	Name:        "created_after",
+	Description: "Filter reservations created at or after the time (RFC 3339).",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var CreatedBeforeQueryParam = Parameter{
+	Name:        "created_before",
+	Description: "Filter reservations created before the time (RFC 3339).",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var SortQueryParam = Parameter{
+	Name:        "sort",
+	Description: "Sort attribute: id, created_at or finished_at. Prefix with '-' for descending order.",
+	Default:     "id",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
+
+var NetworkIDQueryParam = Parameter{
+	Name:        "network_id",
+	Description: "Filter by network ID (VPC ID for AWS).",
+	Default:     "",
+	Type:        "string",
+	Required:    false,
+	In:          "query",
+}
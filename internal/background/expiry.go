@@ -0,0 +1,191 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	rhidentity "github.com/redhatinsights/platform-go-middlewares/identity"
+	"github.com/rs/zerolog"
+)
+
+// maximum amount of expired reservations processed in one tick
+const expiryBatchSize = 100
+
+func reservationExpiry(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started reservation expiry %s", sleep.String())
+	defer func() {
+		logger.Debug().Msgf("Reservation expiry routine exited")
+	}()
+
+	ticker := time.NewTicker(sleep)
+
+	expireReservations(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			expireReservations(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func expireReservations(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	rDao := dao.GetReservationDao(ctx)
+	reservations, err := rDao.UnscopedListExpired(ctx, expiryBatchSize)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error while listing expired reservations")
+		return
+	}
+
+	for _, reservation := range reservations {
+		err = expireReservation(ctx, reservation)
+		if err != nil {
+			// the reservation is picked again in the next tick
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to terminate instances of expired reservation")
+			continue
+		}
+		logger.Info().Int64("reservation_id", reservation.ID).Msgf("Terminated instances of expired reservation %d", reservation.ID)
+	}
+}
+
+// expireReservation terminates all instances of the reservation and marks it as expired.
+func expireReservation(ctx context.Context, reservation *models.Reservation) error {
+	ctx, err := accountContext(ctx, reservation.AccountID)
+	if err != nil {
+		return err
+	}
+
+	rDao := dao.GetReservationDao(ctx)
+	instances, err := rDao.ListInstances(ctx, reservation.ID)
+	if err != nil {
+		return fmt.Errorf("cannot list reservation instances: %w", err)
+	}
+
+	if len(instances) > 0 {
+		err = terminateInstances(ctx, reservation, instances)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = rDao.UnscopedMarkExpired(ctx, reservation.ID)
+	if err != nil {
+		return fmt.Errorf("cannot mark reservation as expired: %w", err)
+	}
+	return nil
+}
+
+// accountContext returns context copy with account id and identity of the account, the identity
+// is needed for communication with platform services like sources.
+func accountContext(ctx context.Context, accountId int64) (context.Context, error) {
+	ctx = identity.WithAccountId(ctx, accountId)
+	account, err := dao.GetAccountDao(ctx).GetById(ctx, accountId)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get account %d: %w", accountId, err)
+	}
+
+	principal := identity.Principal{
+		Identity: rhidentity.Identity{
+			OrgID:         account.OrgID,
+			AccountNumber: account.AccountNumber.String,
+			Internal: rhidentity.Internal{
+				OrgID: account.OrgID,
+			},
+		},
+	}
+	return identity.WithIdentity(ctx, principal), nil
+}
+
+func terminateInstances(ctx context.Context, reservation *models.Reservation, instances []*models.ReservationInstance) error {
+	logger := zerolog.Ctx(ctx)
+
+	ids := make([]string, len(instances))
+	for i, instance := range instances {
+		ids[i] = instance.InstanceID
+	}
+
+	// find source and location of the instances
+	sourceID, location, err := dao.ReservationSourceAndLocation(ctx, reservation)
+	if err != nil {
+		return fmt.Errorf("cannot find reservation source: %w", err)
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot create sources client: %w", err)
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(ctx, sourceID)
+	if err != nil {
+		return fmt.Errorf("cannot get authentication for source %s: %w", sourceID, err)
+	}
+
+	if typeErr := authentication.MustBe(reservation.Provider); typeErr != nil {
+		return fmt.Errorf("unexpected authentication for source %s: %w", sourceID, typeErr)
+	}
+
+	//nolint:exhaustive
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, location)
+		if err != nil {
+			return fmt.Errorf("cannot create new ec2 client from config: %w", err)
+		}
+		err = ec2Client.TerminateInstances(ctx, ids)
+		if errors.Is(err, clients.ErrNotFound) {
+			// some instances were already deleted by the user, the whole call fails then
+			for _, id := range ids {
+				err = ec2Client.TerminateInstances(ctx, []string{id})
+				if errors.Is(err, clients.ErrNotFound) {
+					logger.Warn().Err(err).Msgf("Instance %s of expired reservation not found", id)
+				} else if err != nil {
+					return fmt.Errorf("cannot terminate instance %s: %w", id, err)
+				}
+			}
+		} else if err != nil {
+			return fmt.Errorf("cannot terminate instances: %w", err)
+		}
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate Azure client: %w", err)
+		}
+		for _, id := range ids {
+			err = azureClient.DeleteVM(ctx, id)
+			if errors.Is(err, clients.ErrNotFound) {
+				// already deleted by the user
+				logger.Warn().Err(err).Msgf("VM %s of expired reservation not found", id)
+			} else if err != nil {
+				return fmt.Errorf("cannot delete VM %s: %w", id, err)
+			}
+		}
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return fmt.Errorf("cannot create new gcp client: %w", err)
+		}
+		for _, instance := range instances {
+			err = gcpClient.DeleteInstance(ctx, instance.InstanceID, instance.ZoneOrDefault(location))
+			if errors.Is(err, clients.ErrNotFound) {
+				// already deleted by the user
+				logger.Warn().Err(err).Msgf("Instance %s of expired reservation not found", instance.InstanceID)
+			} else if err != nil {
+				return fmt.Errorf("cannot delete instance %s: %w", instance.InstanceID, err)
+			}
+		}
+	}
+
+	return nil
+}
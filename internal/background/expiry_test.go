@@ -0,0 +1,130 @@
+package background
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addExpiryReservation(t *testing.T, ctx context.Context, sourceID string, expiresAt time.Time, instanceID string) *models.AWSReservation {
+	t.Helper()
+
+	reservation := &models.AWSReservation{
+		PubkeyID: 1,
+		SourceID: sourceID,
+		ImageID:  "ami-random",
+		Detail: &models.AWSDetail{
+			Region: "us-east-1",
+			Amount: 1,
+		},
+	}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	reservation.ExpiresAt = sql.NullTime{Time: expiresAt, Valid: true}
+	err := stubs.AddAWSReservation(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	err = dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{
+		ReservationID: reservation.ID,
+		InstanceID:    instanceID,
+	})
+	require.NoError(t, err, "failed to create stub instance")
+
+	return reservation
+}
+
+func TestExpireReservations(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAWS)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	expired := addExpiryReservation(t, ctx, source.ID, time.Now().Add(-time.Minute), "i-expired")
+	valid := addExpiryReservation(t, ctx, source.ID, time.Now().Add(time.Hour), "i-valid")
+
+	expireReservations(ctx)
+
+	assert.Equal(t, "terminate", clientStubs.StubLastInstanceActionEC2(ctx, "i-expired"))
+	assert.True(t, expired.ExpiredAt.Valid, "Expired reservation was not marked")
+
+	assert.Empty(t, clientStubs.StubLastInstanceActionEC2(ctx, "i-valid"))
+	assert.False(t, valid.ExpiredAt.Valid, "Valid reservation was marked as expired")
+}
+
+func TestExpireReservationsDeletedInstance(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAWS)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	reservation := addExpiryReservation(t, ctx, source.ID, time.Now().Add(-time.Minute), "i-running")
+	err = dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{
+		ReservationID: reservation.ID,
+		InstanceID:    "i-deleted",
+	})
+	require.NoError(t, err, "failed to create stub instance")
+
+	// the instance was already deleted by the user
+	clientStubs.StubDeleteInstanceEC2(ctx, "i-deleted")
+
+	expireReservations(ctx)
+
+	assert.Equal(t, "terminate", clientStubs.StubLastInstanceActionEC2(ctx, "i-running"))
+	assert.True(t, reservation.ExpiredAt.Valid, "Reservation with deleted instance was not marked")
+}
+
+func TestExpireReservationsDeletedVM(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithAzureClient(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAzure)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	reservation := &models.AzureReservation{
+		PubkeyID: 1,
+		SourceID: source.ID,
+		ImageID:  "/subscriptions/subUUID/resourceGroups/redhat/providers/Microsoft.Compute/images/image",
+		Detail: &models.AzureDetail{
+			Location: "useast",
+			Amount:   1,
+		},
+	}
+	reservation.Provider = models.ProviderTypeAzure
+	reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	reservation.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	// the virtual machine was already deleted by the user
+	err = rDao.CreateInstance(ctx, &models.ReservationInstance{
+		ReservationID: reservation.ID,
+		InstanceID:    "/subscriptions/subUUID/resourceGroups/redhat/providers/Microsoft.Compute/virtualMachines/deleted",
+	})
+	require.NoError(t, err, "failed to create stub instance")
+
+	expireReservations(ctx)
+
+	assert.True(t, reservation.ExpiredAt.Valid, "Reservation with deleted VM was not marked")
+}
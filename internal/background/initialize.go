@@ -46,4 +46,19 @@ func InitializeStats(ctx context.Context) {
 	if config.Reservation.CleanupEnabled {
 		go dbCleanup(ctx, config.Reservation.CleanupInterval)
 	}
+
+	// terminate instances of expired reservations
+	if config.Reservation.ExpiryEnabled {
+		go reservationExpiry(ctx, config.Reservation.ExpiryInterval)
+	}
+
+	// finish reservations with lost jobs
+	if config.Reservation.StuckEnabled {
+		go stuckReaper(ctx, config.Reservation.StuckInterval)
+	}
+
+	// remove pubkey resources which are no longer present in clouds
+	if config.Pubkey.ReconcileEnabled {
+		go pubkeyReconciliation(ctx, config.Pubkey.ReconcileInterval)
+	}
 }
@@ -0,0 +1,174 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/rs/zerolog"
+)
+
+var ErrReconcileUnsupportedProvider = errors.New("pubkey reconciliation is not supported for provider")
+
+// amount of pubkeys fetched from the database at once
+const reconcileBatchSize = 100
+
+func pubkeyReconciliation(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started pubkey reconciliation %s", sleep.String())
+	defer func() {
+		logger.Debug().Msgf("Pubkey reconciliation routine exited")
+	}()
+
+	ticker := time.NewTicker(sleep)
+
+	reconcilePubkeys(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			reconcilePubkeys(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// reconcilePubkeys walks all pubkeys of all accounts and verifies their resources in clouds.
+func reconcilePubkeys(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	pkDao := dao.GetPubkeyDao(ctx)
+
+	var afterId int64
+	for {
+		pubkeys, err := pkDao.UnscopedList(ctx, afterId, reconcileBatchSize)
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while listing pubkeys for reconciliation")
+			return
+		}
+
+		for _, pubkey := range pubkeys {
+			err = reconcilePubkey(ctx, pubkey)
+			if err != nil {
+				// the pubkey is picked again in the next tick
+				logger.Error().Err(err).Int64("pubkey_id", pubkey.ID).Msg("Unable to reconcile pubkey resources")
+			}
+		}
+
+		if len(pubkeys) < reconcileBatchSize {
+			return
+		}
+		afterId = pubkeys[len(pubkeys)-1].ID
+	}
+}
+
+// findPubkeyResource looks up the resource in the cloud and returns its current handle,
+// ErrPubkeyNotFound is returned when the resource no longer exists.
+func findPubkeyResource(ctx context.Context, authentication *clients.Authentication, pubkey *models.Pubkey, res *models.PubkeyResource) (string, error) {
+	switch res.Provider {
+	case models.ProviderTypeAWS:
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, res.Region)
+		if err != nil {
+			return "", fmt.Errorf("cannot create new ec2 client from config: %w", err)
+		}
+
+		// resources without handle are key-pairs which were found by fingerprint, not imported by us
+		if res.Handle == "" {
+			_, err = ec2Client.GetPubkeyName(ctx, pubkey.FindAwsFingerprint(ctx))
+			return "", err
+		}
+		return ec2Client.GetPubkeyHandle(ctx, res.FormattedTag())
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return "", fmt.Errorf("cannot create new Azure client: %w", err)
+		}
+		return azureClient.GetSSHKey(ctx, res.Handle)
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return "", fmt.Errorf("cannot create new GCP client: %w", err)
+		}
+
+		found, err := gcpClient.HasProjectSSHKey(ctx, res.Handle)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", httpClients.ErrPubkeyNotFound
+		}
+		return res.Handle, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrReconcileUnsupportedProvider, res.Provider)
+	}
+}
+
+// reconcilePubkey checks presence of all resources of the pubkey in clouds, resources which
+// are no longer present are deleted, present resources are marked as verified. Resources which
+// cannot be checked (missing or unauthorized source) are left untouched.
+func reconcilePubkey(ctx context.Context, pubkey *models.Pubkey) error {
+	logger := zerolog.Ctx(ctx).With().Int64("pubkey_id", pubkey.ID).Logger()
+	ctx = logger.WithContext(ctx)
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	resources, err := pkDao.UnscopedListResourcesByPubkeyId(ctx, pubkey.ID)
+	if err != nil {
+		return fmt.Errorf("cannot list pubkey resources: %w", err)
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+
+	ctx, err = accountContext(ctx, pubkey.AccountID)
+	if err != nil {
+		return err
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot create sources client: %w", err)
+	}
+
+	for _, res := range resources {
+		authentication, errAuth := sourcesClient.GetAuthentication(ctx, res.SourceID)
+		if errAuth != nil {
+			logger.Warn().Err(errAuth).Int64("resource_id", res.ID).Msgf("Skipping pubkey resource of source %s without authentication", res.SourceID)
+			continue
+		}
+
+		handle, errFind := findPubkeyResource(ctx, authentication, pubkey, res)
+		if errors.Is(errFind, httpClients.ErrPubkeyNotFound) {
+			logger.Info().Int64("resource_id", res.ID).Msgf("Deleting pubkey resource %s no longer present in region %s", res.Handle, res.Region)
+			err = pkDao.UnscopedDeleteResource(ctx, res.ID)
+			if err != nil {
+				return fmt.Errorf("cannot delete stale pubkey resource: %w", err)
+			}
+			continue
+		} else if errFind != nil {
+			logger.Warn().Err(errFind).Int64("resource_id", res.ID).Msg("Skipping pubkey resource which cannot be verified")
+			continue
+		}
+
+		if res.Handle != handle {
+			res.Handle = handle
+			err = pkDao.UnscopedUpdateResource(ctx, res)
+			if err != nil {
+				return fmt.Errorf("cannot update pubkey resource: %w", err)
+			}
+		}
+
+		err = pkDao.UnscopedVerifyResource(ctx, res.ID)
+		if err != nil {
+			return fmt.Errorf("cannot verify pubkey resource: %w", err)
+		}
+	}
+
+	return nil
+}
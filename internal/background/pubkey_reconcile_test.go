@@ -0,0 +1,164 @@
+package background
+
+import (
+	"context"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcilePubkeys(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAWS)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	pk := factories.NewPubkeyRSA()
+	err = stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	ec2Client, err := clients.GetEC2Client(ctx, nil, "us-east-1")
+	require.NoError(t, err, "failed to get stubbed ec2 client")
+
+	present := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeAWS,
+		SourceID: source.ID,
+		Region:   "us-east-1",
+	}
+	present.RandomizeTag()
+	present.Handle, err = ec2Client.ImportPubkey(ctx, pk, present.FormattedTag())
+	require.NoError(t, err, "failed to import stubbed key")
+	err = stubs.AddPubkeyResource(ctx, present)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	stale := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeAWS,
+		SourceID: source.ID,
+		Region:   "eu-central-1",
+		Handle:   "key-deleted",
+	}
+	stale.RandomizeTag()
+	err = stubs.AddPubkeyResource(ctx, stale)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	reconcilePubkeys(ctx)
+
+	resources, err := dao.GetPubkeyDao(ctx).UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+	require.NoError(t, err, "failed to list key resources")
+	require.Len(t, resources, 1, "stale resource was not deleted")
+	assert.Equal(t, present.ID, resources[0].ID)
+	assert.True(t, resources[0].VerifiedAt.Valid, "present resource was not verified")
+}
+
+func TestReconcilePubkeysAzure(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithAzureClient(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAzure)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	pk := factories.NewPubkeyRSA()
+	err = stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	azureClient, err := clients.GetAzureClient(ctx, nil)
+	require.NoError(t, err, "failed to get stubbed azure client")
+
+	present := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeAzure,
+		SourceID: source.ID,
+		Region:   "eastus",
+	}
+	present.RandomizeTag()
+	present.Handle, err = azureClient.ImportSSHKey(ctx, "redhat-deployed", "eastus", pk, present.FormattedTag())
+	require.NoError(t, err, "failed to import stubbed key")
+	err = stubs.AddPubkeyResource(ctx, present)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	stale := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeAzure,
+		SourceID: source.ID,
+		Region:   "westus",
+		Handle:   "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/redhat-deployed/providers/Microsoft.Compute/sshPublicKeys/deleted",
+	}
+	stale.RandomizeTag()
+	err = stubs.AddPubkeyResource(ctx, stale)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	reconcilePubkeys(ctx)
+
+	resources, err := dao.GetPubkeyDao(ctx).UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+	require.NoError(t, err, "failed to list key resources")
+	require.Len(t, resources, 1, "stale resource was not deleted")
+	assert.Equal(t, present.ID, resources[0].ID)
+	assert.True(t, resources[0].VerifiedAt.Valid, "present resource was not verified")
+}
+
+func TestReconcilePubkeysGCP(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithGCPCCustomerClient(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeGCP)
+	require.NoError(t, err, "failed to add stubbed source")
+	staleSource, err := clientStubs.AddSource(ctx, models.ProviderTypeGCP)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	pk := factories.NewPubkeyRSA()
+	err = stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	gcpClient, err := clients.GetGCPClient(ctx, nil)
+	require.NoError(t, err, "failed to get stubbed GCP client")
+
+	present := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeGCP,
+		SourceID: source.ID,
+		Handle:   "provisioning:" + pk.Body,
+	}
+	present.RandomizeTag()
+	err = gcpClient.AddProjectSSHKey(ctx, present.Handle)
+	require.NoError(t, err, "failed to add stubbed project key")
+	err = stubs.AddPubkeyResource(ctx, present)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	stale := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeGCP,
+		SourceID: staleSource.ID,
+		Handle:   "deleted:" + pk.Body,
+	}
+	stale.RandomizeTag()
+	err = stubs.AddPubkeyResource(ctx, stale)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	reconcilePubkeys(ctx)
+
+	resources, err := dao.GetPubkeyDao(ctx).UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+	require.NoError(t, err, "failed to list key resources")
+	require.Len(t, resources, 1, "stale resource was not deleted")
+	assert.Equal(t, present.ID, resources[0].ID)
+	assert.True(t, resources[0].VerifiedAt.Valid, "present resource was not verified")
+}
@@ -25,8 +25,11 @@ func jobQueueMetricLoop(ctx context.Context, sleep time.Duration, name string) {
 		select {
 		case <-ticker.C:
 			stats := jq.Stats(ctx)
-			logger.Debug().Msgf("Job queue statistics: enqueued=%d, in-flight=%d", stats.EnqueuedJobs, stats.InFlight)
+			logger.Debug().Msgf("Job queue statistics: enqueued=%d, in-flight=%d, delayed=%d, dead=%d",
+				stats.EnqueuedJobs, stats.InFlight, stats.DelayedJobs, stats.DeadJobs)
 			metrics.SetJobQueueSize(stats.EnqueuedJobs)
+			metrics.SetJobQueueDelayedSize(stats.DelayedJobs)
+			metrics.SetJobQueueDeadSize(stats.DeadJobs)
 			metrics.SetJobQueueInFlight(name, stats.InFlight)
 
 		case <-ctx.Done():
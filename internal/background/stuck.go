@@ -0,0 +1,116 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+)
+
+// maximum amount of stuck reservations processed in one tick
+const stuckBatchSize = 100
+
+var ErrReservationStuck = errors.New("reservation job was lost or did not finish in time")
+
+func stuckReaper(ctx context.Context, sleep time.Duration) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Started stuck reservation reaper %s", sleep.String())
+	defer func() {
+		logger.Debug().Msgf("Stuck reservation reaper routine exited")
+	}()
+
+	ticker := time.NewTicker(sleep)
+
+	reapStuckReservations(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			reapStuckReservations(ctx)
+
+		case <-ctx.Done():
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// stuckThreshold returns the maximum duration of a job including all retries plus the configured
+// margin. Reservations not finished within this duration are considered stuck.
+func stuckThreshold() time.Duration {
+	policy := worker.RetryPolicy{
+		MaxAttempts: config.Worker.MaxAttempts,
+		Backoff:     config.Worker.RetryBackoff,
+	}
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	total := time.Duration(policy.MaxAttempts) * config.Worker.Timeout
+	for attempt := 1; attempt < policy.MaxAttempts; attempt++ {
+		total += policy.Delay(attempt)
+	}
+	return total + config.Reservation.StuckMargin
+}
+
+func reapStuckReservations(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	threshold := stuckThreshold()
+	rDao := dao.GetReservationDao(ctx)
+	reservations, err := rDao.UnscopedListStuck(ctx, threshold, stuckBatchSize)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error while listing stuck reservations")
+		return
+	}
+
+	for _, reservation := range reservations {
+		reaped, err := reapStuckReservation(ctx, reservation, threshold)
+		if err != nil {
+			// the reservation is picked again in the next tick
+			logger.Error().Err(err).Int64("reservation_id", reservation.ID).Msg("Unable to finish stuck reservation")
+			continue
+		} else if !reaped {
+			logger.Debug().Int64("reservation_id", reservation.ID).Msgf("Stuck reservation %d was finished by its job", reservation.ID)
+			continue
+		}
+		logger.Warn().Int64("reservation_id", reservation.ID).Msgf("Finished stuck reservation %d created at %s", reservation.ID, reservation.CreatedAt)
+	}
+}
+
+// reapStuckReservation finishes the reservation with an error and sends failed launch notification
+// for launch reservations. Returns false when the reservation was finished by its job meanwhile.
+func reapStuckReservation(ctx context.Context, reservation *models.Reservation, threshold time.Duration) (bool, error) {
+	ctx, err := accountContext(ctx, reservation.AccountID)
+	if err != nil {
+		return false, err
+	}
+
+	jobErr := fmt.Errorf("%w: not finished within %s", ErrReservationStuck, threshold.String())
+	err = dao.GetReservationDao(ctx).FinishWithError(ctx, reservation.ID, jobErr.Error())
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("cannot finish stuck reservation: %w", err)
+	}
+	metrics.IncStuckReservationCount(reservation.Provider)
+
+	// instance action and noop reservations have no provider details and launch nothing
+	_, _, err = dao.ReservationSourceAndLocation(ctx, reservation)
+	if errors.Is(err, dao.ErrNoRows) || errors.Is(err, dao.ErrUnsupportedProvider) {
+		return true, nil
+	} else if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to get stuck reservation details, not sending notification")
+		return true, nil
+	}
+
+	notifications.GetNotificationClient(ctx).FailedLaunch(ctx, reservation.ID, jobErr)
+	return true, nil
+}
@@ -0,0 +1,80 @@
+package background
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/notifications"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addStuckReservation(t *testing.T, ctx context.Context, createdAt time.Time) *models.AWSReservation {
+	t.Helper()
+
+	reservation := &models.AWSReservation{
+		PubkeyID: 1,
+		SourceID: "1",
+		ImageID:  "ami-random",
+		Detail: &models.AWSDetail{
+			Region: "us-east-1",
+			Amount: 1,
+		},
+	}
+	reservation.AccountID = 1
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.CreatedAt = createdAt
+	err := stubs.AddAWSReservation(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	return reservation
+}
+
+// failedLaunchRecorder records reservations with failed launch notification
+type failedLaunchRecorder struct {
+	failed []int64
+}
+
+func (r *failedLaunchRecorder) SuccessfulLaunch(_ context.Context, _ int64) {}
+
+func (r *failedLaunchRecorder) FailedLaunch(_ context.Context, reservationId int64, _ error) {
+	r.failed = append(r.failed, reservationId)
+}
+
+func TestReapStuckReservations(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	// configuration is not loaded in unit tests
+	config.Reservation.StuckMargin = time.Hour
+
+	recorder := &failedLaunchRecorder{}
+	origClient := notifications.GetNotificationClient
+	notifications.GetNotificationClient = func(_ context.Context) notifications.NotificationClient { return recorder }
+	defer func() { notifications.GetNotificationClient = origClient }()
+
+	stuck := addStuckReservation(t, ctx, time.Now().Add(-stuckThreshold()-time.Minute))
+	running := addStuckReservation(t, ctx, time.Now())
+	action := &models.InstanceActionReservation{}
+	action.Provider = models.ProviderTypeAWS
+	action.CreatedAt = time.Now().Add(-stuckThreshold() - time.Minute)
+	err := dao.GetReservationDao(ctx).CreateInstanceAction(ctx, action)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	reapStuckReservations(ctx)
+
+	assert.True(t, stuck.FinishedAt.Valid, "Stuck reservation was not finished")
+	assert.False(t, stuck.Success.Bool)
+	assert.Contains(t, stuck.Error, ErrReservationStuck.Error())
+
+	assert.False(t, running.FinishedAt.Valid, "Running reservation was finished")
+
+	assert.True(t, action.FinishedAt.Valid, "Stuck action reservation was not finished")
+	assert.Equal(t, []int64{stuck.ID}, recorder.failed, "Only launch reservations notify")
+}
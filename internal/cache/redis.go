@@ -58,6 +58,7 @@ func Initialize() {
 		gob.Register(&models.Account{})
 		gob.Register(&clients.AccountDetailsAWS{})
 		gob.Register(&clients.AccessList{})
+		gob.Register(&clients.InstanceDescriptionList{})
 
 		client = redis.NewClient(&redis.Options{
 			Addr:     config.RedisHostAndPort(),
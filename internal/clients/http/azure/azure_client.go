@@ -44,6 +44,22 @@ func (c *client) newResourceGroupsClient(ctx context.Context) (*armresources.Res
 	return client, nil
 }
 
+func (c *client) newResourcesClient(ctx context.Context) (*armresources.Client, error) {
+	client, err := armresources.NewClient(c.subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create generic resources Azure client: %w", err)
+	}
+	return client, nil
+}
+
+func (c *client) newDeploymentsClient(ctx context.Context) (*armresources.DeploymentsClient, error) {
+	client, err := armresources.NewDeploymentsClient(c.subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create deployments Azure client: %w", err)
+	}
+	return client, nil
+}
+
 func (c *client) newImagesClient(ctx context.Context) (*armcompute.ImagesClient, error) {
 	vmClient, err := armcompute.NewImagesClient(c.subscriptionID, c.credential, nil)
 	if err != nil {
@@ -60,6 +76,14 @@ func (c *client) newVirtualMachinesClient(ctx context.Context) (*armcompute.Virt
 	return vmClient, nil
 }
 
+func (c *client) newDisksClient(ctx context.Context) (*armcompute.DisksClient, error) {
+	diskClient, err := armcompute.NewDisksClient(c.subscriptionID, c.credential, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create disks Azure client: %w", err)
+	}
+	return diskClient, nil
+}
+
 func (c *client) newSubscriptionsClient(ctx context.Context) (*armsubscriptions.Client, error) {
 	client, err := armsubscriptions.NewClient(c.credential, nil)
 	if err != nil {
@@ -18,6 +18,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -27,7 +29,6 @@ const (
 	vnetName              = "redhat-vnet"
 	subnetName            = "redhat-subnet"
 	nsgName               = "redhat-nsg"
-	adminUsername         = "azureuser"
 	vpnIPAddress          = "172.22.0.0/16"
 	resourcePollFrequency = 5 * time.Second
 	vmPollFrequency       = 10 * time.Second
@@ -136,7 +137,7 @@ func (c *client) prepareVMNetworking(ctx context.Context, subnet *armnetwork.Sub
 	logger := logger(ctx)
 
 	publicIPName := vmName + "_ip"
-	publicIP, err := c.createPublicIP(ctx, vmParams.Location, vmParams.ResourceGroupName, publicIPName)
+	publicIP, err := c.createPublicIP(ctx, vmParams.Location, vmParams.Zone, vmParams.ResourceGroupName, publicIPName, vmName, vmParams.Tags)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create public IP address")
 		logger.Error().Err(err).Msg("cannot create public IP address")
@@ -144,7 +145,7 @@ func (c *client) prepareVMNetworking(ctx context.Context, subnet *armnetwork.Sub
 	}
 	logger.Trace().Msgf("Using public IP address id=%s", *publicIP.ID)
 	nicName := vmName + "_nic"
-	networkInterface, err := c.createNetworkInterface(ctx, vmParams.Location, vmParams.ResourceGroupName, subnet, publicIP, securityGroup, nicName)
+	networkInterface, err := c.createNetworkInterface(ctx, vmParams.Location, vmParams.ResourceGroupName, subnet, publicIP, securityGroup, nicName, vmParams.Tags)
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create network interface")
 		logger.Error().Err(err).Msg("cannot create network interface")
@@ -339,7 +340,10 @@ func (c *client) createNetworkSecurityGroup(ctx context.Context, location string
 	return &resp.SecurityGroup, nil
 }
 
-func (c *client) createPublicIP(ctx context.Context, location string, resourceGroupName string, name string) (*armnetwork.PublicIPAddress, error) {
+// createPublicIP creates a static public IP address, the DNS label makes Azure assign a FQDN
+// in the form of LABEL.LOCATION.cloudapp.azure.com to the address. Addresses of zonal VMs are
+// Standard SKU addresses in the same zone.
+func (c *client) createPublicIP(ctx context.Context, location string, zone string, resourceGroupName string, name string, dnsLabel string, tags map[string]*string) (*armnetwork.PublicIPAddress, error) {
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "createPublicIP")
 	defer span.End()
 
@@ -350,10 +354,20 @@ func (c *client) createPublicIP(ctx context.Context, location string, resourceGr
 
 	parameters := armnetwork.PublicIPAddress{
 		Location: to.Ptr(location),
+		Tags:     tags,
 		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
 			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic), // Static or Dynamic
+			DNSSettings: &armnetwork.PublicIPAddressDNSSettings{
+				DomainNameLabel: to.Ptr(dnsLabel),
+			},
 		},
 	}
+	if zone != "" {
+		parameters.Zones = []*string{to.Ptr(zone)}
+		parameters.SKU = &armnetwork.PublicIPAddressSKU{
+			Name: to.Ptr(armnetwork.PublicIPAddressSKUNameStandard),
+		}
+	}
 
 	pollerResponse, err := publicIPAddressClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
@@ -369,7 +383,7 @@ func (c *client) createPublicIP(ctx context.Context, location string, resourceGr
 	return &resp.PublicIPAddress, nil
 }
 
-func (c *client) createNetworkInterface(ctx context.Context, location string, resourceGroupName string, subnet *armnetwork.Subnet, publicIP *armnetwork.PublicIPAddress, nsg *armnetwork.SecurityGroup, name string) (*armnetwork.Interface, error) {
+func (c *client) createNetworkInterface(ctx context.Context, location string, resourceGroupName string, subnet *armnetwork.Subnet, publicIP *armnetwork.PublicIPAddress, nsg *armnetwork.SecurityGroup, name string, tags map[string]*string) (*armnetwork.Interface, error) {
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "createNetworkInterface")
 	defer span.End()
 
@@ -380,6 +394,7 @@ func (c *client) createNetworkInterface(ctx context.Context, location string, re
 
 	parameters := armnetwork.Interface{
 		Location: to.Ptr(location),
+		Tags:     tags,
 		Properties: &armnetwork.InterfacePropertiesFormat{
 			IPConfigurations: []*armnetwork.InterfaceIPConfiguration{
 				{
@@ -395,11 +410,13 @@ func (c *client) createNetworkInterface(ctx context.Context, location string, re
 					},
 				},
 			},
-			NetworkSecurityGroup: &armnetwork.SecurityGroup{
-				ID: nsg.ID,
-			},
 		},
 	}
+	if nsg != nil {
+		parameters.Properties.NetworkSecurityGroup = &armnetwork.SecurityGroup{
+			ID: nsg.ID,
+		}
+	}
 
 	pollerResponse, err := nicClient.BeginCreateOrUpdate(ctx, resourceGroupName, name, parameters, nil)
 	if err != nil {
@@ -420,7 +437,7 @@ func (c *client) prepareVirtualMachineParameters(vmParams clients.AzureInstanceP
 	userDataEncoded := make([]byte, base64.StdEncoding.EncodedLen(len(vmParams.UserData)))
 	base64.StdEncoding.Encode(userDataEncoded, vmParams.UserData)
 
-	return &armcompute.VirtualMachine{
+	vm := &armcompute.VirtualMachine{
 		Location: to.Ptr(vmParams.Location),
 		Identity: &armcompute.VirtualMachineIdentity{
 			Type: to.Ptr(armcompute.ResourceIdentityTypeNone),
@@ -431,29 +448,22 @@ func (c *client) prepareVirtualMachineParameters(vmParams clients.AzureInstanceP
 				ImageReference: &armcompute.ImageReference{
 					ID: ptr.To(vmParams.ImageID),
 				},
-				OSDisk: &armcompute.OSDisk{
-					// Name:         ptr.To(vmName + "_disk1"),
-					CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesFromImage),
-					Caching:      to.Ptr(armcompute.CachingTypesReadWrite),
-					ManagedDisk: &armcompute.ManagedDiskParameters{
-						StorageAccountType: to.Ptr(armcompute.StorageAccountTypesStandardLRS), // OSDisk type Standard/Premium HDD/SSD
-					},
-					// DiskSizeGB: to.Ptr[int32](100), // default 127G
-				},
+				OSDisk:    osDisk(vmName, vmParams.Volumes),
+				DataDisks: dataDisks(vmName, vmParams.Volumes),
 			},
 			HardwareProfile: &armcompute.HardwareProfile{
 				VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(vmParams.InstanceType)), // VM size include vCPUs,RAM,Data Disks,Temp storage.
 			},
 			OSProfile: &armcompute.OSProfile{ //
 				ComputerName:  to.Ptr(vmName),
-				AdminUsername: to.Ptr(adminUsername),
+				AdminUsername: to.Ptr(config.Azure.LoginUsername),
 				// require ssh key for authentication
 				LinuxConfiguration: &armcompute.LinuxConfiguration{
 					DisablePasswordAuthentication: to.Ptr(true),
 					SSH: &armcompute.SSHConfiguration{
 						PublicKeys: []*armcompute.SSHPublicKey{
 							{
-								Path:    to.Ptr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", adminUsername)),
+								Path:    to.Ptr(fmt.Sprintf("/home/%s/.ssh/authorized_keys", config.Azure.LoginUsername)),
 								KeyData: to.Ptr(vmParams.Pubkey.Body),
 							},
 						},
@@ -470,4 +480,117 @@ func (c *client) prepareVirtualMachineParameters(vmParams clients.AzureInstanceP
 			UserData: to.Ptr(string(userDataEncoded)),
 		},
 	}
+
+	if vmParams.Zone != "" {
+		vm.Zones = []*string{to.Ptr(vmParams.Zone)}
+	}
+
+	if vmParams.Spot != nil {
+		// max price -1 caps the price at the pay-as-you-go price
+		maxPrice := float64(-1)
+		if vmParams.Spot.MaxPrice > 0 {
+			maxPrice = vmParams.Spot.MaxPrice
+		}
+		evictionPolicy := armcompute.VirtualMachineEvictionPolicyTypesDelete
+		if vmParams.Spot.StopOnInterruption() {
+			evictionPolicy = armcompute.VirtualMachineEvictionPolicyTypesDeallocate
+		}
+		vm.Properties.Priority = to.Ptr(armcompute.VirtualMachinePriorityTypesSpot)
+		vm.Properties.EvictionPolicy = to.Ptr(evictionPolicy)
+		vm.Properties.BillingProfile = &armcompute.BillingProfile{
+			MaxPrice: to.Ptr(maxPrice),
+		}
+	}
+
+	return vm
+}
+
+// osDisk returns OS disk created from the image, Standard HDD with image size is used by default
+func osDisk(vmName string, volumes *models.Volumes) *armcompute.OSDisk {
+	disk := &armcompute.OSDisk{
+		Name:         to.Ptr(osDiskName(vmName)),
+		CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesFromImage),
+		Caching:      to.Ptr(armcompute.CachingTypesReadWrite),
+		ManagedDisk: &armcompute.ManagedDiskParameters{
+			StorageAccountType: to.Ptr(armcompute.StorageAccountTypesStandardLRS),
+		},
+	}
+	if volumes == nil {
+		return disk
+	}
+
+	if volumes.RootSizeGB > 0 {
+		disk.DiskSizeGB = to.Ptr(volumes.RootSizeGB)
+	}
+	if volumes.RootType != "" {
+		disk.ManagedDisk.StorageAccountType = to.Ptr(armcompute.StorageAccountTypes(volumes.RootType))
+	}
+	return disk
+}
+
+// dataDisks returns empty data disks which are deleted together with the virtual machine
+func dataDisks(vmName string, volumes *models.Volumes) []*armcompute.DataDisk {
+	if volumes == nil || len(volumes.DataDisks) == 0 {
+		return nil
+	}
+
+	disks := make([]*armcompute.DataDisk, len(volumes.DataDisks))
+	for i, disk := range volumes.DataDisks {
+		disks[i] = &armcompute.DataDisk{
+			Name:         to.Ptr(dataDiskName(vmName, i)),
+			Lun:          to.Ptr(int32(i)),
+			CreateOption: to.Ptr(armcompute.DiskCreateOptionTypesEmpty),
+			DeleteOption: to.Ptr(armcompute.DiskDeleteOptionTypesDelete),
+			DiskSizeGB:   to.Ptr(disk.SizeGB),
+			ManagedDisk: &armcompute.ManagedDiskParameters{
+				StorageAccountType: to.Ptr(armcompute.StorageAccountTypes(models.DataDiskType(models.ProviderTypeAzure, disk))),
+			},
+		}
+	}
+	return disks
+}
+
+func osDiskName(vmName string) string {
+	return vmName + "_osdisk"
+}
+
+func dataDiskName(vmName string, lun int) string {
+	return fmt.Sprintf("%s_disk%d", vmName, lun)
+}
+
+// tagDisks sets tags on disks of a virtual machine, disks created together with a virtual machine do
+// not inherit its tags.
+func (c *client) tagDisks(ctx context.Context, vmParams clients.AzureInstanceParams, vmName string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "tagDisks")
+	defer span.End()
+
+	diskClient, err := c.newDisksClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	names := []string{osDiskName(vmName)}
+	if vmParams.Volumes != nil {
+		for i := range vmParams.Volumes.DataDisks {
+			names = append(names, dataDiskName(vmName, i))
+		}
+	}
+
+	for _, name := range names {
+		pollerResponse, err := diskClient.BeginUpdate(ctx, vmParams.ResourceGroupName, name, armcompute.DiskUpdate{Tags: vmParams.Tags}, nil)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot update disk tags")
+			return fmt.Errorf("update of disk %s failed to start: %w", name, err)
+		}
+
+		_, err = pollerResponse.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+			Frequency: resourcePollFrequency,
+		})
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot update disk tags")
+			return fmt.Errorf("failed to poll for update disk %s result: %w", name, err)
+		}
+	}
+
+	return nil
 }
@@ -18,13 +18,18 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 	logger := logger(ctx)
 	logger.Debug().Msgf("Started creating %d Azure VM instances", amount)
 
-	subnet, nsg, err := c.ensureSharedNetworking(ctx, vmParams.Location, vmParams.ResourceGroupName)
+	if vmParams.LaunchTemplateID != "" {
+		return c.createVMsFromTemplate(ctx, vmParams, amount, vmNamePrefix)
+	}
+
+	subnet, nsg, err := c.resolveNetworking(ctx, vmParams)
 	if err != nil {
 		return nil, err
 	}
 
 	vmDescriptions := make([]clients.InstanceDescription, amount)
 	resumeTokens := make([]string, amount)
+	vmNames := make([]string, amount)
 	var i int64
 	for i = 0; i < amount; i++ {
 		uid, err := uuid.NewUUID()
@@ -32,6 +37,7 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 			return vmDescriptions, fmt.Errorf("could not generate a new UUID: %w", err)
 		}
 		vmName := fmt.Sprintf("%s-%s", vmNamePrefix, uid.String())
+		vmNames[i] = vmName
 
 		networkInterface, publicIP, err := c.prepareVMNetworking(ctx, subnet, nsg, vmParams, vmName)
 		if err != nil {
@@ -56,9 +62,67 @@ func (c *client) CreateVMs(ctx context.Context, vmParams clients.AzureInstancePa
 		}
 		vmDescriptions[j].ID = string(instanceId)
 		logger.Debug().Msgf("Created new instance (%s) via Azure CreateVM", string(instanceId))
+
+		// instances are running, missing disk tags are not worth failing the reservation
+		if err = c.tagDisks(ctx, vmParams, vmNames[j]); err != nil {
+			logger.Warn().Err(err).Msgf("Unable to tag disks of instance %s", string(instanceId))
+		}
 	}
 
 	logger.Debug().Msgf("Created %d new instance", amount)
 
 	return vmDescriptions, nil
 }
+
+// createVMsFromTemplate deploys a template spec once for every virtual machine, networking and disks
+// are defined by the template.
+func (c *client) createVMsFromTemplate(ctx context.Context, vmParams clients.AzureInstanceParams, amount int64, vmNamePrefix string) ([]clients.InstanceDescription, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "createVMsFromTemplate")
+	defer span.End()
+
+	logger := logger(ctx)
+	versionID, declared, err := c.templateSpecVersion(ctx, vmParams.LaunchTemplateID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot get template spec")
+		return nil, err
+	}
+
+	vmDescriptions := make([]clients.InstanceDescription, amount)
+	resumeTokens := make([]string, amount)
+	var i int64
+	for i = 0; i < amount; i++ {
+		uid, err := uuid.NewUUID()
+		if err != nil {
+			return vmDescriptions, fmt.Errorf("could not generate a new UUID: %w", err)
+		}
+		vmName := fmt.Sprintf("%s-%s", vmNamePrefix, uid.String())
+
+		resumeTokens[i], err = c.BeginDeployTemplate(ctx, versionID, declared, vmParams, vmName, "redhat-"+uid.String())
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to start deployment of Azure template")
+			return vmDescriptions, fmt.Errorf("cannot start a deployment of Azure template: %w", err)
+		}
+	}
+
+	for j, token := range resumeTokens {
+		instanceId, err := c.WaitForDeployment(ctx, token)
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to deploy Azure template")
+			return vmDescriptions, fmt.Errorf("cannot deploy Azure template: %w", err)
+		}
+		vmDescriptions[j].ID = string(instanceId)
+		logger.Debug().Msgf("Created new instance (%s) via Azure template deployment", string(instanceId))
+
+		// addresses are assigned by the template, describe failures are not worth failing the reservation
+		description, err := c.DescribeVM(ctx, string(instanceId))
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Unable to describe instance %s", string(instanceId))
+			continue
+		}
+		vmDescriptions[j].IPv4 = description.IPv4
+		vmDescriptions[j].PrivateIPv4 = description.PrivateIPv4
+		vmDescriptions[j].DNS = description.DNS
+	}
+
+	return vmDescriptions, nil
+}
@@ -0,0 +1,163 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const powerStatePrefix = "PowerState/"
+
+func (c *client) DescribeVM(ctx context.Context, vmID string) (*clients.InstanceDescription, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DescribeVM")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Describing Azure VM %s", vmID)
+
+	resourceGroupName, vmName, err := parseVMID(vmID)
+	if err != nil {
+		return nil, err
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := vmClient.Get(ctx, resourceGroupName, vmName, &armcompute.VirtualMachinesClientGetOptions{
+		Expand: ptr.To(armcompute.InstanceViewTypesInstanceView),
+	})
+	var azErr *azcore.ResponseError
+	if errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound {
+		// deleted virtual machines are not found
+		return &clients.InstanceDescription{ID: vmID, State: models.InstanceStateTerminated}, nil
+	} else if err != nil {
+		span.SetStatus(codes.Error, "cannot get virtual machine")
+		return nil, fmt.Errorf("cannot get virtual machine: %w", err)
+	}
+
+	description := &clients.InstanceDescription{ID: vmID}
+	if vm.Properties == nil {
+		return description, nil
+	}
+
+	if vm.Properties.InstanceView != nil {
+		for _, status := range vm.Properties.InstanceView.Statuses {
+			code := ptr.FromOrEmpty(status.Code)
+			if strings.HasPrefix(code, powerStatePrefix) {
+				description.State = vmState(strings.TrimPrefix(code, powerStatePrefix))
+			}
+		}
+	}
+
+	if vm.Properties.NetworkProfile != nil {
+		for _, nicRef := range vm.Properties.NetworkProfile.NetworkInterfaces {
+			err = c.describeNetworkInterface(ctx, ptr.FromOrEmpty(nicRef.ID), description)
+			if err != nil {
+				span.SetStatus(codes.Error, "cannot describe network interface")
+				return nil, err
+			}
+			// only the first (primary) interface is described
+			break
+		}
+	}
+
+	return description, nil
+}
+
+// describeNetworkInterface fills private and public addresses of a network interface into the description
+func (c *client) describeNetworkInterface(ctx context.Context, nicID string, description *clients.InstanceDescription) error {
+	id, err := arm.ParseResourceID(nicID)
+	if err != nil {
+		return fmt.Errorf("cannot parse network interface ID: %w", err)
+	}
+
+	nicClient, err := c.newInterfacesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	nic, err := nicClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get network interface: %w", err)
+	}
+	if nic.Properties == nil {
+		return nil
+	}
+
+	for _, ipConfig := range nic.Properties.IPConfigurations {
+		if ipConfig.Properties == nil {
+			continue
+		}
+		if ptr.FromOrEmpty(ipConfig.Properties.PrivateIPAddressVersion) == armnetwork.IPVersionIPv6 {
+			description.PrivateIPv6 = ptr.FromOrEmpty(ipConfig.Properties.PrivateIPAddress)
+		} else if description.PrivateIPv4 == "" {
+			description.PrivateIPv4 = ptr.FromOrEmpty(ipConfig.Properties.PrivateIPAddress)
+		}
+
+		if ipConfig.Properties.PublicIPAddress != nil && description.IPv4 == "" {
+			err = c.describePublicIP(ctx, ptr.FromOrEmpty(ipConfig.Properties.PublicIPAddress.ID), description)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// describePublicIP fills public address and DNS name into the description
+func (c *client) describePublicIP(ctx context.Context, publicIPID string, description *clients.InstanceDescription) error {
+	id, err := arm.ParseResourceID(publicIPID)
+	if err != nil {
+		return fmt.Errorf("cannot parse public IP address ID: %w", err)
+	}
+
+	publicIPClient, err := c.newPublicIPAddressesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	publicIP, err := publicIPClient.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return fmt.Errorf("cannot get public IP address: %w", err)
+	}
+	if publicIP.Properties == nil {
+		return nil
+	}
+
+	description.IPv4 = ptr.FromOrEmpty(publicIP.Properties.IPAddress)
+	if publicIP.Properties.DNSSettings != nil {
+		description.DNS = ptr.FromOrEmpty(publicIP.Properties.DNSSettings.Fqdn)
+	}
+	return nil
+}
+
+// vmState maps Azure power state code (without the PowerState/ prefix) to provider-neutral state
+func vmState(powerState string) models.InstanceState {
+	switch powerState {
+	case "starting":
+		return models.InstanceStatePending
+	case "running":
+		return models.InstanceStateRunning
+	case "stopping", "deallocating":
+		return models.InstanceStateStopping
+	case "stopped", "deallocated":
+		return models.InstanceStateStopped
+	default:
+		return models.InstanceStateUnknown
+	}
+}
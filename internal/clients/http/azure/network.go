@@ -0,0 +1,249 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var ErrInvalidVNetID = errors.New("invalid virtual network resource ID")
+
+// reservedSubnets are names of subnets dedicated to Azure services, virtual machines cannot use them
+var reservedSubnets = []string{
+	"GatewaySubnet",
+	"AzureBastionSubnet",
+	"AzureFirewallSubnet",
+	"AzureFirewallManagementSubnet",
+	"RouteServerSubnet",
+}
+
+// isReservedSubnet returns true for subnets which cannot be used by virtual machines
+func isReservedSubnet(name string) bool {
+	for _, reserved := range reservedSubnets {
+		if strings.EqualFold(name, reserved) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseVNetID returns resource group and virtual network name from a full Azure resource ID
+// in the form of /subscriptions/<sub-id>/resourceGroups/<group>/providers/Microsoft.Network/virtualNetworks/<name>
+func parseVNetID(vnetID string) (string, string, error) {
+	id, err := arm.ParseResourceID(vnetID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidVNetID, err.Error())
+	}
+	if id.ResourceType.String() != "Microsoft.Network/virtualNetworks" {
+		return "", "", fmt.Errorf("%w: unexpected resource type %s", ErrInvalidVNetID, id.ResourceType.String())
+	}
+	return id.ResourceGroupName, id.Name, nil
+}
+
+// parseSubnetVNetID returns virtual network resource ID from a full subnet resource ID
+// in the form of /subscriptions/<sub-id>/resourceGroups/<group>/providers/Microsoft.Network/virtualNetworks/<name>/subnets/<subnet>
+func parseSubnetVNetID(subnetID string) (string, error) {
+	id, err := arm.ParseResourceID(subnetID)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidVNetID, err.Error())
+	}
+	if id.ResourceType.String() != "Microsoft.Network/virtualNetworks/subnets" || id.Parent == nil {
+		return "", fmt.Errorf("%w: unexpected resource type %s", ErrInvalidVNetID, id.ResourceType.String())
+	}
+	return id.Parent.String(), nil
+}
+
+// getVirtualNetwork fetches an existing virtual network by its full resource ID
+func (c *client) getVirtualNetwork(ctx context.Context, vnetID string) (*armnetwork.VirtualNetwork, error) {
+	vnetClient, err := c.newVirtualNetworksClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceGroupName, vnetName, err := parseVNetID(vnetID)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := vnetClient.Get(ctx, resourceGroupName, vnetName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch virtual network: %w", err)
+	}
+	return &resp.VirtualNetwork, nil
+}
+
+func (c *client) ListVirtualNetworks(ctx context.Context) ([]*clients.Network, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListVirtualNetworks")
+	defer span.End()
+
+	vnetClient, err := c.newVirtualNetworksClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	networks := make([]*clients.Network, 0)
+	pager := vnetClient.NewListAllPager(nil)
+	for pager.More() {
+		page, pagerErr := pager.NextPage(ctx)
+		if pagerErr != nil {
+			span.SetStatus(codes.Error, pagerErr.Error())
+			return nil, fmt.Errorf("failed to fetch virtual networks: %w", pagerErr)
+		}
+		for _, vnet := range page.Value {
+			network := &clients.Network{
+				ID:   ptr.FromOrEmpty(vnet.ID),
+				Name: ptr.FromOrEmpty(vnet.Name),
+			}
+			if vnet.Properties != nil && vnet.Properties.AddressSpace != nil && len(vnet.Properties.AddressSpace.AddressPrefixes) > 0 {
+				network.CIDR = ptr.FromOrEmpty(vnet.Properties.AddressSpace.AddressPrefixes[0])
+			}
+			networks = append(networks, network)
+		}
+	}
+
+	return networks, nil
+}
+
+func (c *client) ListSubnets(ctx context.Context, vnetID string) ([]*clients.Subnet, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListSubnets")
+	defer span.End()
+
+	var vnets []*armnetwork.VirtualNetwork
+	if vnetID != "" {
+		vnet, err := c.getVirtualNetwork(ctx, vnetID)
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+		vnets = append(vnets, vnet)
+	} else {
+		vnetClient, err := c.newVirtualNetworksClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		pager := vnetClient.NewListAllPager(nil)
+		for pager.More() {
+			page, pagerErr := pager.NextPage(ctx)
+			if pagerErr != nil {
+				span.SetStatus(codes.Error, pagerErr.Error())
+				return nil, fmt.Errorf("failed to fetch virtual networks: %w", pagerErr)
+			}
+			vnets = append(vnets, page.Value...)
+		}
+	}
+
+	subnets := make([]*clients.Subnet, 0)
+	for _, vnet := range vnets {
+		if vnet.Properties == nil {
+			continue
+		}
+		for _, subnet := range vnet.Properties.Subnets {
+			result := &clients.Subnet{
+				ID:        ptr.FromOrEmpty(subnet.ID),
+				Name:      ptr.FromOrEmpty(subnet.Name),
+				NetworkID: ptr.FromOrEmpty(vnet.ID),
+			}
+			if subnet.Properties != nil {
+				result.CIDR = ptr.FromOrEmpty(subnet.Properties.AddressPrefix)
+			}
+			subnets = append(subnets, result)
+		}
+	}
+
+	return subnets, nil
+}
+
+// ListSecurityGroups returns all network security groups, these are not bound to virtual networks in Azure.
+func (c *client) ListSecurityGroups(ctx context.Context) ([]*clients.SecurityGroup, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListSecurityGroups")
+	defer span.End()
+
+	nsgClient, err := c.newSecurityGroupsClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*clients.SecurityGroup, 0)
+	pager := nsgClient.NewListAllPager(nil)
+	for pager.More() {
+		page, pagerErr := pager.NextPage(ctx)
+		if pagerErr != nil {
+			span.SetStatus(codes.Error, pagerErr.Error())
+			return nil, fmt.Errorf("failed to fetch network security groups: %w", pagerErr)
+		}
+		for _, nsg := range page.Value {
+			groups = append(groups, &clients.SecurityGroup{
+				ID:   ptr.FromOrEmpty(nsg.ID),
+				Name: ptr.FromOrEmpty(nsg.Name),
+			})
+		}
+	}
+
+	return groups, nil
+}
+
+// resolveNetworking returns subnet and network security group for new network interfaces. Existing resources
+// are used when set in parameters, the shared virtual network in the resource group is created otherwise.
+// Existing virtual networks must be in the location of the virtual machines.
+// The first subnet which is not reserved for Azure services (e.g. GatewaySubnet) is used when only a network
+// is set. Security group is nil when an existing subnet is used without a security group, rules of the subnet apply.
+func (c *client) resolveNetworking(ctx context.Context, vmParams clients.AzureInstanceParams) (*armnetwork.Subnet, *armnetwork.SecurityGroup, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "resolveNetworking")
+	defer span.End()
+
+	var nsg *armnetwork.SecurityGroup
+	if vmParams.SecurityGroupID != "" {
+		nsg = &armnetwork.SecurityGroup{ID: ptr.To(vmParams.SecurityGroupID)}
+	}
+
+	vnetID := vmParams.NetworkID
+	if vmParams.SubnetID != "" {
+		var err error
+		vnetID, err = parseSubnetVNetID(vmParams.SubnetID)
+		if err != nil {
+			span.SetStatus(codes.Error, "invalid subnet ID")
+			return nil, nil, err
+		}
+	}
+
+	if vnetID != "" {
+		vnet, err := c.getVirtualNetwork(ctx, vnetID)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot get virtual network")
+			return nil, nil, err
+		}
+		// network interfaces are created in the reservation location, Azure rejects other vnet locations
+		if !strings.EqualFold(ptr.FromOrEmpty(vnet.Location), vmParams.Location) {
+			return nil, nil, fmt.Errorf("%w: %s is in %s", http.ErrNetworkLocation, vnetID, ptr.FromOrEmpty(vnet.Location))
+		}
+
+		if vmParams.SubnetID != "" {
+			return &armnetwork.Subnet{ID: ptr.To(vmParams.SubnetID)}, nsg, nil
+		}
+		if vnet.Properties != nil {
+			for _, subnet := range vnet.Properties.Subnets {
+				if !isReservedSubnet(ptr.FromOrEmpty(subnet.Name)) {
+					return &armnetwork.Subnet{ID: subnet.ID}, nsg, nil
+				}
+			}
+		}
+		return nil, nil, http.ErrNetworkNoSubnet
+	}
+
+	subnet, sharedNSG, err := c.ensureSharedNetworking(ctx, vmParams.Location, vmParams.ResourceGroupName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if nsg == nil {
+		nsg = sharedNSG
+	}
+	return subnet, nsg, nil
+}
@@ -0,0 +1,23 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsReservedSubnet(t *testing.T) {
+	assert.True(t, isReservedSubnet("GatewaySubnet"))
+	assert.True(t, isReservedSubnet("azurebastionsubnet"))
+	assert.False(t, isReservedSubnet("workloads"))
+}
+
+func TestParseSubnetVNetID(t *testing.T) {
+	vnetID, err := parseSubnetVNetID("/subscriptions/subUUID/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet/subnets/workloads")
+	require.NoError(t, err)
+	assert.Equal(t, "/subscriptions/subUUID/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet", vnetID)
+
+	_, err = parseSubnetVNetID("/subscriptions/subUUID/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet")
+	assert.ErrorIs(t, err, ErrInvalidVNetID)
+}
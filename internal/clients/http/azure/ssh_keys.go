@@ -0,0 +1,113 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	httpClients "github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var ErrInvalidSSHKeyID = errors.New("invalid SSH public key resource ID")
+
+// parseSSHKeyID returns resource group and SSH public key name from a full Azure resource ID
+// in the form of /subscriptions/<sub-id>/resourceGroups/<group>/providers/Microsoft.Compute/sshPublicKeys/<name>
+func parseSSHKeyID(keyID string) (string, string, error) {
+	id, err := arm.ParseResourceID(keyID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidSSHKeyID, err.Error())
+	}
+	if id.ResourceType.String() != "Microsoft.Compute/sshPublicKeys" {
+		return "", "", fmt.Errorf("%w: unexpected resource type %s", ErrInvalidSSHKeyID, id.ResourceType.String())
+	}
+	return id.ResourceGroupName, id.Name, nil
+}
+
+func (c *client) ImportSSHKey(ctx context.Context, resourceGroupName, location string, pk *models.Pubkey, name string) (string, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ImportSSHKey")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Importing Azure SSH public key '%s' named '%s'", pk.Name, name)
+
+	keysClient, err := c.newSshKeysClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := keysClient.Create(ctx, resourceGroupName, name, armcompute.SSHPublicKeyResource{
+		Location: ptr.To(location),
+		Properties: &armcompute.SSHPublicKeyResourceProperties{
+			PublicKey: ptr.To(pk.Body),
+		},
+		Tags: map[string]*string{
+			"rh-kid": ptr.To(name),
+		},
+	}, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot import SSH public key")
+		return "", fmt.Errorf("cannot import SSH public key %s: %w", pk.Name, err)
+	}
+
+	return ptr.FromOrEmpty(resp.ID), nil
+}
+
+func (c *client) GetSSHKey(ctx context.Context, keyID string) (string, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "GetSSHKey")
+	defer span.End()
+
+	resourceGroupName, name, err := parseSSHKeyID(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	keysClient, err := c.newSshKeysClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := keysClient.Get(ctx, resourceGroupName, name, nil)
+	var azErr *azcore.ResponseError
+	if errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("SSH public key %s: %w", keyID, httpClients.ErrPubkeyNotFound)
+	} else if err != nil {
+		span.SetStatus(codes.Error, "cannot get SSH public key")
+		return "", fmt.Errorf("cannot get SSH public key: %w", err)
+	}
+
+	return ptr.FromOrEmpty(resp.ID), nil
+}
+
+func (c *client) DeleteSSHKey(ctx context.Context, keyID string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DeleteSSHKey")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Deleting Azure SSH public key %s", keyID)
+
+	resourceGroupName, name, err := parseSSHKeyID(keyID)
+	if err != nil {
+		return err
+	}
+
+	keysClient, err := c.newSshKeysClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = keysClient.Delete(ctx, resourceGroupName, name, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot delete SSH public key")
+		return fmt.Errorf("cannot delete SSH public key %s: %w", keyID, err)
+	}
+
+	return nil
+}
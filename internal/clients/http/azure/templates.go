@@ -0,0 +1,235 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	templateSpecsAPIVersion = "2022-02-01"
+	templateSpecType        = "Microsoft.Resources/templateSpecs"
+	virtualMachineType      = "Microsoft.Compute/virtualMachines"
+	vmNameParameter         = "vmName"
+)
+
+// ListLaunchTemplates lists Template Specs in the subscription. The Azure pager does not expose
+// continuation tokens, all template specs are returned in a single page.
+func (c *client) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchTemplate, string, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListLaunchTemplates")
+	defer span.End()
+
+	resourcesClient, err := c.newResourcesClient(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	templates := make([]*clients.LaunchTemplate, 0)
+	pager := resourcesClient.NewListPager(&armresources.ClientListOptions{
+		Filter: to.Ptr(fmt.Sprintf("resourceType eq '%s'", templateSpecType)),
+	})
+	for pager.More() {
+		page, pagerErr := pager.NextPage(ctx)
+		if pagerErr != nil {
+			span.SetStatus(codes.Error, pagerErr.Error())
+			return nil, "", fmt.Errorf("failed to fetch template specs: %w", pagerErr)
+		}
+		for _, resource := range page.Value {
+			templates = append(templates, &clients.LaunchTemplate{
+				ID:   *resource.ID,
+				Name: *resource.Name,
+			})
+		}
+	}
+
+	return templates, "", nil
+}
+
+// templateSpecVersion returns resource ID and declared parameters of a template spec version. Template spec IDs
+// are resolved to the most recently modified version, version IDs are used as they are.
+func (c *client) templateSpecVersion(ctx context.Context, templateID string) (string, map[string]any, error) {
+	resourcesClient, err := c.newResourcesClient(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	versionID := templateID
+	if !strings.Contains(strings.ToLower(templateID), "/versions/") {
+		spec, err := resourcesClient.GetByID(ctx, templateID, templateSpecsAPIVersion, nil)
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot get template spec: %w", err)
+		}
+		version := latestVersion(spec.Properties)
+		if version == "" {
+			return "", nil, http.ErrTemplateSpecNoVersion
+		}
+		versionID = fmt.Sprintf("%s/versions/%s", templateID, version)
+	}
+
+	version, err := resourcesClient.GetByID(ctx, versionID, templateSpecsAPIVersion, nil)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot get template spec version: %w", err)
+	}
+
+	var parameters map[string]any
+	if properties, ok := version.Properties.(map[string]any); ok {
+		if mainTemplate, ok := properties["mainTemplate"].(map[string]any); ok {
+			parameters, _ = mainTemplate["parameters"].(map[string]any)
+		}
+	}
+	if _, ok := parameters[vmNameParameter]; !ok {
+		return "", nil, http.ErrTemplateNoVMParameter
+	}
+
+	return versionID, parameters, nil
+}
+
+// latestVersion returns name of the most recently modified version from template spec properties.
+func latestVersion(properties any) string {
+	props, ok := properties.(map[string]any)
+	if !ok {
+		return ""
+	}
+	versions, ok := props["versions"].(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	var latest string
+	var latestTime time.Time
+	for name, info := range versions {
+		var modified time.Time
+		if infoMap, ok := info.(map[string]any); ok {
+			if value, ok := infoMap["timeModified"].(string); ok {
+				modified, _ = time.Parse(time.RFC3339, value)
+			}
+		}
+		if latest == "" || modified.After(latestTime) {
+			latest = name
+			latestTime = modified
+		}
+	}
+	return latest
+}
+
+// templateParameters returns values of well-known parameters which are declared by the template,
+// ARM deployments fail for parameters which are not declared.
+func templateParameters(declared map[string]any, vmParams clients.AzureInstanceParams, vmName string) map[string]any {
+	tags := make(map[string]string, len(vmParams.Tags))
+	for key, value := range vmParams.Tags {
+		if value != nil {
+			tags[key] = *value
+		}
+	}
+
+	known := map[string]any{
+		vmNameParameter:      vmName,
+		"location":           vmParams.Location,
+		"vmSize":             string(vmParams.InstanceType),
+		"imageId":            vmParams.ImageID,
+		"adminUsername":      config.Azure.LoginUsername,
+		"authenticationType": "sshPublicKey",
+		"adminPasswordOrKey": vmParams.Pubkey.Body,
+		"sshPublicKey":       vmParams.Pubkey.Body,
+		"customData":         string(vmParams.UserData),
+		"userData":           string(vmParams.UserData),
+		"tags":               tags,
+	}
+
+	parameters := make(map[string]any)
+	for name, value := range known {
+		if _, ok := declared[name]; ok {
+			parameters[name] = map[string]any{"value": value}
+		}
+	}
+	return parameters
+}
+
+// BeginDeployTemplate starts deployment of a template spec version creating a single virtual machine.
+func (c *client) BeginDeployTemplate(ctx context.Context, versionID string, declared map[string]any, vmParams clients.AzureInstanceParams, vmName, deploymentName string) (string, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "BeginDeployTemplate")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deploying template spec %s without waiting", versionID)
+
+	deploymentsClient, err := c.newDeploymentsClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	deployment := armresources.Deployment{
+		Properties: &armresources.DeploymentProperties{
+			Mode: to.Ptr(armresources.DeploymentModeIncremental),
+			TemplateLink: &armresources.TemplateLink{
+				ID: to.Ptr(versionID),
+			},
+			Parameters: templateParameters(declared, vmParams, vmName),
+		},
+		Tags: vmParams.Tags,
+	}
+
+	poller, err := deploymentsClient.BeginCreateOrUpdate(ctx, vmParams.ResourceGroupName, deploymentName, deployment, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot deploy template spec")
+		return "", fmt.Errorf("deployment of template spec failed to start: %w", err)
+	}
+
+	resumeToken, err := poller.ResumeToken()
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot generate resume token")
+		return "", fmt.Errorf("cannot generate resume token for template deployment: %w", err)
+	}
+	return resumeToken, nil
+}
+
+// WaitForDeployment waits for a template deployment and returns ID of the created virtual machine.
+func (c *client) WaitForDeployment(ctx context.Context, resumeToken string) (clients.AzureInstanceID, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "WaitForDeployment")
+	defer span.End()
+
+	deploymentsClient, err := c.newDeploymentsClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	poller, err := deploymentsClient.BeginCreateOrUpdate(ctx, "", "", armresources.Deployment{}, &armresources.DeploymentsClientBeginCreateOrUpdateOptions{
+		ResumeToken: resumeToken,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "polling of template deployment failed to start")
+		return "", fmt.Errorf("polling of template deployment failed to start: %w", err)
+	}
+	resp, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: vmPollFrequency,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to poll for template deployment status")
+		return "", fmt.Errorf("failed to poll for template deployment status: %w", err)
+	}
+
+	if resp.Properties != nil {
+		for _, resource := range resp.Properties.OutputResources {
+			if resource.ID != nil && isVirtualMachineID(*resource.ID) {
+				return clients.AzureInstanceID(*resource.ID), nil
+			}
+		}
+	}
+	return "", http.ErrTemplateDeploymentNoVM
+}
+
+// isVirtualMachineID returns true for virtual machine IDs, but not for their child resources (e.g. extensions).
+func isVirtualMachineID(id string) bool {
+	_, after, found := strings.Cut(strings.ToLower(id), "/providers/"+strings.ToLower(virtualMachineType)+"/")
+	return found && !strings.Contains(after, "/")
+}
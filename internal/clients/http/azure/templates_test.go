@@ -0,0 +1,49 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	properties := map[string]any{
+		"versions": map[string]any{
+			"1.0": map[string]any{"timeModified": "2023-05-01T10:00:00Z"},
+			"2.0": map[string]any{"timeModified": "2023-06-01T10:00:00Z"},
+			"1.1": map[string]any{"timeModified": "2023-05-15T10:00:00Z"},
+		},
+	}
+	assert.Equal(t, "2.0", latestVersion(properties))
+	assert.Equal(t, "", latestVersion(map[string]any{}))
+	assert.Equal(t, "", latestVersion(nil))
+}
+
+func TestTemplateParameters(t *testing.T) {
+	declared := map[string]any{
+		"vmName":             map[string]any{"type": "string"},
+		"adminPasswordOrKey": map[string]any{"type": "securestring"},
+		"vmSize":             map[string]any{"type": "string"},
+		"networkName":        map[string]any{"type": "string"},
+	}
+	vmParams := clients.AzureInstanceParams{
+		Location:     "eastus",
+		InstanceType: "Standard_B1s",
+		Pubkey:       &models.Pubkey{Body: "ssh-ed25519 AAAA"},
+	}
+
+	parameters := templateParameters(declared, vmParams, "redhat-vm-1")
+	require.Len(t, parameters, 3)
+	assert.Equal(t, map[string]any{"value": "redhat-vm-1"}, parameters["vmName"])
+	assert.Equal(t, map[string]any{"value": "ssh-ed25519 AAAA"}, parameters["adminPasswordOrKey"])
+	assert.Equal(t, map[string]any{"value": "Standard_B1s"}, parameters["vmSize"])
+}
+
+func TestIsVirtualMachineID(t *testing.T) {
+	assert.True(t, isVirtualMachineID("/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1"))
+	assert.False(t, isVirtualMachineID("/subscriptions/s/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1/extensions/init"))
+	assert.False(t, isVirtualMachineID("/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/networkInterfaces/nic-1"))
+}
@@ -0,0 +1,165 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var ErrInvalidVMID = errors.New("invalid virtual machine resource ID")
+
+// parseVMID returns resource group and virtual machine name from a full Azure resource ID
+// in the form of /subscriptions/<sub-id>/resourceGroups/<group>/providers/Microsoft.Compute/virtualMachines/<name>
+func parseVMID(vmID string) (string, string, error) {
+	id, err := arm.ParseResourceID(vmID)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidVMID, err.Error())
+	}
+	if id.ResourceType.String() != "Microsoft.Compute/virtualMachines" {
+		return "", "", fmt.Errorf("%w: unexpected resource type %s", ErrInvalidVMID, id.ResourceType.String())
+	}
+	return id.ResourceGroupName, id.Name, nil
+}
+
+func (c *client) StartVM(ctx context.Context, vmID string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "StartVM")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Starting Azure VM %s", vmID)
+
+	resourceGroupName, vmName, err := parseVMID(vmID)
+	if err != nil {
+		return err
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	poller, err := vmClient.BeginStart(ctx, resourceGroupName, vmName, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot start virtual machine")
+		return fmt.Errorf("start of virtual machine failed to begin: %w", err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: vmPollFrequency,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to poll for start virtual machine status")
+		return fmt.Errorf("failed to poll for start virtual machine status: %w", err)
+	}
+
+	return nil
+}
+
+func (c *client) DeallocateVM(ctx context.Context, vmID string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DeallocateVM")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deallocating Azure VM %s", vmID)
+
+	resourceGroupName, vmName, err := parseVMID(vmID)
+	if err != nil {
+		return err
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	poller, err := vmClient.BeginDeallocate(ctx, resourceGroupName, vmName, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot deallocate virtual machine")
+		return fmt.Errorf("deallocation of virtual machine failed to begin: %w", err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: vmPollFrequency,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to poll for deallocate virtual machine status")
+		return fmt.Errorf("failed to poll for deallocate virtual machine status: %w", err)
+	}
+
+	return nil
+}
+
+func (c *client) RestartVM(ctx context.Context, vmID string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "RestartVM")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Restarting Azure VM %s", vmID)
+
+	resourceGroupName, vmName, err := parseVMID(vmID)
+	if err != nil {
+		return err
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	poller, err := vmClient.BeginRestart(ctx, resourceGroupName, vmName, nil)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot restart virtual machine")
+		return fmt.Errorf("restart of virtual machine failed to begin: %w", err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: vmPollFrequency,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to poll for restart virtual machine status")
+		return fmt.Errorf("failed to poll for restart virtual machine status: %w", err)
+	}
+
+	return nil
+}
+
+func (c *client) DeleteVM(ctx context.Context, vmID string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DeleteVM")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Debug().Msgf("Deleting Azure VM %s", vmID)
+
+	resourceGroupName, vmName, err := parseVMID(vmID)
+	if err != nil {
+		return err
+	}
+
+	vmClient, err := c.newVirtualMachinesClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	poller, err := vmClient.BeginDelete(ctx, resourceGroupName, vmName, nil)
+	var azErr *azcore.ResponseError
+	if errors.As(err, &azErr) && azErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: virtual machine %s", clients.ErrNotFound, vmID)
+	} else if err != nil {
+		span.SetStatus(codes.Error, "cannot delete virtual machine")
+		return fmt.Errorf("delete of virtual machine failed to begin: %w", err)
+	}
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: vmPollFrequency,
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, "failed to poll for delete virtual machine status")
+		return fmt.Errorf("failed to poll for delete virtual machine status: %w", err)
+	}
+
+	return nil
+}
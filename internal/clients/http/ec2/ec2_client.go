@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
@@ -216,6 +217,33 @@ func (c *ec2Client) GetPubkeyName(ctx context.Context, fingerprint string) (stri
 	return *output.KeyPairs[0].KeyName, nil
 }
 
+func (c *ec2Client) GetPubkeyHandle(ctx context.Context, tag string) (string, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "GetPubkeyHandle")
+	defer span.End()
+
+	if !c.assumed {
+		return "", http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Fetching AWS key with tag '%s' to get its ID", tag)
+	input := &ec2.DescribeKeyPairsInput{}
+	input.Filters = []types.Filter{{Name: ptr.To("tag:rh-kid"), Values: []string{tag}}}
+	output, err := c.ec2.DescribeKeyPairs(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return "", fmt.Errorf("cannot fetch SSH key with tag %s: %w", tag, err)
+	}
+
+	if len(output.KeyPairs) == 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("no KeyPair with tag (%s) found", tag))
+		return "", fmt.Errorf("SSH key not found by its tag: %w", http.ErrPubkeyNotFound)
+	}
+	return *output.KeyPairs[0].KeyPairId, nil
+}
+
 func (c *ec2Client) DeleteSSHKey(ctx context.Context, handle string) error {
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "DeleteSSHKey")
 	defer span.End()
@@ -329,6 +357,8 @@ func (c *ec2Client) DescribeInstanceDetails(ctx context.Context, InstanceIds []s
 	if err != nil {
 		if isAWSUnauthorizedError(err) {
 			err = clients.ErrUnauthorized
+		} else if isAWSInstanceNotFoundError(err) {
+			err = fmt.Errorf("%w: %s", clients.ErrNotFound, err.Error())
 		}
 		span.SetStatus(codes.Error, err.Error())
 		return nil, fmt.Errorf("cannot fetch instances description: %w", err)
@@ -402,28 +432,78 @@ func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanc
 		UserData:       &encodedUserData,
 	}
 
-	input.TagSpecifications = []types.TagSpecification{
+	mappings, err := c.blockDeviceMappings(ctx, params.AMI, params.Volumes)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, nil, err
+	}
+	input.BlockDeviceMappings = mappings
+
+	// Public IP association can only be set on a network interface, subnet and security groups
+	// must be then set on the interface too.
+	if params.AssociatePublicIP != nil {
+		nic := types.InstanceNetworkInterfaceSpecification{
+			DeviceIndex:              ptr.ToInt32(0),
+			AssociatePublicIpAddress: params.AssociatePublicIP,
+			Groups:                   params.SecurityGroupIDs,
+		}
+		if params.SubnetID != "" {
+			nic.SubnetId = ptr.To(params.SubnetID)
+		}
+		input.NetworkInterfaces = []types.InstanceNetworkInterfaceSpecification{nic}
+	} else {
+		if params.SubnetID != "" {
+			input.SubnetId = ptr.To(params.SubnetID)
+		}
+		input.SecurityGroupIds = params.SecurityGroupIDs
+	}
+
+	if params.Spot != nil {
+		input.InstanceMarketOptions = spotMarketOptions(params.Spot)
+	}
+
+	if params.Zone != "" {
+		input.Placement = &types.Placement{AvailabilityZone: ptr.To(params.Zone)}
+	}
+
+	tags := []types.Tag{
 		{
-			ResourceType: types.ResourceTypeInstance,
-			Tags: []types.Tag{
-				{
-					Key:   ptr.To("rh-rid"),
-					Value: ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
-				},
-				{
-					Key:   ptr.To("rh-org"),
-					Value: ptr.To(identity.Identity(ctx).Identity.OrgID),
-				},
-			},
+			Key:   ptr.To("rh-rid"),
+			Value: ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
 		},
+		{
+			Key:   ptr.To("rh-org"),
+			Value: ptr.To(identity.Identity(ctx).Identity.OrgID),
+		},
+	}
+	for _, key := range sortedKeys(params.Tags) {
+		tags = append(tags, types.Tag{
+			Key:   ptr.To(key),
+			Value: ptr.To(params.Tags[key]),
+		})
 	}
 
+	// volumes and network interfaces created with the instances are tagged too
+	instanceTags := tags
 	if name != "" {
-		t := types.Tag{
+		instanceTags = append(instanceTags[:len(instanceTags):len(instanceTags)], types.Tag{
 			Key:   ptr.To("Name"),
 			Value: &name,
-		}
-		input.TagSpecifications[0].Tags = append(input.TagSpecifications[0].Tags, t)
+		})
+	}
+	input.TagSpecifications = []types.TagSpecification{
+		{
+			ResourceType: types.ResourceTypeInstance,
+			Tags:         instanceTags,
+		},
+		{
+			ResourceType: types.ResourceTypeVolume,
+			Tags:         tags,
+		},
+		{
+			ResourceType: types.ResourceTypeNetworkInterface,
+			Tags:         tags,
+		},
 	}
 
 	resp, err := c.ec2.RunInstances(ctx, input)
@@ -444,6 +524,166 @@ func (c *ec2Client) RunInstances(ctx context.Context, params *clients.AWSInstanc
 	return instances, resp.ReservationId, nil
 }
 
+// blockDeviceMappings returns EBS mappings for root and data volumes. Root device name is fetched from the AMI.
+func (c *ec2Client) blockDeviceMappings(ctx context.Context, ami string, volumes *models.Volumes) ([]types.BlockDeviceMapping, error) {
+	if volumes == nil {
+		return nil, nil
+	}
+
+	var encrypted *bool
+	if volumes.Encrypted {
+		encrypted = ptr.To(true)
+	}
+
+	mappings := make([]types.BlockDeviceMapping, 0, len(volumes.DataDisks)+1)
+	if volumes.RootSizeGB > 0 || volumes.RootType != "" || volumes.Encrypted {
+		if ami == "" {
+			return nil, http.ErrRootDeviceUnknown
+		}
+
+		output, err := c.ec2.DescribeImages(ctx, &ec2.DescribeImagesInput{ImageIds: []string{ami}})
+		if err != nil {
+			if isAWSUnauthorizedError(err) {
+				err = clients.ErrUnauthorized
+			}
+			return nil, fmt.Errorf("cannot describe image %s: %w", ami, err)
+		}
+		if len(output.Images) == 0 || output.Images[0].RootDeviceName == nil {
+			return nil, fmt.Errorf("%w: %s", http.ErrRootDeviceUnknown, ami)
+		}
+
+		root := &types.EbsBlockDevice{
+			DeleteOnTermination: ptr.To(true),
+			Encrypted:           encrypted,
+			VolumeType:          types.VolumeType(volumes.RootType),
+		}
+		if volumes.RootSizeGB > 0 {
+			root.VolumeSize = ptr.To(volumes.RootSizeGB)
+		}
+		mappings = append(mappings, types.BlockDeviceMapping{
+			DeviceName: output.Images[0].RootDeviceName,
+			Ebs:        root,
+		})
+	}
+
+	// recommended device names for EBS volumes are /dev/sd[f-p]
+	for i, disk := range volumes.DataDisks {
+		mappings = append(mappings, types.BlockDeviceMapping{
+			DeviceName: ptr.To(fmt.Sprintf("/dev/sd%c", 'f'+i)),
+			Ebs: &types.EbsBlockDevice{
+				DeleteOnTermination: ptr.To(true),
+				Encrypted:           encrypted,
+				VolumeSize:          ptr.To(disk.SizeGB),
+				VolumeType:          types.VolumeType(models.DataDiskType(models.ProviderTypeAWS, disk)),
+			},
+		})
+	}
+
+	return mappings, nil
+}
+
+func (c *ec2Client) StartInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "StartInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Starting AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.StartInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.StartInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot start instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ec2Client) StopInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "StopInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Stopping AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.StopInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.StopInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot stop instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ec2Client) RebootInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "RebootInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Rebooting AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.RebootInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.RebootInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot reboot instances: %w", err)
+	}
+
+	return nil
+}
+
+func (c *ec2Client) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "TerminateInstances")
+	defer span.End()
+
+	if !c.assumed {
+		return http.ErrServiceAccountUnsupportedOp
+	}
+	logger := logger(ctx)
+	logger.Trace().Msgf("Terminating AWS EC2 instances %v", instanceIds)
+
+	input := &ec2.TerminateInstancesInput{
+		InstanceIds: instanceIds,
+	}
+	_, err := c.ec2.TerminateInstances(ctx, input)
+	if err != nil {
+		if isAWSUnauthorizedError(err) {
+			err = clients.ErrUnauthorized
+		} else if isAWSInstanceNotFoundError(err) {
+			err = fmt.Errorf("%w: %s", clients.ErrNotFound, err.Error())
+		}
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot terminate instances: %w", err)
+	}
+
+	return nil
+}
+
 func (c *ec2Client) parseRunInstancesResponse(respAWS *ec2.RunInstancesOutput) []*string {
 	instances := respAWS.Instances
 	list := make([]*string, len(instances))
@@ -467,10 +707,58 @@ func (c *ec2Client) parseDescribeInstances(respAWS *ec2.DescribeInstancesOutput)
 			PrivateIPv4: ptr.FromOrEmpty(instance.PrivateIpAddress),
 			PrivateIPv6: ptr.FromOrEmpty(instance.Ipv6Address),
 		}
+		if instance.State != nil {
+			list[i].State = instanceState(instance.State.Name)
+		}
 	}
 	return list, nil
 }
 
+// spotMarketOptions returns market options of spot instances. Only one-time requests are used so
+// terminated instances are never launched again by a persistent request, interrupted instances are
+// always terminated.
+func spotMarketOptions(spot *models.SpotOptions) *types.InstanceMarketOptionsRequest {
+	options := &types.SpotMarketOptions{
+		SpotInstanceType:             types.SpotInstanceTypeOneTime,
+		InstanceInterruptionBehavior: types.InstanceInterruptionBehaviorTerminate,
+	}
+	if spot.MaxPrice > 0 {
+		options.MaxPrice = ptr.To(strconv.FormatFloat(spot.MaxPrice, 'f', -1, 64))
+	}
+	return &types.InstanceMarketOptionsRequest{
+		MarketType:  types.MarketTypeSpot,
+		SpotOptions: options,
+	}
+}
+
+// sortedKeys returns keys of user tags in a stable order
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// instanceState maps EC2 instance state to provider-neutral state.
+func instanceState(state types.InstanceStateName) models.InstanceState {
+	switch state {
+	case types.InstanceStateNamePending:
+		return models.InstanceStatePending
+	case types.InstanceStateNameRunning:
+		return models.InstanceStateRunning
+	case types.InstanceStateNameStopping:
+		return models.InstanceStateStopping
+	case types.InstanceStateNameStopped:
+		return models.InstanceStateStopped
+	case types.InstanceStateNameShuttingDown, types.InstanceStateNameTerminated:
+		return models.InstanceStateTerminated
+	default:
+		return models.InstanceStateUnknown
+	}
+}
+
 func (c *ec2Client) GetAccountId(ctx context.Context) (string, error) {
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "GetAccountId")
 	defer span.End()
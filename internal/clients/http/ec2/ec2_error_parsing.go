@@ -11,6 +11,10 @@ func isAWSUnauthorizedError(err error) bool {
 	return isAWSOperationError(err, "api error UnauthorizedOperation")
 }
 
+func isAWSInstanceNotFoundError(err error) bool {
+	return isAWSOperationError(err, "InvalidInstanceID.NotFound")
+}
+
 func isAWSOperationError(err error, substr string) bool {
 	var oe *smithy.OperationError
 	if errors.As(err, &oe) {
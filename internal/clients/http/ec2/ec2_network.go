@@ -0,0 +1,119 @@
+package ec2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func (c *ec2Client) ListVPCs(ctx context.Context) ([]*clients.Network, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListVPCs")
+	defer span.End()
+
+	pag := ec2.NewDescribeVpcsPaginator(c.ec2, &ec2.DescribeVpcsInput{})
+	result := make([]*clients.Network, 0)
+	for pag.HasMorePages() {
+		resp, err := pag.NextPage(ctx)
+		if err != nil {
+			if isAWSUnauthorizedError(err) {
+				err = clients.ErrUnauthorized
+			}
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot list VPCs: %w", err)
+		}
+
+		for _, vpc := range resp.Vpcs {
+			result = append(result, &clients.Network{
+				ID:      ptr.FromOrEmpty(vpc.VpcId),
+				Name:    nameTag(vpc.Tags),
+				CIDR:    ptr.FromOrEmpty(vpc.CidrBlock),
+				Default: ptr.FromOrEmpty(vpc.IsDefault),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (c *ec2Client) ListSubnets(ctx context.Context, vpcID string) ([]*clients.Subnet, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListSubnets")
+	defer span.End()
+
+	pag := ec2.NewDescribeSubnetsPaginator(c.ec2, &ec2.DescribeSubnetsInput{Filters: vpcFilter(vpcID)})
+	result := make([]*clients.Subnet, 0)
+	for pag.HasMorePages() {
+		resp, err := pag.NextPage(ctx)
+		if err != nil {
+			if isAWSUnauthorizedError(err) {
+				err = clients.ErrUnauthorized
+			}
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot list subnets: %w", err)
+		}
+
+		for _, subnet := range resp.Subnets {
+			result = append(result, &clients.Subnet{
+				ID:        ptr.FromOrEmpty(subnet.SubnetId),
+				Name:      nameTag(subnet.Tags),
+				NetworkID: ptr.FromOrEmpty(subnet.VpcId),
+				CIDR:      ptr.FromOrEmpty(subnet.CidrBlock),
+				Zone:      ptr.FromOrEmpty(subnet.AvailabilityZone),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+func (c *ec2Client) ListSecurityGroups(ctx context.Context, vpcID string) ([]*clients.SecurityGroup, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListSecurityGroups")
+	defer span.End()
+
+	pag := ec2.NewDescribeSecurityGroupsPaginator(c.ec2, &ec2.DescribeSecurityGroupsInput{Filters: vpcFilter(vpcID)})
+	result := make([]*clients.SecurityGroup, 0)
+	for pag.HasMorePages() {
+		resp, err := pag.NextPage(ctx)
+		if err != nil {
+			if isAWSUnauthorizedError(err) {
+				err = clients.ErrUnauthorized
+			}
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("cannot list security groups: %w", err)
+		}
+
+		for _, group := range resp.SecurityGroups {
+			result = append(result, &clients.SecurityGroup{
+				ID:          ptr.FromOrEmpty(group.GroupId),
+				Name:        ptr.FromOrEmpty(group.GroupName),
+				NetworkID:   ptr.FromOrEmpty(group.VpcId),
+				Description: ptr.FromOrEmpty(group.Description),
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// vpcFilter returns a filter by VPC ID or no filter when the ID is empty
+func vpcFilter(vpcID string) []types.Filter {
+	if vpcID == "" {
+		return nil
+	}
+	return []types.Filter{{Name: ptr.To("vpc-id"), Values: []string{vpcID}}}
+}
+
+// nameTag returns value of the Name tag or empty string
+func nameTag(tags []types.Tag) string {
+	for _, tag := range tags {
+		if ptr.FromOrEmpty(tag.Key) == "Name" {
+			return ptr.FromOrEmpty(tag.Value)
+		}
+	}
+	return ""
+}
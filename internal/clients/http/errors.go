@@ -28,4 +28,14 @@ var (
 	ErrServiceAccountUnsupportedOp = usrerr.New(500, "unsupported operation on service account", "")
 	ErrARNParsing                  = usrerr.New(500, "ARN parsing error", "")
 	ErrNoReservation               = usrerr.New(404, "no reservation was found in AWS response", "")
+	ErrRootDeviceUnknown           = usrerr.New(400, "root device of the image is unknown", "root volume can only be configured for AMIs")
+)
+
+// Azure
+var (
+	ErrTemplateSpecNoVersion  = usrerr.New(400, "template spec has no versions", "")
+	ErrTemplateNoVMParameter  = usrerr.New(400, "template spec does not declare vmName parameter", "the template must name virtual machines by the vmName parameter")
+	ErrTemplateDeploymentNoVM = usrerr.New(500, "template deployment did not create a virtual machine", "")
+	ErrNetworkNoSubnet        = usrerr.New(400, "virtual network has no subnets usable by virtual machines", "")
+	ErrNetworkLocation        = usrerr.New(400, "virtual network is in a different location", "network interfaces must be in the location of the virtual network")
 )
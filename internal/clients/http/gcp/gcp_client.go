@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
@@ -20,6 +21,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
@@ -141,6 +143,61 @@ func (c *gcpClient) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchT
 	return templatesList, nextToken, nil
 }
 
+// attachedDisks returns boot disk from the image and data disks with labels of the instance. Disk types
+// in instance properties are names (e.g. pd-ssd) rather than zonal URLs.
+func attachedDisks(imageName string, volumes *models.Volumes, labels map[string]string) []*computepb.AttachedDisk {
+	boot := &computepb.AttachedDisk{
+		InitializeParams: &computepb.AttachedDiskInitializeParams{
+			SourceImage: ptr.To(imageName),
+			Labels:      labels,
+		},
+		AutoDelete: ptr.To(true),
+		Boot:       ptr.To(true),
+		Type:       ptr.To(computepb.AttachedDisk_PERSISTENT.String()),
+	}
+	if volumes == nil {
+		return []*computepb.AttachedDisk{boot}
+	}
+
+	if volumes.RootSizeGB > 0 {
+		boot.InitializeParams.DiskSizeGb = ptr.To(int64(volumes.RootSizeGB))
+	}
+	if volumes.RootType != "" {
+		boot.InitializeParams.DiskType = ptr.To(volumes.RootType)
+	}
+
+	disks := make([]*computepb.AttachedDisk, 0, len(volumes.DataDisks)+1)
+	disks = append(disks, boot)
+	for _, disk := range volumes.DataDisks {
+		disks = append(disks, &computepb.AttachedDisk{
+			InitializeParams: &computepb.AttachedDiskInitializeParams{
+				DiskSizeGb: ptr.To(int64(disk.SizeGB)),
+				DiskType:   ptr.To(models.DataDiskType(models.ProviderTypeGCP, disk)),
+				Labels:     labels,
+			},
+			AutoDelete: ptr.To(true),
+			Boot:       ptr.To(false),
+			Type:       ptr.To(computepb.AttachedDisk_PERSISTENT.String()),
+		})
+	}
+	return disks
+}
+
+// spotScheduling returns scheduling of spot instances, these cannot be restarted automatically and
+// are terminated on host maintenance.
+func spotScheduling(spot *models.SpotOptions) *computepb.Scheduling {
+	action := computepb.Scheduling_DELETE
+	if spot.StopOnInterruption() {
+		action = computepb.Scheduling_STOP
+	}
+	return &computepb.Scheduling{
+		ProvisioningModel:         ptr.To(computepb.Scheduling_SPOT.String()),
+		InstanceTerminationAction: ptr.To(action.String()),
+		AutomaticRestart:          ptr.To(false),
+		OnHostMaintenance:         ptr.To(computepb.Scheduling_TERMINATE.String()),
+	}
+}
+
 func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInstanceParams, amount int64) ([]*string, *string, error) {
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "InsertInstances")
 	defer span.End()
@@ -159,17 +216,19 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 		params.Zone = config.GCP.DefaultZone
 	}
 
-	pk := models.Pubkey{Body: params.KeyBody}
-	pkBody, err := pk.BodyWithUsername(ctx)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to get pubkey body with username: %w", err)
-	}
+	// empty key body means the key was uploaded into project metadata
+	metadata := make([]*computepb.Items, 0, 2)
+	if params.KeyBody != "" {
+		pk := models.Pubkey{Body: params.KeyBody}
+		pkBody, err := pk.BodyWithUsername(ctx, config.GCP.LoginUsername)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to get pubkey body with username: %w", err)
+		}
 
-	metadata := []*computepb.Items{
-		{
+		metadata = append(metadata, &computepb.Items{
 			Key:   ptr.To("ssh-keys"),
 			Value: ptr.To(pkBody),
-		},
+		})
 	}
 	if params.StartupScript != "" {
 		metadata = append(metadata, &computepb.Items{
@@ -178,6 +237,15 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 		})
 	}
 
+	labels := map[string]string{
+		"rh-rid":  config.EnvironmentPrefix("r", strconv.FormatInt(params.ReservationID, 10)),
+		"rh-uuid": params.UUID,
+		"rh-org":  identity.Identity(ctx).Identity.OrgID,
+	}
+	for key, value := range params.Labels {
+		labels[key] = value
+	}
+
 	req := &computepb.BulkInsertInstanceRequest{
 		Project: c.auth.Payload,
 		Zone:    params.Zone,
@@ -186,21 +254,9 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 			Count:       &amount,
 			MinCount:    &amount,
 			InstanceProperties: &computepb.InstanceProperties{
-				Labels: map[string]string{
-					"rh-rid":  config.EnvironmentPrefix("r", strconv.FormatInt(params.ReservationID, 10)),
-					"rh-uuid": params.UUID,
-					"rh-org":  identity.Identity(ctx).Identity.OrgID,
-				},
+				Labels: labels,
 				NetworkInterfaces: []*computepb.NetworkInterface{
-					{
-						AccessConfigs: []*computepb.AccessConfig{
-							{
-								Name: ptr.To("External NAT"),
-								Type: ptr.To("ONE_TO_ONE_NAT"),
-							},
-						},
-						Name: ptr.To("global/networks/default"),
-					},
+					networkInterface(params),
 				},
 				Metadata: &computepb.Metadata{
 					Items: metadata,
@@ -218,17 +274,12 @@ func (c *gcpClient) InsertInstances(ctx context.Context, params *clients.GCPInst
 		req.BulkInsertInstanceResourceResource.InstanceProperties.MachineType = ptr.To(params.MachineType)
 	}
 
+	if params.Spot != nil {
+		req.BulkInsertInstanceResourceResource.InstanceProperties.Scheduling = spotScheduling(params.Spot)
+	}
+
 	if params.ImageName != "" {
-		req.BulkInsertInstanceResourceResource.InstanceProperties.Disks = []*computepb.AttachedDisk{
-			{
-				InitializeParams: &computepb.AttachedDiskInitializeParams{
-					SourceImage: &params.ImageName,
-				},
-				AutoDelete: ptr.To(true),
-				Boot:       ptr.To(true),
-				Type:       ptr.To(computepb.AttachedDisk_PERSISTENT.String()),
-			},
-		}
+		req.BulkInsertInstanceResourceResource.InstanceProperties.Disks = attachedDisks(params.ImageName, params.Volumes, labels)
 	}
 
 	op, err := client.BulkInsert(ctx, req)
@@ -314,7 +365,7 @@ func (c *gcpClient) GetInstanceDescriptionByID(ctx context.Context, id, zone str
 		return nil, fmt.Errorf("unable to get instance: %w", err)
 	}
 	instanceId := strconv.FormatUint(instance.GetId(), 10)
-	instanceDesc := clients.InstanceDescription{ID: instanceId}
+	instanceDesc := clients.InstanceDescription{ID: instanceId, State: instanceState(instance.GetStatus())}
 	for _, n := range instance.NetworkInterfaces {
 		instanceDesc.PrivateIPv4 = ptr.FromOrEmpty(n.NetworkIP)
 		if len(n.AccessConfigs) > 0 && n.AccessConfigs[0] != nil {
@@ -324,3 +375,130 @@ func (c *gcpClient) GetInstanceDescriptionByID(ctx context.Context, id, zone str
 	}
 	return &instanceDesc, nil
 }
+
+// instanceState maps GCP instance status to provider-neutral state, TERMINATED means stopped in GCP.
+func instanceState(status string) models.InstanceState {
+	switch status {
+	case "PROVISIONING", "STAGING", "REPAIRING":
+		return models.InstanceStatePending
+	case "RUNNING":
+		return models.InstanceStateRunning
+	case "STOPPING", "SUSPENDING":
+		return models.InstanceStateStopping
+	case "STOPPED", "SUSPENDED", "TERMINATED":
+		return models.InstanceStateStopped
+	default:
+		return models.InstanceStateUnknown
+	}
+}
+
+func (c *gcpClient) StartInstance(ctx context.Context, id, zone string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "StartInstance")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Starting GCP instance %s in zone %s", id, zone)
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not get instances client")
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Start(ctx, &computepb.StartInstanceRequest{Instance: id, Project: c.auth.Payload, Zone: zone})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot start instance: %w", err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot start instance: %w", err)
+	}
+
+	return nil
+}
+
+func (c *gcpClient) StopInstance(ctx context.Context, id, zone string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "StopInstance")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Stopping GCP instance %s in zone %s", id, zone)
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not get instances client")
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Stop(ctx, &computepb.StopInstanceRequest{Instance: id, Project: c.auth.Payload, Zone: zone})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot stop instance: %w", err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot stop instance: %w", err)
+	}
+
+	return nil
+}
+
+func (c *gcpClient) ResetInstance(ctx context.Context, id, zone string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ResetInstance")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Resetting GCP instance %s in zone %s", id, zone)
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not get instances client")
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Reset(ctx, &computepb.ResetInstanceRequest{Instance: id, Project: c.auth.Payload, Zone: zone})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot reset instance: %w", err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot reset instance: %w", err)
+	}
+
+	return nil
+}
+
+func (c *gcpClient) DeleteInstance(ctx context.Context, id, zone string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DeleteInstance")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Deleting GCP instance %s in zone %s", id, zone)
+
+	client, err := c.newInstancesClient(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not get instances client")
+		return fmt.Errorf("unable to get instances client: %w", err)
+	}
+	defer client.Close()
+
+	op, err := client.Delete(ctx, &computepb.DeleteInstanceRequest{Instance: id, Project: c.auth.Payload, Zone: zone})
+	var gErr *googleapi.Error
+	if errors.As(err, &gErr) && gErr.Code == http.StatusNotFound {
+		return fmt.Errorf("%w: instance %s", clients.ErrNotFound, id)
+	} else if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot delete instance: %w", err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("cannot delete instance: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/api/iterator"
+)
+
+const defaultNetworkID = "global/networks/default"
+
+// networkID returns partial URL of a network (global/networks/NAME) from its full URL.
+func networkID(networkURL string) string {
+	return "global/networks/" + path.Base(networkURL)
+}
+
+// subnetworkID returns partial URL of a subnetwork (regions/REGION/subnetworks/NAME).
+func subnetworkID(regionURL, name string) string {
+	return fmt.Sprintf("regions/%s/subnetworks/%s", path.Base(regionURL), name)
+}
+
+func (c *gcpClient) newNetworksClient(ctx context.Context) (*compute.NetworksClient, error) {
+	client, err := compute.NewNetworksRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP networks client: %w", err)
+	}
+	return client, nil
+}
+
+func (c *gcpClient) newSubnetworksClient(ctx context.Context) (*compute.SubnetworksClient, error) {
+	client, err := compute.NewSubnetworksRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP subnetworks client: %w", err)
+	}
+	return client, nil
+}
+
+func (c *gcpClient) ListNetworks(ctx context.Context) ([]*clients.Network, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListNetworks")
+	defer span.End()
+
+	client, err := c.newNetworksClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	networks := make([]*clients.Network, 0)
+	iter := client.List(ctx, &computepb.ListNetworksRequest{
+		Project: c.auth.Payload,
+	})
+	for {
+		network, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, fmt.Errorf("iterator error: %w", err)
+		}
+		id := networkID(network.GetName())
+		networks = append(networks, &clients.Network{
+			ID:      id,
+			Name:    network.GetName(),
+			CIDR:    network.GetIPv4Range(),
+			Default: id == defaultNetworkID,
+		})
+	}
+	return networks, nil
+}
+
+// ListSubnetworks returns subnetworks in a region or in all regions when region is empty, optionally
+// filtered by network ID (global/networks/NAME).
+func (c *gcpClient) ListSubnetworks(ctx context.Context, region, network string) ([]*clients.Subnet, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "ListSubnetworks")
+	defer span.End()
+
+	client, err := c.newSubnetworksClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	var subnetworks []*computepb.Subnetwork
+	if region != "" {
+		iter := client.List(ctx, &computepb.ListSubnetworksRequest{
+			Project: c.auth.Payload,
+			Region:  region,
+		})
+		for {
+			subnetwork, err := iter.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				return nil, fmt.Errorf("iterator error: %w", err)
+			}
+			subnetworks = append(subnetworks, subnetwork)
+		}
+	} else {
+		iter := client.AggregatedList(ctx, &computepb.AggregatedListSubnetworksRequest{
+			Project: c.auth.Payload,
+		})
+		for {
+			pair, err := iter.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				return nil, fmt.Errorf("iterator error: %w", err)
+			}
+			subnetworks = append(subnetworks, pair.Value.GetSubnetworks()...)
+		}
+	}
+
+	subnets := make([]*clients.Subnet, 0, len(subnetworks))
+	for _, subnetwork := range subnetworks {
+		subnetNetworkID := networkID(subnetwork.GetNetwork())
+		if network != "" && subnetNetworkID != network {
+			continue
+		}
+		subnets = append(subnets, &clients.Subnet{
+			ID:        subnetworkID(subnetwork.GetRegion(), subnetwork.GetName()),
+			Name:      subnetwork.GetName(),
+			NetworkID: subnetNetworkID,
+			CIDR:      subnetwork.GetIpCidrRange(),
+		})
+	}
+	return subnets, nil
+}
+
+// networkInterface returns the network interface of new instances. The default network is used when neither
+// network nor subnetwork are set, the network is inferred by GCP when only subnetwork is set.
+func networkInterface(params *clients.GCPInstanceParams) *computepb.NetworkInterface {
+	nic := &computepb.NetworkInterface{}
+	if params.NetworkID != "" {
+		nic.Network = ptr.To(params.NetworkID)
+	}
+	if params.SubnetID != "" {
+		nic.Subnetwork = ptr.To(params.SubnetID)
+	}
+	if params.NetworkID == "" && params.SubnetID == "" {
+		nic.Network = ptr.To(defaultNetworkID)
+	}
+
+	if params.ExternalIP == nil || *params.ExternalIP {
+		nic.AccessConfigs = []*computepb.AccessConfig{
+			{
+				Name: ptr.To("External NAT"),
+				Type: ptr.To(computepb.AccessConfig_ONE_TO_ONE_NAT.String()),
+			},
+		}
+	}
+	return nic
+}
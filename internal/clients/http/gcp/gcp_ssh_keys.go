@@ -0,0 +1,196 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// project metadata key holding newline separated "username:type body" lines
+	sshKeysMetadataKey = "ssh-keys"
+
+	// number of read-modify-write attempts when project metadata is changed concurrently
+	maxMetadataAttempts = 5
+)
+
+func (c *gcpClient) newProjectsClient(ctx context.Context) (*compute.ProjectsClient, error) {
+	client, err := compute.NewProjectsRESTClient(ctx, c.options...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create GCP projects client: %w", err)
+	}
+	return client, nil
+}
+
+// projectSSHKeys returns project common metadata and lines of the ssh-keys item.
+func (c *gcpClient) projectSSHKeys(ctx context.Context, client *compute.ProjectsClient) (*computepb.Metadata, []string, error) {
+	project, err := client.Get(ctx, &computepb.GetProjectRequest{Project: c.auth.Payload})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get project: %w", err)
+	}
+
+	metadata := project.GetCommonInstanceMetadata()
+	if metadata == nil {
+		metadata = &computepb.Metadata{}
+	}
+
+	var keys []string
+	for _, item := range metadata.Items {
+		if item.GetKey() == sshKeysMetadataKey {
+			for _, line := range strings.Split(item.GetValue(), "\n") {
+				if strings.TrimSpace(line) != "" {
+					keys = append(keys, line)
+				}
+			}
+		}
+	}
+	return metadata, keys, nil
+}
+
+// setProjectSSHKeys replaces the ssh-keys item of project common metadata.
+func (c *gcpClient) setProjectSSHKeys(ctx context.Context, client *compute.ProjectsClient, metadata *computepb.Metadata, keys []string) error {
+	items := make([]*computepb.Items, 0, len(metadata.Items)+1)
+	for _, item := range metadata.Items {
+		if item.GetKey() != sshKeysMetadataKey {
+			items = append(items, item)
+		}
+	}
+	if len(keys) > 0 {
+		items = append(items, &computepb.Items{
+			Key:   ptr.To(sshKeysMetadataKey),
+			Value: ptr.To(strings.Join(keys, "\n")),
+		})
+	}
+
+	// fingerprint makes the update fail when metadata was changed concurrently
+	op, err := client.SetCommonInstanceMetadata(ctx, &computepb.SetCommonInstanceMetadataProjectRequest{
+		Project: c.auth.Payload,
+		MetadataResource: &computepb.Metadata{
+			Fingerprint: metadata.Fingerprint,
+			Items:       items,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("cannot set project metadata: %w", err)
+	}
+	if err = op.Wait(ctx); err != nil {
+		return fmt.Errorf("cannot set project metadata: %w", err)
+	}
+	return nil
+}
+
+// modifyProjectSSHKeys reads the ssh-keys item, modifies it and writes it back. The fingerprint precondition
+// fails when another launch changed the metadata in the meantime, the whole read-modify-write is repeated
+// then. Metadata is not written when modify returns false.
+func (c *gcpClient) modifyProjectSSHKeys(ctx context.Context, client *compute.ProjectsClient, modify func(keys []string) ([]string, bool)) error {
+	logger := logger(ctx)
+	var err error
+	for attempt := 1; attempt <= maxMetadataAttempts; attempt++ {
+		metadata, keys, errGet := c.projectSSHKeys(ctx, client)
+		if errGet != nil {
+			return errGet
+		}
+
+		modified, changed := modify(keys)
+		if !changed {
+			return nil
+		}
+
+		err = c.setProjectSSHKeys(ctx, client, metadata, modified)
+		var gErr *googleapi.Error
+		if !errors.As(err, &gErr) || gErr.Code != http.StatusPreconditionFailed {
+			return err
+		}
+		logger.Debug().Msgf("Project %s metadata changed concurrently, attempt %d", c.auth.Payload, attempt)
+	}
+	return err
+}
+
+func (c *gcpClient) AddProjectSSHKey(ctx context.Context, key string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "AddProjectSSHKey")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Adding SSH key into project %s metadata", c.auth.Payload)
+
+	client, err := c.newProjectsClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	err = c.modifyProjectSSHKeys(ctx, client, func(keys []string) ([]string, bool) {
+		for _, k := range keys {
+			if k == key {
+				return keys, false
+			}
+		}
+		return append(keys, key), true
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
+
+func (c *gcpClient) HasProjectSSHKey(ctx context.Context, key string) (bool, error) {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "HasProjectSSHKey")
+	defer span.End()
+
+	client, err := c.newProjectsClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer client.Close()
+
+	_, keys, err := c.projectSSHKeys(ctx, client)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return false, err
+	}
+	for _, k := range keys {
+		if k == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *gcpClient) RemoveProjectSSHKey(ctx context.Context, key string) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "RemoveProjectSSHKey")
+	defer span.End()
+
+	logger := logger(ctx)
+	logger.Trace().Msgf("Removing SSH key from project %s metadata", c.auth.Payload)
+
+	client, err := c.newProjectsClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	err = c.modifyProjectSSHKeys(ctx, client, func(keys []string) ([]string, bool) {
+		remaining := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if k != key {
+				remaining = append(remaining, k)
+			}
+		}
+		return remaining, len(remaining) != len(keys)
+	})
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	return nil
+}
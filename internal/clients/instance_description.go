@@ -1,5 +1,7 @@
 package clients
 
+import "github.com/RHEnVision/provisioning-backend/internal/models"
+
 type AzureInstanceID string
 
 // InstanceDescription defines a model for an instance description
@@ -18,4 +20,14 @@ type InstanceDescription struct {
 
 	// The IPv6 of the instance or empty when not available
 	PrivateIPv6 string `json:"private_ipv6,omitempty" yaml:"private_ipv6"`
+
+	// The power state of the instance or empty when not available
+	State models.InstanceState `json:"state,omitempty" yaml:"state"`
+}
+
+// InstanceDescriptionList is a cacheable list of instance descriptions of a reservation.
+type InstanceDescriptionList []*InstanceDescription
+
+func (InstanceDescriptionList) CacheKeyName() string {
+	return "instance_descriptions"
 }
@@ -32,6 +32,25 @@ type GCPInstanceParams struct {
 
 	// StartupScript contains metadata startup script (GCP tools must be installed on the image)
 	StartupScript string
+
+	// Volumes configure boot and data disks, nil for image defaults
+	Volumes *models.Volumes
+
+	// Labels defined by the user, applied to instances and disks
+	Labels map[string]string
+
+	// Spot launches preemptible instances with spot provisioning model, nil for standard instances
+	Spot *models.SpotOptions
+
+	// NetworkID is a partial URL of the network (global/networks/NAME), default network is used when
+	// neither network nor subnetwork are set
+	NetworkID string
+
+	// SubnetID is a partial URL of the subnetwork (regions/REGION/subnetworks/NAME)
+	SubnetID string
+
+	// ExternalIP assigns an ephemeral external IP address when nil or true
+	ExternalIP *bool
 }
 
 type AWSInstanceParams struct {
@@ -44,7 +63,7 @@ type AWSInstanceParams struct {
 	// InstanceType to launch
 	InstanceType types.InstanceType
 
-	// Zone - to deploy into
+	// Zone - availability zone to deploy into, chosen by AWS when empty
 	Zone string
 
 	// Pubkey to use for the instance access
@@ -52,6 +71,24 @@ type AWSInstanceParams struct {
 
 	// UserData for the instance launch
 	UserData []byte
+
+	// SubnetID to launch the instances into, empty for the default VPC
+	SubnetID string
+
+	// SecurityGroupIDs to assign to the instances, empty for the default security group
+	SecurityGroupIDs []string
+
+	// AssociatePublicIP overrides the subnet setting of public IP address assignment when set
+	AssociatePublicIP *bool
+
+	// Volumes configure root and data volumes, nil for AMI block device mapping
+	Volumes *models.Volumes
+
+	// Tags defined by the user, applied to instances, volumes and network interfaces
+	Tags map[string]string
+
+	// Spot launches spot instances via market options, nil for on-demand instances
+	Spot *models.SpotOptions
 }
 
 // AzureInstanceParams define parameters for a single instance launch on Azure.
@@ -59,6 +96,9 @@ type AzureInstanceParams struct {
 	// Location - to deploy into
 	Location string
 
+	// Zone - availability zone (1, 2 or 3) to deploy into, regional VM is deployed when empty
+	Zone string
+
 	// ResourceGroupName to launch the instance in
 	ResourceGroupName string
 
@@ -75,6 +115,27 @@ type AzureInstanceParams struct {
 	// UserData for the instance launch
 	UserData []byte
 
-	// Tags carries list of key-value tags
+	// Tags carries list of key-value tags, applied to instances, disks, network interfaces and public IPs
 	Tags map[string]*string
+
+	// Volumes configure OS and data disks, nil for image defaults
+	Volumes *models.Volumes
+
+	// Spot launches VMs with spot priority, nil for regular priority
+	Spot *models.SpotOptions
+
+	// NetworkID of an existing virtual network, its first subnet is used unless SubnetID is set
+	NetworkID string
+
+	// SubnetID of an existing subnet, the shared virtual network in the resource group is created
+	// when neither NetworkID nor SubnetID are set
+	SubnetID string
+
+	// SecurityGroupID of an existing network security group, the shared one is used with the shared
+	// virtual network and none (subnet rules) with existing networks when empty
+	SecurityGroupID string
+
+	// LaunchTemplateID is a Template Spec (or its version) resource ID to deploy the VMs from,
+	// networking, disks and spot settings are then defined by the template
+	LaunchTemplateID string
 }
@@ -77,6 +77,9 @@ type EC2 interface {
 	// GetPubkeyName fetches the AWS key name using given pubkey fingerprint.
 	GetPubkeyName(ctx context.Context, fingerprint string) (string, error)
 
+	// GetPubkeyHandle fetches the AWS ID of a key-pair imported with given tag.
+	GetPubkeyHandle(ctx context.Context, tag string) (string, error)
+
 	// DeleteSSHKey deletes a given ssh key-pair found by AWS ID.
 	DeleteSSHKey(ctx context.Context, handle string) error
 
@@ -86,6 +89,15 @@ type EC2 interface {
 	// ListLaunchTemplates lists all launch templates and returns the next page token.
 	ListLaunchTemplates(ctx context.Context) ([]*LaunchTemplate, string, error)
 
+	// ListVPCs lists all VPCs in the region.
+	ListVPCs(ctx context.Context) ([]*Network, error)
+
+	// ListSubnets lists all subnets in the region, optionally filtered by VPC ID (empty string for all).
+	ListSubnets(ctx context.Context, vpcID string) ([]*Subnet, error)
+
+	// ListSecurityGroups lists all security groups in the region, optionally filtered by VPC ID (empty string for all).
+	ListSecurityGroups(ctx context.Context, vpcID string) ([]*SecurityGroup, error)
+
 	// RunInstances launches one or more instances.
 	//
 	// All arguments are required except: launchTemplateID (empty string means no template in use).
@@ -97,7 +109,21 @@ type EC2 interface {
 
 	CheckPermission(ctx context.Context, auth *Authentication) ([]string, error)
 
+	// DescribeInstanceDetails returns descriptions of instances, returns ErrNotFound when any of the instances does not exist.
 	DescribeInstanceDetails(ctx context.Context, InstanceIds []string) ([]*InstanceDescription, error)
+
+	// StartInstances starts one or more stopped instances.
+	StartInstances(ctx context.Context, instanceIds []string) error
+
+	// StopInstances stops one or more running instances.
+	StopInstances(ctx context.Context, instanceIds []string) error
+
+	// RebootInstances requests a reboot of one or more instances.
+	RebootInstances(ctx context.Context, instanceIds []string) error
+
+	// TerminateInstances terminates one or more instances, terminated instances cannot be started again.
+	// Returns ErrNotFound when any of the instances does not exist, no instance is terminated then.
+	TerminateInstances(ctx context.Context, instanceIds []string) error
 }
 
 // GetAzureClient returns an Azure client with customer's subscription ID.
@@ -120,6 +146,42 @@ type Azure interface {
 	CreateVMs(ctx context.Context, instanceParams AzureInstanceParams, amount int64, vmNamePrefix string) (vmIds []InstanceDescription, err error)
 
 	ListResourceGroups(ctx context.Context) ([]string, error)
+
+	// ListLaunchTemplates lists all Template Specs and returns the next page token.
+	ListLaunchTemplates(ctx context.Context) ([]*LaunchTemplate, string, error)
+
+	// ListVirtualNetworks lists all virtual networks in the subscription.
+	ListVirtualNetworks(ctx context.Context) ([]*Network, error)
+
+	// ListSubnets lists subnets of a virtual network or all subnets when vnetID is empty.
+	ListSubnets(ctx context.Context, vnetID string) ([]*Subnet, error)
+
+	// ListSecurityGroups lists all network security groups in the subscription.
+	ListSecurityGroups(ctx context.Context) ([]*SecurityGroup, error)
+
+	// StartVM starts a stopped or deallocated virtual machine found by its full resource ID.
+	StartVM(ctx context.Context, vmID string) error
+
+	// DeallocateVM stops a virtual machine and releases its compute resources so it is no longer billed.
+	DeallocateVM(ctx context.Context, vmID string) error
+
+	// RestartVM restarts a running virtual machine.
+	RestartVM(ctx context.Context, vmID string) error
+
+	// DeleteVM deletes a virtual machine, returns ErrNotFound when it does not exist.
+	DeleteVM(ctx context.Context, vmID string) error
+
+	// DescribeVM returns current power state and network addresses of a virtual machine found by its full resource ID.
+	DescribeVM(ctx context.Context, vmID string) (*InstanceDescription, error)
+
+	// ImportSSHKey uploads public key as SSH public key resource with given name returning its full resource ID.
+	ImportSSHKey(ctx context.Context, resourceGroupName, location string, pk *models.Pubkey, name string) (string, error)
+
+	// GetSSHKey fetches SSH public key resource by its full resource ID, returns ErrPubkeyNotFound when it does not exist.
+	GetSSHKey(ctx context.Context, keyID string) (string, error)
+
+	// DeleteSSHKey deletes SSH public key resource found by its full resource ID.
+	DeleteSSHKey(ctx context.Context, keyID string) error
 }
 
 type ServiceAzure interface {
@@ -158,4 +220,32 @@ type GCP interface {
 
 	// ListLaunchTemplates lists all launch templates and returns the next page token.
 	ListLaunchTemplates(ctx context.Context) ([]*LaunchTemplate, string, error)
+
+	// ListNetworks lists all VPC networks of the project.
+	ListNetworks(ctx context.Context) ([]*Network, error)
+
+	// ListSubnetworks lists subnetworks in a region (all regions when empty), optionally filtered by network ID.
+	ListSubnetworks(ctx context.Context, region, network string) ([]*Subnet, error)
+
+	// StartInstance starts a stopped instance in the given zone.
+	StartInstance(ctx context.Context, id, zone string) error
+
+	// StopInstance stops a running instance in the given zone.
+	StopInstance(ctx context.Context, id, zone string) error
+
+	// ResetInstance performs a hard reset of an instance in the given zone.
+	ResetInstance(ctx context.Context, id, zone string) error
+
+	// DeleteInstance deletes an instance in the given zone, returns ErrNotFound when it does not exist.
+	DeleteInstance(ctx context.Context, id, zone string) error
+
+	// AddProjectSSHKey appends a key in the "username:type body" format into project ssh-keys metadata
+	// unless it is already present.
+	AddProjectSSHKey(ctx context.Context, key string) error
+
+	// HasProjectSSHKey returns true when the key is present in project ssh-keys metadata.
+	HasProjectSSHKey(ctx context.Context, key string) (bool, error)
+
+	// RemoveProjectSSHKey removes the key from project ssh-keys metadata.
+	RemoveProjectSSHKey(ctx context.Context, key string) error
 }
@@ -0,0 +1,49 @@
+package clients
+
+// Network represents a generic virtual network of a hyperscaler (VPC for AWS EC2, VNet for Azure).
+type Network struct {
+	// ID is an identifier, for example "vpc-0a4caa2cf5b097ce1" for AWS EC2 or full resource ID for Azure.
+	ID string
+
+	// Name of the network, can be empty when not set by the user.
+	Name string
+
+	// CIDR is the primary address range of the network.
+	CIDR string
+
+	// Default is set for the default network of a region.
+	Default bool
+}
+
+// Subnet represents a generic subnet of a virtual network.
+type Subnet struct {
+	// ID is an identifier, for example "subnet-06e6b4eb3f8b6a0fb" for AWS EC2 or full resource ID for Azure.
+	ID string
+
+	// Name of the subnet, can be empty when not set by the user.
+	Name string
+
+	// NetworkID is an identifier of the network the subnet belongs to.
+	NetworkID string
+
+	// CIDR is the address range of the subnet.
+	CIDR string
+
+	// Zone the subnet is located in, empty for regional subnets.
+	Zone string
+}
+
+// SecurityGroup represents a generic set of firewall rules which can be assigned to instances.
+type SecurityGroup struct {
+	// ID is an identifier, for example "sg-0d2e7b9f3c1a8e4b5" for AWS EC2 or full NSG resource ID for Azure.
+	ID string
+
+	// Name of the security group.
+	Name string
+
+	// NetworkID is an identifier of the network the security group belongs to, empty for Azure.
+	NetworkID string
+
+	// Description of the security group, user defined.
+	Description string
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 )
 
 var ErrNotStartedVM = errors.New("the VM under given resumeToken not started")
@@ -17,6 +19,8 @@ type AzureClientStub struct {
 	startedVms []*armcompute.VirtualMachine
 	createdVms []*armcompute.VirtualMachine
 	createdRgs []*armresources.ResourceGroup
+	vmActions  map[string]string
+	sshKeys    []string
 }
 
 func DidCreateAzureResourceGroup(ctx context.Context, name string) bool {
@@ -32,6 +36,20 @@ func DidCreateAzureResourceGroup(ctx context.Context, name string) bool {
 	return false
 }
 
+// StubAzureResourceGroupLocation returns location of a created resource group or empty string
+func StubAzureResourceGroupLocation(ctx context.Context, name string) string {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return ""
+	}
+	for _, rg := range client.createdRgs {
+		if *rg.Name == name {
+			return *rg.Location
+		}
+	}
+	return ""
+}
+
 func CountStubAzureVMs(ctx context.Context) int {
 	client, err := getAzureClientStub(ctx)
 	if err != nil {
@@ -40,6 +58,24 @@ func CountStubAzureVMs(ctx context.Context) int {
 	return len(client.createdVms)
 }
 
+// StubLastAzureVMAction returns the last lifecycle action performed on a VM or empty string
+func StubLastAzureVMAction(ctx context.Context, vmID string) string {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return ""
+	}
+	return client.vmActions[vmID]
+}
+
+// CountStubAzureSSHKeys returns amount of SSH public key resources uploaded into the stub
+func CountStubAzureSSHKeys(ctx context.Context) int {
+	client, err := getAzureClientStub(ctx)
+	if err != nil {
+		return 0
+	}
+	return len(client.sshKeys)
+}
+
 func (stub *AzureClientStub) Status(ctx context.Context) error {
 	return nil
 }
@@ -111,3 +147,127 @@ func (stub *AzureClientStub) TenantId(ctx context.Context) (clients.AzureTenantI
 func (stub *AzureClientStub) ListResourceGroups(ctx context.Context) ([]string, error) {
 	return []string{"firstGroup", "secondGroup", "test"}, nil
 }
+
+func (stub *AzureClientStub) ListLaunchTemplates(ctx context.Context) ([]*clients.LaunchTemplate, string, error) {
+	return []*clients.LaunchTemplate{
+		{
+			ID:   "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/templates/providers/Microsoft.Resources/templateSpecs/rhel-web",
+			Name: "rhel-web",
+		},
+		{
+			ID:   "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/templates/providers/Microsoft.Resources/templateSpecs/rhel-database",
+			Name: "rhel-database",
+		},
+	}, "", nil
+}
+
+const stubVNetID = "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet"
+
+func (stub *AzureClientStub) ListVirtualNetworks(ctx context.Context) ([]*clients.Network, error) {
+	return []*clients.Network{
+		{
+			ID:   stubVNetID,
+			Name: "hub-vnet",
+			CIDR: "10.10.0.0/16",
+		},
+	}, nil
+}
+
+func (stub *AzureClientStub) ListSubnets(ctx context.Context, vnetID string) ([]*clients.Subnet, error) {
+	if vnetID != "" && vnetID != stubVNetID {
+		return []*clients.Subnet{}, nil
+	}
+	return []*clients.Subnet{
+		{
+			ID:        stubVNetID + "/subnets/workloads",
+			Name:      "workloads",
+			NetworkID: stubVNetID,
+			CIDR:      "10.10.1.0/24",
+		},
+	}, nil
+}
+
+func (stub *AzureClientStub) ListSecurityGroups(ctx context.Context) ([]*clients.SecurityGroup, error) {
+	return []*clients.SecurityGroup{
+		{
+			ID:   "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/hub/providers/Microsoft.Network/networkSecurityGroups/ssh",
+			Name: "ssh",
+		},
+	}, nil
+}
+
+func (stub *AzureClientStub) recordVMAction(vmID, action string) error {
+	for _, vm := range stub.createdVms {
+		if *vm.ID == vmID {
+			if stub.vmActions == nil {
+				stub.vmActions = make(map[string]string)
+			}
+			stub.vmActions[vmID] = action
+			return nil
+		}
+	}
+	return ErrMissingInstanceID
+}
+
+func (stub *AzureClientStub) StartVM(ctx context.Context, vmID string) error {
+	return stub.recordVMAction(vmID, "start")
+}
+
+func (stub *AzureClientStub) DeallocateVM(ctx context.Context, vmID string) error {
+	return stub.recordVMAction(vmID, "stop")
+}
+
+func (stub *AzureClientStub) RestartVM(ctx context.Context, vmID string) error {
+	return stub.recordVMAction(vmID, "reboot")
+}
+
+func (stub *AzureClientStub) DeleteVM(ctx context.Context, vmID string) error {
+	err := stub.recordVMAction(vmID, "terminate")
+	if errors.Is(err, ErrMissingInstanceID) {
+		return fmt.Errorf("%w: %s", clients.ErrNotFound, err.Error())
+	}
+	return err
+}
+
+func (stub *AzureClientStub) DescribeVM(ctx context.Context, vmID string) (*clients.InstanceDescription, error) {
+	for _, vm := range stub.createdVms {
+		if *vm.ID == vmID {
+			state := models.InstanceStateRunning
+			if stub.vmActions[vmID] == "stop" {
+				state = models.InstanceStateStopped
+			}
+			return &clients.InstanceDescription{
+				ID:    vmID,
+				IPv4:  "198.51.100.10",
+				DNS:   *vm.Name + ".eastus.cloudapp.azure.com",
+				State: state,
+			}, nil
+		}
+	}
+	return nil, ErrMissingInstanceID
+}
+
+func (stub *AzureClientStub) ImportSSHKey(ctx context.Context, resourceGroupName, location string, pk *models.Pubkey, name string) (string, error) {
+	id := fmt.Sprintf("/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/%s/providers/Microsoft.Compute/sshPublicKeys/%s", resourceGroupName, name)
+	stub.sshKeys = append(stub.sshKeys, id)
+	return id, nil
+}
+
+func (stub *AzureClientStub) GetSSHKey(ctx context.Context, keyID string) (string, error) {
+	for _, id := range stub.sshKeys {
+		if id == keyID {
+			return id, nil
+		}
+	}
+	return "", http.ErrPubkeyNotFound
+}
+
+func (stub *AzureClientStub) DeleteSSHKey(ctx context.Context, keyID string) error {
+	for i, id := range stub.sshKeys {
+		if id == keyID {
+			stub.sshKeys = append(stub.sshKeys[:i], stub.sshKeys[i+1:]...)
+			return nil
+		}
+	}
+	return http.ErrPubkeyNotFound
+}
@@ -17,6 +17,12 @@ const ec2CtxKey ec2CtxKeyType = iota
 
 type EC2ClientStub struct {
 	Imported []*types.KeyPairInfo
+
+	// InstanceActions holds the last lifecycle action performed on an instance
+	InstanceActions map[string]string
+
+	// DeletedInstances holds instances deleted outside of the application
+	DeletedInstances map[string]bool
 }
 
 func init() {
@@ -38,6 +44,27 @@ func AddStubbedEC2KeyPair(ctx context.Context, info *types.KeyPairInfo) error {
 	return nil
 }
 
+// StubLastInstanceActionEC2 returns the last lifecycle action performed on an instance or empty string
+func StubLastInstanceActionEC2(ctx context.Context, instanceID string) string {
+	si, err := getEC2StubFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+	return si.InstanceActions[instanceID]
+}
+
+// StubDeleteInstanceEC2 marks the instance as deleted outside of the application
+func StubDeleteInstanceEC2(ctx context.Context, instanceID string) {
+	si, err := getEC2StubFromContext(ctx)
+	if err != nil {
+		return
+	}
+	if si.DeletedInstances == nil {
+		si.DeletedInstances = make(map[string]bool)
+	}
+	si.DeletedInstances[instanceID] = true
+}
+
 func newEC2ServiceClientStubWithRegion(ctx context.Context, region string) (clients.EC2, error) {
 	return nil, nil
 }
@@ -88,7 +115,24 @@ func (mock *EC2ClientStub) GetPubkeyName(ctx context.Context, fingerprint string
 	return "", http.ErrPubkeyNotFound
 }
 
+func (mock *EC2ClientStub) GetPubkeyHandle(ctx context.Context, tag string) (string, error) {
+	for _, key := range mock.Imported {
+		for _, t := range key.Tags {
+			if *t.Key == "rh-kid" && *t.Value == tag {
+				return *key.KeyPairId, nil
+			}
+		}
+	}
+	return "", http.ErrPubkeyNotFound
+}
+
 func (mock *EC2ClientStub) DeleteSSHKey(ctx context.Context, handle string) error {
+	for idx, key := range mock.Imported {
+		if *key.KeyPairId == handle {
+			mock.Imported = append(mock.Imported[:idx], mock.Imported[idx+1:]...)
+			return nil
+		}
+	}
 	return nil
 }
 
@@ -155,6 +199,73 @@ func (mock *EC2ClientStub) ListLaunchTemplates(ctx context.Context) ([]*clients.
 	}, "", nil
 }
 
+func (mock *EC2ClientStub) ListVPCs(ctx context.Context) ([]*clients.Network, error) {
+	return []*clients.Network{
+		{
+			ID:   "vpc-0a4caa2cf5b097ce1",
+			Name: "production",
+			CIDR: "10.0.0.0/16",
+		},
+		{
+			ID:   "vpc-0b7e3d1f9c2a6b8d4",
+			Name: "staging",
+			CIDR: "10.1.0.0/16",
+		},
+	}, nil
+}
+
+func (mock *EC2ClientStub) ListSubnets(ctx context.Context, vpcID string) ([]*clients.Subnet, error) {
+	subnets := []*clients.Subnet{
+		{
+			ID:        "subnet-06e6b4eb3f8b6a0fb",
+			Name:      "production-a",
+			NetworkID: "vpc-0a4caa2cf5b097ce1",
+			CIDR:      "10.0.1.0/24",
+			Zone:      "us-east-1a",
+		},
+		{
+			ID:        "subnet-0c1d9e8f7a6b5c4d3",
+			Name:      "staging-a",
+			NetworkID: "vpc-0b7e3d1f9c2a6b8d4",
+			CIDR:      "10.1.1.0/24",
+			Zone:      "us-east-1a",
+		},
+	}
+
+	result := make([]*clients.Subnet, 0, len(subnets))
+	for _, subnet := range subnets {
+		if vpcID == "" || subnet.NetworkID == vpcID {
+			result = append(result, subnet)
+		}
+	}
+	return result, nil
+}
+
+func (mock *EC2ClientStub) ListSecurityGroups(ctx context.Context, vpcID string) ([]*clients.SecurityGroup, error) {
+	groups := []*clients.SecurityGroup{
+		{
+			ID:          "sg-0d2e7b9f3c1a8e4b5",
+			Name:        "ssh",
+			NetworkID:   "vpc-0a4caa2cf5b097ce1",
+			Description: "Allow SSH",
+		},
+		{
+			ID:          "sg-0e3f8c0a4d2b9f5c6",
+			Name:        "default",
+			NetworkID:   "vpc-0b7e3d1f9c2a6b8d4",
+			Description: "default VPC security group",
+		},
+	}
+
+	result := make([]*clients.SecurityGroup, 0, len(groups))
+	for _, group := range groups {
+		if vpcID == "" || group.NetworkID == vpcID {
+			result = append(result, group)
+		}
+	}
+	return result, nil
+}
+
 func (mock *EC2ClientStub) CheckPermission(ctx context.Context, auth *clients.Authentication) ([]string, error) {
 	return nil, nil
 }
@@ -168,14 +279,54 @@ func (mock *EC2ClientStub) GetAccountId(ctx context.Context) (string, error) {
 }
 
 func (mock *EC2ClientStub) DescribeInstanceDetails(ctx context.Context, InstanceIds []string) ([]*clients.InstanceDescription, error) {
+	for _, id := range InstanceIds {
+		if mock.DeletedInstances[id] {
+			return nil, fmt.Errorf("%w: instance %s", clients.ErrNotFound, id)
+		}
+	}
 	id := "i-0a4caa2cf5b097ce1"
 	dns := "ec2-51-83-81-17.compute-1.amazonaws.com"
 	ip := "54.11.88.17"
 	return []*clients.InstanceDescription{
 		{
-			ID:   id,
-			DNS:  dns,
-			IPv4: ip,
+			ID:    id,
+			DNS:   dns,
+			IPv4:  ip,
+			State: models.InstanceStateRunning,
 		},
 	}, nil
 }
+
+func (mock *EC2ClientStub) recordInstanceAction(instanceIds []string, action string) {
+	if mock.InstanceActions == nil {
+		mock.InstanceActions = make(map[string]string)
+	}
+	for _, id := range instanceIds {
+		mock.InstanceActions[id] = action
+	}
+}
+
+func (mock *EC2ClientStub) StartInstances(ctx context.Context, instanceIds []string) error {
+	mock.recordInstanceAction(instanceIds, "start")
+	return nil
+}
+
+func (mock *EC2ClientStub) StopInstances(ctx context.Context, instanceIds []string) error {
+	mock.recordInstanceAction(instanceIds, "stop")
+	return nil
+}
+
+func (mock *EC2ClientStub) RebootInstances(ctx context.Context, instanceIds []string) error {
+	mock.recordInstanceAction(instanceIds, "reboot")
+	return nil
+}
+
+func (mock *EC2ClientStub) TerminateInstances(ctx context.Context, instanceIds []string) error {
+	for _, id := range instanceIds {
+		if mock.DeletedInstances[id] {
+			return fmt.Errorf("%w: instance %s", clients.ErrNotFound, id)
+		}
+	}
+	mock.recordInstanceAction(instanceIds, "terminate")
+	return nil
+}
@@ -2,10 +2,13 @@ package stubs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 )
 
@@ -21,6 +24,12 @@ var (
 type (
 	GCPClientStub struct {
 		Instances []*string
+
+		// InstanceActions holds the last lifecycle action performed on an instance
+		InstanceActions map[string]string
+
+		// ProjectSSHKeys holds keys present in project ssh-keys metadata
+		ProjectSSHKeys []string
 	}
 	GCPServiceClientStub struct{}
 )
@@ -72,6 +81,15 @@ func CountStubInstancesGCP(ctx context.Context) int {
 	return len(client.Instances)
 }
 
+// StubLastInstanceActionGCP returns the last lifecycle action performed on an instance or empty string
+func StubLastInstanceActionGCP(ctx context.Context, id string) string {
+	client, err := getCustomerGCPClientStub(ctx, &clients.Authentication{})
+	if err != nil {
+		return ""
+	}
+	return client.InstanceActions[id]
+}
+
 func (mock *GCPClientStub) ListAllRegions(ctx context.Context) ([]clients.Region, error) {
 	return nil, nil
 }
@@ -83,7 +101,7 @@ func (mock *GCPClientStub) Status(ctx context.Context) error {
 func (mock *GCPClientStub) GetInstanceDescriptionByID(ctx context.Context, id, zone string) (*clients.InstanceDescription, error) {
 	for _, instanceID := range mock.Instances {
 		if ptr.From(instanceID) == id {
-			instanceDesc := &clients.InstanceDescription{ID: id, IPv4: fmt.Sprintf("10.0.0.%v", ipCounter)}
+			instanceDesc := &clients.InstanceDescription{ID: id, IPv4: fmt.Sprintf("10.0.0.%v", ipCounter), State: models.InstanceStateRunning}
 			ipCounter = ipCounter + 1
 			return instanceDesc, nil
 		}
@@ -95,6 +113,45 @@ func (mock *GCPClientStub) ListLaunchTemplates(ctx context.Context) ([]*clients.
 	return nil, "", nil
 }
 
+func (mock *GCPClientStub) ListNetworks(ctx context.Context) ([]*clients.Network, error) {
+	return []*clients.Network{
+		{
+			ID:      "global/networks/default",
+			Name:    "default",
+			Default: true,
+		},
+		{
+			ID:   "global/networks/shared-vpc",
+			Name: "shared-vpc",
+		},
+	}, nil
+}
+
+func (mock *GCPClientStub) ListSubnetworks(ctx context.Context, region, network string) ([]*clients.Subnet, error) {
+	subnets := []*clients.Subnet{
+		{
+			ID:        "regions/us-central1/subnetworks/default",
+			Name:      "default",
+			NetworkID: "global/networks/default",
+			CIDR:      "10.128.0.0/20",
+		},
+		{
+			ID:        "regions/us-east4/subnetworks/workloads",
+			Name:      "workloads",
+			NetworkID: "global/networks/shared-vpc",
+			CIDR:      "10.20.0.0/24",
+		},
+	}
+
+	result := make([]*clients.Subnet, 0, len(subnets))
+	for _, subnet := range subnets {
+		if (region == "" || strings.HasPrefix(subnet.ID, "regions/"+region+"/")) && (network == "" || subnet.NetworkID == network) {
+			result = append(result, subnet)
+		}
+	}
+	return result, nil
+}
+
 func (mock *GCPClientStub) InsertInstances(ctx context.Context, params *clients.GCPInstanceParams, amount int64) ([]*string, *string, error) {
 	for i := 0; i < int(amount); i++ {
 		ID := fmt.Sprintf("300394200587658274%s", strconv.Itoa(len(mock.Instances)+1))
@@ -108,6 +165,68 @@ func (mock *GCPClientStub) ListInstancesIDsByLabel(ctx context.Context, _ string
 	return mock.Instances, nil
 }
 
+func (mock *GCPClientStub) recordInstanceAction(id, action string) error {
+	for _, instanceID := range mock.Instances {
+		if ptr.From(instanceID) == id {
+			if mock.InstanceActions == nil {
+				mock.InstanceActions = make(map[string]string)
+			}
+			mock.InstanceActions[id] = action
+			return nil
+		}
+	}
+	return ErrMissingInstanceID
+}
+
+func (mock *GCPClientStub) StartInstance(ctx context.Context, id, zone string) error {
+	return mock.recordInstanceAction(id, "start")
+}
+
+func (mock *GCPClientStub) StopInstance(ctx context.Context, id, zone string) error {
+	return mock.recordInstanceAction(id, "stop")
+}
+
+func (mock *GCPClientStub) ResetInstance(ctx context.Context, id, zone string) error {
+	return mock.recordInstanceAction(id, "reboot")
+}
+
+func (mock *GCPClientStub) DeleteInstance(ctx context.Context, id, zone string) error {
+	err := mock.recordInstanceAction(id, "terminate")
+	if errors.Is(err, ErrMissingInstanceID) {
+		return fmt.Errorf("%w: %s", clients.ErrNotFound, err.Error())
+	}
+	return err
+}
+
+func (mock *GCPClientStub) AddProjectSSHKey(ctx context.Context, key string) error {
+	for _, k := range mock.ProjectSSHKeys {
+		if k == key {
+			return nil
+		}
+	}
+	mock.ProjectSSHKeys = append(mock.ProjectSSHKeys, key)
+	return nil
+}
+
+func (mock *GCPClientStub) HasProjectSSHKey(ctx context.Context, key string) (bool, error) {
+	for _, k := range mock.ProjectSSHKeys {
+		if k == key {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (mock *GCPClientStub) RemoveProjectSSHKey(ctx context.Context, key string) error {
+	for i, k := range mock.ProjectSSHKeys {
+		if k == key {
+			mock.ProjectSSHKeys = append(mock.ProjectSSHKeys[:i], mock.ProjectSSHKeys[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func (mock *GCPServiceClientStub) ListMachineTypes(ctx context.Context, zone string) ([]*clients.InstanceType, error) {
 	return nil, nil
 }
@@ -25,9 +25,10 @@ var config struct {
 			Enabled bool `env:"ENABLED" env-default:"false" env-description:"notifications enabled"`
 		} `env-prefix:"NOTIFICATIONS_"`
 		Cache struct {
-			Type       string        `env:"TYPE" env-default:"none" env-description:"application cache (none, redis)"`
-			Expiration time.Duration `env:"EXPIRATION" env-default:"10m" env-description:"expiration for Redis application cache (time interval syntax)"`
-			Redis      struct {
+			Type               string        `env:"TYPE" env-default:"none" env-description:"application cache (none, redis)"`
+			Expiration         time.Duration `env:"EXPIRATION" env-default:"10m" env-description:"expiration for Redis application cache (time interval syntax)"`
+			InstanceExpiration time.Duration `env:"INSTANCE_EXPIRATION" env-default:"1m" env-description:"expiration for refreshed instance state and addresses (time interval syntax)"`
+			Redis              struct {
 				Host     string `env:"HOST" env-default:"localhost" env-description:"redis hostname"`
 				Port     int    `env:"PORT" env-default:"6379" env-description:"redis port"`
 				User     string `env:"USER" env-default:"" env-description:"redis username"`
@@ -47,7 +48,17 @@ var config struct {
 		CleanupEnabled  bool          `env:"CLEANUP_ENABLED" env-default:"false" env-description:"reservation cleanup enabled"`
 		Lifetime        time.Duration `env:"LIFETIME" env-default:"8760h" env-description:"how old reservation should be deleted, default equal to 365 days"`
 		CleanupInterval time.Duration `env:"CLEANUP_INTERVAL" env-default:"1h" env-description:"how often to cleanup the reservation"`
+		ExpiryEnabled   bool          `env:"EXPIRY_ENABLED" env-default:"true" env-description:"termination of instances of expired reservations enabled"`
+		ExpiryInterval  time.Duration `env:"EXPIRY_INTERVAL" env-default:"5m" env-description:"how often to look for expired reservations"`
+		MaxTTL          time.Duration `env:"MAX_TTL" env-default:"8760h" env-description:"maximum reservation time-to-live, default equal to 365 days"`
+		StuckEnabled    bool          `env:"STUCK_ENABLED" env-default:"true" env-description:"finishing of stuck reservations (lost jobs) enabled"`
+		StuckInterval   time.Duration `env:"STUCK_INTERVAL" env-default:"5m" env-description:"how often to look for stuck reservations"`
+		StuckMargin     time.Duration `env:"STUCK_MARGIN" env-default:"30m" env-description:"time added to the maximum job duration (worker timeout and retries) before reservation is considered stuck"`
 	} `env-prefix:"RESERVATION_"`
+	Pubkey struct {
+		ReconcileEnabled  bool          `env:"RECONCILE_ENABLED" env-default:"false" env-description:"verification of uploaded pubkeys in clouds enabled"`
+		ReconcileInterval time.Duration `env:"RECONCILE_INTERVAL" env-default:"1h" env-description:"how often to verify uploaded pubkeys in clouds"`
+	} `env-prefix:"PUBKEY_"`
 	Database struct {
 		Host        string        `env:"HOST" env-default:"localhost" env-description:"main database hostname"`
 		Port        uint16        `env:"PORT" env-default:"5432" env-description:"main database port"`
@@ -105,6 +116,7 @@ var config struct {
 		SubscriptionID    string        `env:"SUBSCRIPTION_ID" env-default:"" env-description:"Azure service account subscription id"`
 		AvailabilityDelay time.Duration `env:"AVAILABILITY_DELAY" env-default:"1s" env-description:"arbitrary delay between sources availability checks (time interval syntax)"`
 		AvailabilityRate  float32       `env:"AVAILABILITY_RATE" env-default:"1.0" env-description:"probability rate for availability checks (0.0 = all skipped, 1.0 = nothing skipped)"`
+		LoginUsername     string        `env:"LOGIN_USERNAME" env-default:"azureuser" env-description:"admin username of virtual machines"`
 	} `env-prefix:"AZURE_"`
 	GCP struct {
 		ProjectID         string        `env:"PROJECT_ID" env-default:"" env-description:"GCP service account project id"`
@@ -112,6 +124,8 @@ var config struct {
 		DefaultZone       string        `env:"DEFAULT_ZONE" env-default:"us-east4" env-description:"GCP region when not provided"`
 		AvailabilityDelay time.Duration `env:"AVAILABILITY_DELAY" env-default:"1s" env-description:"arbitrary delay between sources availability checks (time interval syntax)"`
 		AvailabilityRate  float32       `env:"AVAILABILITY_RATE" env-default:"1.0" env-description:"probability rate for availability checks (0.0 = all skipped, 1.0 = nothing skipped)"`
+		LoginUsername     string        `env:"LOGIN_USERNAME" env-default:"gcp-user" env-description:"username of the SSH key uploaded to instances"`
+		ProjectKeys       bool          `env:"PROJECT_KEYS" env-default:"false" env-description:"upload SSH keys into project metadata instead of instance metadata"`
 	} `env-prefix:"GCP_"`
 	Prometheus struct {
 		Port int    `env:"PORT" env-default:"9000" env-description:"prometheus HTTP port"`
@@ -139,10 +153,12 @@ var config struct {
 		TraceData bool `env:"TRACE_DATA" env-default:"true" env-description:"open telemetry HTTP context pass and trace"`
 	} `env-prefix:"REST_ENDPOINTS_"`
 	Worker struct {
-		Queue        string        `env:"QUEUE" env-default:"memory" env-description:"job worker implementation (memory, redis, sqs, postgres)"`
+		Queue        string        `env:"QUEUE" env-default:"memory" env-description:"job worker implementation (memory, redis, postgres)"`
 		PollInterval time.Duration `env:"POLL_INTERVAL" env-default:"5s" env-description:"polling interval (network timeout)"`
 		Concurrency  int           `env:"CONCURRENCY" env-default:"33" env-description:"amount of worker polling goroutines (effective concurrency)"`
 		Timeout      time.Duration `env:"TIMEOUT" env-default:"30m" env-description:"total timeout for a single job to complete (duration)"`
+		MaxAttempts  int           `env:"MAX_ATTEMPTS" env-default:"3" env-description:"total attempts of a failed or lost job before it is moved into the dead-letter list (redis and postgres)"`
+		RetryBackoff time.Duration `env:"RETRY_BACKOFF" env-default:"1m" env-description:"delay before the first retry of a failed job, doubles with every attempt (redis and postgres)"`
 	} `env-prefix:"WORKER_"`
 	Unleash struct {
 		Enabled     bool   `env:"ENABLED" env-default:"false" env-description:"unleash service (feature flags)"`
@@ -174,6 +190,7 @@ var (
 	Application   = &config.App
 	Stats         = &config.Stats
 	Reservation   = &config.Reservation
+	Pubkey        = &config.Pubkey
 	Database      = &config.Database
 	Prometheus    = &config.Prometheus
 	Logging       = &config.Logging
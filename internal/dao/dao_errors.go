@@ -36,6 +36,12 @@ var (
 	// ErrStubContextAlreadySet is returned when stub object was already added to the context
 	ErrStubContextAlreadySet = errors.New("context object already set")
 
+	// ErrUnsupportedProvider is returned for reservations of providers which do not launch instances
+	ErrUnsupportedProvider = errors.New("provider does not launch instances")
+
 	// ErrReservationRateExceeded is returned when SQL constraint does not allow to insert more reservations
 	ErrReservationRateExceeded = usrerr.New(429, "rate limit exceeded", "too many reservations, wait and retry")
+
+	// ErrDuplicateIdempotencyKey is returned when a concurrent request with the same idempotency key created a reservation
+	ErrDuplicateIdempotencyKey = usrerr.New(409, "duplicate idempotency key", "reservation with the same idempotency key is being created, retry")
 )
@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// ReservationStatus is a reservation result used for filtering.
+type ReservationStatus string
+
+const (
+	ReservationStatusAny     ReservationStatus = ""
+	ReservationStatusPending ReservationStatus = "pending"
+	ReservationStatusSuccess ReservationStatus = "success"
+	ReservationStatusFailure ReservationStatus = "failure"
+)
+
+// ReservationSortBy is a reservation attribute used for sorting.
+type ReservationSortBy string
+
+const (
+	ReservationSortByID         ReservationSortBy = "id"
+	ReservationSortByCreatedAt  ReservationSortBy = "created_at"
+	ReservationSortByFinishedAt ReservationSortBy = "finished_at"
+)
+
+// ReservationFilter limits and orders reservations returned by ReservationDao. Zero values of all
+// fields do not filter, reservations are ordered by ID by default.
+type ReservationFilter struct {
+	// Provider type or ProviderTypeUnknown for all providers.
+	Provider models.ProviderType
+
+	// Reservation result.
+	Status ReservationStatus
+
+	// Source ID, reservations without source (e.g. noop) are never matched.
+	SourceID string
+
+	// Instance ID, only reservations which launched the instance are matched.
+	InstanceID string
+
+	// Reservations created at or after the time.
+	CreatedAfter time.Time
+
+	// Reservations created before the time.
+	CreatedBefore time.Time
+
+	// Sort attribute and direction.
+	SortBy   ReservationSortBy
+	SortDesc bool
+
+	// Keyset pagination: reservations after the ID (before the ID in descending order), only
+	// sorting by ID is supported. Zero value does not filter.
+	AfterID int64
+}
+
+// PubkeyFilter limits pubkeys returned by PubkeyDao, pubkeys are always ordered by ID.
+type PubkeyFilter struct {
+	// Keyset pagination: pubkeys after the ID. Zero value does not filter.
+	AfterID int64
+}
@@ -12,6 +12,7 @@ package dao
 
 import (
 	"context"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -37,13 +38,21 @@ type PubkeyDao interface {
 	Create(ctx context.Context, pk *models.Pubkey) error
 	Update(ctx context.Context, pk *models.Pubkey) error
 	GetById(ctx context.Context, id int64) (*models.Pubkey, error)
-	List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error)
+	List(ctx context.Context, filter *PubkeyFilter, limit, offset int64) ([]*models.Pubkey, error)
 	Count(ctx context.Context) (int, error)
 	Delete(ctx context.Context, id int64) error
 
+	// UnscopedList returns pubkeys of all accounts with ID greater than afterId ordered by ID. UNSCOPED.
+	UnscopedList(ctx context.Context, afterId, limit int64) ([]*models.Pubkey, error)
+
 	UnscopedCreateResource(ctx context.Context, pkr *models.PubkeyResource) error
 	UnscopedGetResourceBySourceAndRegion(ctx context.Context, pubkeyId int64, sourceId string, region string) (*models.PubkeyResource, error)
 	UnscopedListResourcesByPubkeyId(ctx context.Context, pkId int64) ([]*models.PubkeyResource, error)
+	UnscopedUpdateResource(ctx context.Context, pkr *models.PubkeyResource) error
+
+	// UnscopedVerifyResource records the resource was found in the cloud just now. UNSCOPED.
+	UnscopedVerifyResource(ctx context.Context, id int64) error
+
 	UnscopedDeleteResource(ctx context.Context, id int64) error
 }
 
@@ -56,14 +65,21 @@ type ReservationDao interface {
 	// CreateNoop creates no operation reservation with details in a single transaction.
 	CreateNoop(ctx context.Context, reservation *models.NoopReservation) error
 
-	// CreateAWS creates AWS reservation with details in a single transaction.
-	CreateAWS(ctx context.Context, reservation *models.AWSReservation) error
+	// CreateInstanceAction creates reservation tracking an instance lifecycle action. Provider and
+	// LaunchReservationID must be set, the reservation is not subject to the reservation rate limit.
+	CreateInstanceAction(ctx context.Context, reservation *models.InstanceActionReservation) error
 
-	// CreateAzure creates Azure reservation with details in a single transaction.
-	CreateAzure(ctx context.Context, reservation *models.AzureReservation) error
+	// CreateAWS creates AWS reservation with details in a single transaction. Optional hooks are
+	// called within the transaction after the reservation is created, e.g. to enqueue a job.
+	CreateAWS(ctx context.Context, reservation *models.AWSReservation, hooks ...TxFn) error
 
-	// CreateGCP creates GCP reservation with details in a single transaction.
-	CreateGCP(ctx context.Context, reservation *models.GCPReservation) error
+	// CreateAzure creates Azure reservation with details in a single transaction. Optional hooks are
+	// called within the transaction after the reservation is created, e.g. to enqueue a job.
+	CreateAzure(ctx context.Context, reservation *models.AzureReservation, hooks ...TxFn) error
+
+	// CreateGCP creates GCP reservation with details in a single transaction. Optional hooks are
+	// called within the transaction after the reservation is created, e.g. to enqueue a job.
+	CreateGCP(ctx context.Context, reservation *models.GCPReservation, hooks ...TxFn) error
 
 	// CreateInstance inserts instance associated to a reservation.
 	CreateInstance(ctx context.Context, reservation *models.ReservationInstance) error
@@ -71,6 +87,9 @@ type ReservationDao interface {
 	// GetById returns reservation for a particular account.
 	GetById(ctx context.Context, id int64) (*models.Reservation, error)
 
+	// GetByIdempotencyKey returns reservation created with the idempotency key for a particular account.
+	GetByIdempotencyKey(ctx context.Context, key string) (*models.Reservation, error)
+
 	// GetAWSById returns reservation for a particular account.
 	GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error)
 
@@ -80,16 +99,29 @@ type ReservationDao interface {
 	// GetGCPById returns reservation for a particular account.
 	GetGCPById(ctx context.Context, id int64) (*models.GCPReservation, error)
 
-	// Count returns total reservations for a particular account.
-	Count(ctx context.Context) (int, error)
+	// Count returns total reservations for a particular account matching the filter, nil filter
+	// matches all reservations.
+	Count(ctx context.Context, filter *ReservationFilter) (int, error)
 
-	// List returns reservation for a particular account.
-	List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error)
+	// List returns reservation for a particular account matching the filter in the filter order,
+	// nil filter matches all reservations.
+	List(ctx context.Context, filter *ReservationFilter, limit, offset int64) ([]*models.Reservation, error)
 
 	// ListInstances returns instances associated to a reservation. UNSCOPED.
 	// It currently lists all instances and not instances for a reservation, this is a TODO.
 	ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error)
 
+	// UnscopedListExpired returns finished reservations which expired and their instances were not
+	// terminated yet, the oldest expiry first. UNSCOPED.
+	UnscopedListExpired(ctx context.Context, limit int64) ([]*models.Reservation, error)
+
+	// UnscopedMarkExpired records that instances of an expired reservation were terminated. UNSCOPED.
+	UnscopedMarkExpired(ctx context.Context, id int64) error
+
+	// UnscopedListStuck returns reservations which are not finished and were created before the given
+	// duration, oldest first. UNSCOPED.
+	UnscopedListStuck(ctx context.Context, olderThan time.Duration, limit int64) ([]*models.Reservation, error)
+
 	// UpdateStatus sets status field and increment step counter by addSteps. UNSCOPED.
 	UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error
 
@@ -105,10 +137,20 @@ type ReservationDao interface {
 	// UpdateReservationInstance updates an instance with its description
 	UpdateReservationInstance(ctx context.Context, reservationID int64, instance *clients.InstanceDescription) error
 
-	// FinishWithSuccess sets Success flag. UNSCOPED.
+	// UnscopedDeleteInstance removes a terminated instance from the reservation which launched it. UNSCOPED.
+	UnscopedDeleteInstance(ctx context.Context, reservationID int64, instanceID string) error
+
+	// Cancel marks an unfinished reservation as cancelled. Jobs observe cancellation between steps
+	// and roll back created instances. Returns ErrAffectedMismatch when the reservation is already
+	// finished or cancelled.
+	Cancel(ctx context.Context, id int64) error
+
+	// FinishWithSuccess sets Success flag. Cancelled or finished reservations are not updated and
+	// ErrAffectedMismatch is returned instead. UNSCOPED.
 	FinishWithSuccess(ctx context.Context, id int64) error
 
-	// FinishWithError sets Success flag and Error flag. UNSCOPED.
+	// FinishWithError sets Success flag and Error flag. Finished reservations are not updated and
+	// ErrAffectedMismatch is returned instead. UNSCOPED.
 	FinishWithError(ctx context.Context, id int64, errorString string) error
 
 	// Delete deletes a reservation. Only used in tests and background cleanup job. UNSCOPED.
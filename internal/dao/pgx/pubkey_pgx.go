@@ -93,12 +93,33 @@ func (x *pubkeyDao) Update(ctx context.Context, pubkey *models.Pubkey) error {
 	return nil
 }
 
-func (x *pubkeyDao) List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error) {
-	query := `SELECT * FROM pubkeys WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+func (x *pubkeyDao) List(ctx context.Context, filter *dao.PubkeyFilter, limit, offset int64) ([]*models.Pubkey, error) {
+	query := `SELECT * FROM pubkeys WHERE account_id = $1 AND id > $2 ORDER BY id LIMIT $3 OFFSET $4`
 	accountId := identity.AccountId(ctx)
 	var result []*models.Pubkey
 
-	rows, err := db.Pool.Query(ctx, query, accountId, limit, offset)
+	var afterId int64
+	if filter != nil {
+		afterId = filter.AfterID
+	}
+
+	rows, err := db.Pool.Query(ctx, query, accountId, afterId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *pubkeyDao) UnscopedList(ctx context.Context, afterId, limit int64) ([]*models.Pubkey, error) {
+	query := `SELECT * FROM pubkeys WHERE id > $1 ORDER BY id LIMIT $2`
+	var result []*models.Pubkey
+
+	rows, err := db.Pool.Query(ctx, query, afterId, limit)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -183,6 +204,32 @@ func (x *pubkeyDao) UnscopedListResourcesByPubkeyId(ctx context.Context, id int6
 	return result, nil
 }
 
+func (x *pubkeyDao) UnscopedUpdateResource(ctx context.Context, pkr *models.PubkeyResource) error {
+	query := `UPDATE pubkey_resources SET handle = $2, tag = $3 WHERE id = $1`
+
+	tag, err := db.Pool.Exec(ctx, query, pkr.ID, pkr.Handle, pkr.Tag)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *pubkeyDao) UnscopedVerifyResource(ctx context.Context, id int64) error {
+	query := `UPDATE pubkey_resources SET verified_at = now() WHERE id = $1`
+
+	tag, err := db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
 func (x *pubkeyDao) UnscopedDeleteResource(ctx context.Context, id int64) error {
 	query := `DELETE FROM pubkey_resources WHERE id = $1`
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/config"
@@ -42,7 +43,22 @@ func (x *reservationDao) CreateNoop(ctx context.Context, reservation *models.Noo
 	return nil
 }
 
-func (x *reservationDao) CreateAWS(ctx context.Context, reservation *models.AWSReservation) error {
+func (x *reservationDao) CreateInstanceAction(ctx context.Context, reservation *models.InstanceActionReservation) error {
+	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+		if err := x.createGenericReservation(ctx, tx, &reservation.Reservation); err != nil {
+			return fmt.Errorf("failed to create reservation record: %w", err)
+		}
+
+		return nil
+	})
+
+	if txErr != nil {
+		return fmt.Errorf("pgx tx error: %w", txErr)
+	}
+	return nil
+}
+
+func (x *reservationDao) CreateAWS(ctx context.Context, reservation *models.AWSReservation, hooks ...dao.TxFn) error {
 	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
 		reservation.Provider = models.ProviderTypeAWS
 		if err := x.createGenericReservation(ctx, tx, &reservation.Reservation); err != nil {
@@ -64,7 +80,7 @@ func (x *reservationDao) CreateAWS(ctx context.Context, reservation *models.AWSR
 			return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
 		}
 
-		return nil
+		return runHooks(tx, hooks)
 	})
 
 	if txErr != nil {
@@ -73,7 +89,7 @@ func (x *reservationDao) CreateAWS(ctx context.Context, reservation *models.AWSR
 	return nil
 }
 
-func (x *reservationDao) CreateAzure(ctx context.Context, reservation *models.AzureReservation) error {
+func (x *reservationDao) CreateAzure(ctx context.Context, reservation *models.AzureReservation, hooks ...dao.TxFn) error {
 	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
 		reservation.Provider = models.ProviderTypeAzure
 		if err := x.createGenericReservation(ctx, tx, &reservation.Reservation); err != nil {
@@ -95,7 +111,7 @@ func (x *reservationDao) CreateAzure(ctx context.Context, reservation *models.Az
 			return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
 		}
 
-		return nil
+		return runHooks(tx, hooks)
 	})
 
 	if txErr != nil {
@@ -104,7 +120,7 @@ func (x *reservationDao) CreateAzure(ctx context.Context, reservation *models.Az
 	return nil
 }
 
-func (x *reservationDao) CreateGCP(ctx context.Context, reservation *models.GCPReservation) error {
+func (x *reservationDao) CreateGCP(ctx context.Context, reservation *models.GCPReservation, hooks ...dao.TxFn) error {
 	txErr := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
 		reservation.Provider = models.ProviderTypeGCP
 		if err := x.createGenericReservation(ctx, tx, &reservation.Reservation); err != nil {
@@ -126,7 +142,7 @@ func (x *reservationDao) CreateGCP(ctx context.Context, reservation *models.GCPR
 			return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
 		}
 
-		return nil
+		return runHooks(tx, hooks)
 	})
 
 	if txErr != nil {
@@ -139,24 +155,40 @@ func (x *reservationDao) createGenericReservation(ctx context.Context, tx pgx.Tx
 	reservation.AccountID = identity.AccountId(ctx)
 	reservation.Status = "Created"
 
-	reservationQuery := `INSERT INTO reservations (provider, account_id, steps, step_titles, status)
-		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`
+	reservationQuery := `INSERT INTO reservations (provider, account_id, steps, step_titles, status, expires_at, idempotency_key, launch_reservation_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at`
 	err := tx.QueryRow(ctx, reservationQuery,
 		reservation.Provider,
 		reservation.AccountID,
 		reservation.Steps,
 		reservation.StepTitles,
-		reservation.Status).Scan(&reservation.ID, &reservation.CreatedAt)
+		reservation.Status,
+		reservation.ExpiresAt,
+		reservation.IdempotencyKey,
+		reservation.LaunchReservationID).Scan(&reservation.ID, &reservation.CreatedAt)
 	if err != nil {
 		if strings.Contains(err.Error(), "too many pending reservations") {
 			return fmt.Errorf("%w: %s", dao.ErrReservationRateExceeded, err.Error())
 		}
+		if strings.Contains(err.Error(), "reservations_account_id_idempotency_key_idx") {
+			return fmt.Errorf("%w: %s", dao.ErrDuplicateIdempotencyKey, err.Error())
+		}
 		return fmt.Errorf("failed to create reservation record: %w", err)
 	}
 
 	return nil
 }
 
+// runHooks calls transaction hooks in order and stops on the first error.
+func runHooks(tx pgx.Tx, hooks []dao.TxFn) error {
+	for _, hook := range hooks {
+		if err := hook(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (x *reservationDao) CreateInstance(ctx context.Context, instance *models.ReservationInstance) error {
 	query := `INSERT INTO reservation_instances (reservation_id, instance_id, detail) VALUES ($1, $2, $3)`
 
@@ -175,12 +207,16 @@ func (x *reservationDao) CreateInstance(ctx context.Context, instance *models.Re
 }
 
 func (x *reservationDao) UpdateReservationInstance(ctx context.Context, reservationID int64, instance *clients.InstanceDescription) error {
-	query := `UPDATE reservation_instances SET detail = $3 WHERE reservation_id = $1 AND instance_id = $2`
+	// zone is recorded when the instance is created and it is not part of the description
+	query := `UPDATE reservation_instances
+		SET detail = jsonb_strip_nulls(jsonb_build_object('zone', detail->'zone')) || $3::jsonb
+		WHERE reservation_id = $1 AND instance_id = $2`
 	detail := &models.ReservationInstanceDetail{
 		PublicIPv4:  instance.IPv4,
 		PublicDNS:   instance.DNS,
 		PrivateIPv4: instance.PrivateIPv4,
 		PrivateIPv6: instance.PrivateIPv6,
+		State:       instance.State,
 	}
 	tag, err := db.Pool.Exec(ctx, query, reservationID, instance.ID, detail)
 	if err != nil {
@@ -193,6 +229,20 @@ func (x *reservationDao) UpdateReservationInstance(ctx context.Context, reservat
 	return nil
 }
 
+func (x *reservationDao) UnscopedDeleteInstance(ctx context.Context, reservationID int64, instanceID string) error {
+	query := `DELETE FROM reservation_instances WHERE reservation_id = $1 AND instance_id = $2`
+
+	tag, err := db.Pool.Exec(ctx, query, reservationID, instanceID)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+
+	return nil
+}
+
 func (x *reservationDao) GetById(ctx context.Context, id int64) (*models.Reservation, error) {
 	query := `SELECT * FROM reservations WHERE account_id = $1 AND id = $2 LIMIT 1`
 	accountId := identity.AccountId(ctx)
@@ -205,6 +255,18 @@ func (x *reservationDao) GetById(ctx context.Context, id int64) (*models.Reserva
 	return result, nil
 }
 
+func (x *reservationDao) GetByIdempotencyKey(ctx context.Context, key string) (*models.Reservation, error) {
+	query := `SELECT * FROM reservations WHERE account_id = $1 AND idempotency_key = $2`
+	accountId := identity.AccountId(ctx)
+	result := &models.Reservation{}
+
+	err := pgxscan.Get(ctx, db.Pool, result, query, accountId, key)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
 func (x *reservationDao) GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error) {
 	query := `SELECT id, provider, account_id, created_at, steps, step, status, error, finished_at, success,
     	pubkey_id, source_id, image_id, aws_reservation_id, detail
@@ -250,12 +312,12 @@ func (x *reservationDao) GetGCPById(ctx context.Context, id int64) (*models.GCPR
 	return result, nil
 }
 
-func (x *reservationDao) Count(ctx context.Context) (int, error) {
-	query := `SELECT COUNT(*) FROM reservations WHERE account_id = $1`
-	accountId := identity.AccountId(ctx)
+func (x *reservationDao) Count(ctx context.Context, filter *dao.ReservationFilter) (int, error) {
+	where, args := reservationFilterWhere(identity.AccountId(ctx), filter)
+	query := `SELECT COUNT(*) FROM reservations WHERE ` + where
 
 	var result int
-	err := db.Pool.QueryRow(ctx, query, accountId).Scan(&result)
+	err := db.Pool.QueryRow(ctx, query, args...).Scan(&result)
 	if err != nil {
 		return 0, fmt.Errorf("pgx error: %w", err)
 	}
@@ -263,13 +325,15 @@ func (x *reservationDao) Count(ctx context.Context) (int, error) {
 	return result, nil
 }
 
-func (x *reservationDao) List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
-	query := `SELECT * FROM reservations WHERE account_id = $1 ORDER BY id LIMIT $2 OFFSET $3`
+func (x *reservationDao) List(ctx context.Context, filter *dao.ReservationFilter, limit, offset int64) ([]*models.Reservation, error) {
+	where, args := reservationFilterWhere(identity.AccountId(ctx), filter)
+	query := fmt.Sprintf(`SELECT * FROM reservations WHERE %s ORDER BY %s LIMIT $%d OFFSET $%d`,
+		where, reservationFilterOrder(filter), len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
 
-	accountId := identity.AccountId(ctx)
 	var result []*models.Reservation
 
-	rows, err := db.Pool.Query(ctx, query, accountId, limit, offset)
+	rows, err := db.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("pgx error: %w", err)
 	}
@@ -281,6 +345,88 @@ func (x *reservationDao) List(ctx context.Context, limit, offset int64) ([]*mode
 	return result, nil
 }
 
+// reservationFilterWhere returns WHERE condition and its arguments for the account and the filter.
+func reservationFilterWhere(accountId int64, filter *dao.ReservationFilter) (string, []any) {
+	conditions := []string{"account_id = $1"}
+	args := []any{accountId}
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter == nil {
+		return conditions[0], args
+	}
+
+	if filter.Provider != models.ProviderTypeUnknown {
+		// instance action reservations have no provider details
+		add("provider = $%d", filter.Provider)
+		conditions = append(conditions, "launch_reservation_id IS NULL")
+	}
+
+	//nolint:exhaustive
+	switch filter.Status {
+	case dao.ReservationStatusPending:
+		conditions = append(conditions, "success IS NULL")
+	case dao.ReservationStatusSuccess:
+		conditions = append(conditions, "success = true")
+	case dao.ReservationStatusFailure:
+		conditions = append(conditions, "success = false")
+	}
+
+	if filter.SourceID != "" {
+		add(`id IN (SELECT reservation_id FROM aws_reservation_details WHERE source_id = $%[1]d
+			UNION ALL SELECT reservation_id FROM azure_reservation_details WHERE source_id = $%[1]d
+			UNION ALL SELECT reservation_id FROM gcp_reservation_details WHERE source_id = $%[1]d)`, filter.SourceID)
+	}
+
+	if filter.InstanceID != "" {
+		add("id IN (SELECT reservation_id FROM reservation_instances WHERE instance_id = $%d)", filter.InstanceID)
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		add("created_at >= $%d", filter.CreatedAfter)
+	}
+
+	if !filter.CreatedBefore.IsZero() {
+		add("created_at < $%d", filter.CreatedBefore)
+	}
+
+	if filter.AfterID != 0 {
+		if filter.SortDesc {
+			add("id < $%d", filter.AfterID)
+		} else {
+			add("id > $%d", filter.AfterID)
+		}
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// reservationFilterOrder returns ORDER BY expression for the filter, columns are never taken from
+// user input directly.
+func reservationFilterOrder(filter *dao.ReservationFilter) string {
+	if filter == nil {
+		return "id"
+	}
+
+	direction := ""
+	if filter.SortDesc {
+		direction = " DESC"
+	}
+
+	// ID makes the order stable, NULL values (unfinished reservations) are last in both directions
+	//nolint:exhaustive
+	switch filter.SortBy {
+	case dao.ReservationSortByCreatedAt:
+		return "created_at" + direction + ", id" + direction
+	case dao.ReservationSortByFinishedAt:
+		return "finished_at" + direction + " NULLS LAST, id" + direction
+	default:
+		return "id" + direction
+	}
+}
+
 func (x *reservationDao) ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
 	query := `SELECT reservation_id, instance_id, detail FROM reservation_instances, reservations
          WHERE reservation_id = reservations.id AND account_id = $1 AND reservation_id = $2`
@@ -300,6 +446,57 @@ func (x *reservationDao) ListInstances(ctx context.Context, reservationId int64)
 	return result, nil
 }
 
+func (x *reservationDao) UnscopedListExpired(ctx context.Context, limit int64) ([]*models.Reservation, error) {
+	query := `SELECT * FROM reservations
+		WHERE expires_at < (now() AT TIME ZONE 'UTC') AND expired_at IS NULL AND finished_at IS NOT NULL
+		ORDER BY expires_at LIMIT $1`
+
+	var result []*models.Reservation
+
+	rows, err := db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
+func (x *reservationDao) UnscopedMarkExpired(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET expired_at = now() WHERE id = $1 AND expired_at IS NULL`
+
+	tag, err := db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
+func (x *reservationDao) UnscopedListStuck(ctx context.Context, olderThan time.Duration, limit int64) ([]*models.Reservation, error) {
+	query := `SELECT * FROM reservations
+		WHERE finished_at IS NULL AND created_at < now() - cast($1 as interval)
+		ORDER BY created_at LIMIT $2`
+
+	var result []*models.Reservation
+
+	rows, err := db.Pool.Query(ctx, query, olderThan.String(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+
+	err = pgxscan.ScanAll(&result, rows)
+	if err != nil {
+		return nil, fmt.Errorf("pgx error: %w", err)
+	}
+	return result, nil
+}
+
 func (x *reservationDao) UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error {
 	query := `UPDATE reservations SET status = $2, step = step + $3 WHERE id = $1`
 
@@ -352,8 +549,26 @@ func (x *reservationDao) UpdateOperationNameForGCP(ctx context.Context, id int64
 	return nil
 }
 
+func (x *reservationDao) Cancel(ctx context.Context, id int64) error {
+	query := `UPDATE reservations SET cancelled_at = now()
+		WHERE account_id = $1 AND id = $2 AND finished_at IS NULL AND cancelled_at IS NULL`
+	accountId := identity.AccountId(ctx)
+
+	tag, err := db.Pool.Exec(ctx, query, accountId, id)
+	if err != nil {
+		return fmt.Errorf("pgx error: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("expected 1 row, got %d: %w", tag.RowsAffected(), dao.ErrAffectedMismatch)
+	}
+	return nil
+}
+
 func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64) error {
-	query := `UPDATE reservations SET success = true, finished_at = now() WHERE id = $1`
+	// cancellation is checked atomically, it can happen anytime during the job, the stuck reservation
+	// reaper may finish the reservation concurrently too
+	query := `UPDATE reservations SET success = true, finished_at = now()
+		WHERE id = $1 AND cancelled_at IS NULL AND finished_at IS NULL`
 
 	tag, err := db.Pool.Exec(ctx, query, id)
 	if err != nil {
@@ -366,7 +581,8 @@ func (x *reservationDao) FinishWithSuccess(ctx context.Context, id int64) error
 }
 
 func (x *reservationDao) FinishWithError(ctx context.Context, id int64, errorString string) error {
-	query := `UPDATE reservations SET success = false, error = $2, finished_at = now() WHERE id = $1`
+	// the job and the stuck reservation reaper may finish the reservation concurrently
+	query := `UPDATE reservations SET success = false, error = $2, finished_at = now() WHERE id = $1 AND finished_at IS NULL`
 
 	tag, err := db.Pool.Exec(ctx, query, id, errorString)
 	if err != nil {
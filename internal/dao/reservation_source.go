@@ -0,0 +1,42 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+// ReservationSourceAndLocation returns source ID and location (AWS region, Azure location or GCP zone)
+// of instances launched by a reservation. ErrUnsupportedProvider is returned for reservations which
+// do not launch instances.
+func ReservationSourceAndLocation(ctx context.Context, reservation *models.Reservation) (string, string, error) {
+	if reservation.LaunchReservationID.Valid {
+		return "", "", fmt.Errorf("%w: instance action reservation", ErrUnsupportedProvider)
+	}
+	rDao := GetReservationDao(ctx)
+
+	//nolint:exhaustive
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		awsReservation, err := rDao.GetAWSById(ctx, reservation.ID)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot get AWS reservation: %w", err)
+		}
+		return awsReservation.SourceID, awsReservation.Detail.Region, nil
+	case models.ProviderTypeAzure:
+		azureReservation, err := rDao.GetAzureById(ctx, reservation.ID)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot get Azure reservation: %w", err)
+		}
+		return azureReservation.SourceID, azureReservation.Detail.Location, nil
+	case models.ProviderTypeGCP:
+		gcpReservation, err := rDao.GetGCPById(ctx, reservation.ID)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot get GCP reservation: %w", err)
+		}
+		return gcpReservation.SourceID, gcpReservation.Detail.Zone, nil
+	default:
+		return "", "", fmt.Errorf("%w: %s", ErrUnsupportedProvider, reservation.Provider)
+	}
+}
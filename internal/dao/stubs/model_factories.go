@@ -11,6 +11,11 @@ func AddPubkey(ctx context.Context, pubkey *models.Pubkey) error {
 	return pubkeyDao.Create(ctx, pubkey)
 }
 
+func AddPubkeyResource(ctx context.Context, pkr *models.PubkeyResource) error {
+	pubkeyDao := getPubkeyDaoStub(ctx)
+	return pubkeyDao.UnscopedCreateResource(ctx, pkr)
+}
+
 func AddAWSReservation(ctx context.Context, reservation *models.AWSReservation) error {
 	reservationDao := getReservationDaoStub(ctx)
 	return reservationDao.CreateAWS(ctx, reservation)
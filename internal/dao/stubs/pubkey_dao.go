@@ -2,6 +2,8 @@ package stubs
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -72,16 +74,39 @@ func (stub *pubkeyDaoStub) GetById(ctx context.Context, id int64) (*models.Pubke
 	return nil, dao.ErrNoRows
 }
 
-func (stub *pubkeyDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Pubkey, error) {
+func (stub *pubkeyDaoStub) List(ctx context.Context, filter *dao.PubkeyFilter, limit, offset int64) ([]*models.Pubkey, error) {
+	var afterId int64
+	if filter != nil {
+		afterId = filter.AfterID
+	}
+
 	var filtered []*models.Pubkey
 	for _, pk := range stub.store {
-		if pk.AccountID == ctxAccountId(ctx) {
+		if pk.AccountID == ctxAccountId(ctx) && pk.ID > afterId {
 			filtered = append(filtered, pk)
 		}
 	}
+
+	if offset >= int64(len(filtered)) {
+		return nil, nil
+	}
+	filtered = filtered[offset:]
+	if limit < int64(len(filtered)) {
+		filtered = filtered[:limit]
+	}
 	return filtered, nil
 }
 
+func (stub *pubkeyDaoStub) UnscopedList(ctx context.Context, afterId, limit int64) ([]*models.Pubkey, error) {
+	var result []*models.Pubkey
+	for _, pk := range stub.store {
+		if pk.ID > afterId && int64(len(result)) < limit {
+			result = append(result, pk)
+		}
+	}
+	return result, nil
+}
+
 func (stub *pubkeyDaoStub) Count(ctx context.Context) (int, error) {
 	return len(stub.store), nil
 }
@@ -111,7 +136,33 @@ func (stub *pubkeyDaoStub) UnscopedCreateResource(ctx context.Context, pkr *mode
 	return nil
 }
 
+func (stub *pubkeyDaoStub) UnscopedUpdateResource(ctx context.Context, pkr *models.PubkeyResource) error {
+	for idx, r := range stub.resourceStore {
+		if r.ID == pkr.ID {
+			stub.resourceStore[idx] = pkr
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+func (stub *pubkeyDaoStub) UnscopedVerifyResource(ctx context.Context, id int64) error {
+	for _, r := range stub.resourceStore {
+		if r.ID == id {
+			r.VerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
 func (stub *pubkeyDaoStub) UnscopedDeleteResource(ctx context.Context, id int64) error {
+	for idx, pkr := range stub.resourceStore {
+		if pkr.ID == id {
+			stub.resourceStore = append(stub.resourceStore[:idx], stub.resourceStore[idx+1:]...)
+			return nil
+		}
+	}
 	return nil
 }
 
@@ -2,7 +2,11 @@ package stubs
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"math"
+	"sort"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
@@ -10,10 +14,11 @@ import (
 )
 
 type reservationDaoStub struct {
-	storeAWS   []*models.AWSReservation
-	storeAzure []*models.AzureReservation
-	storeGCP   []*models.GCPReservation
-	instances  map[int64][]*models.ReservationInstance
+	storeAWS             []*models.AWSReservation
+	storeAzure           []*models.AzureReservation
+	storeGCP             []*models.GCPReservation
+	storeInstanceActions []*models.InstanceActionReservation
+	instances            map[int64][]*models.ReservationInstance
 }
 
 func init() {
@@ -35,32 +40,56 @@ func GCPReservationStubCount(ctx context.Context) int {
 	return len(resDao.storeGCP)
 }
 
+func InstanceActionReservationStubCount(ctx context.Context) int {
+	resDao := getReservationDaoStub(ctx)
+	return len(resDao.storeInstanceActions)
+}
+
 func getReservationDao(ctx context.Context) dao.ReservationDao {
 	return getReservationDaoStub(ctx)
 }
 
-func (stub *reservationDaoStub) CreateAWS(ctx context.Context, reservation *models.AWSReservation) error {
+func (stub *reservationDaoStub) CreateAWS(ctx context.Context, reservation *models.AWSReservation, hooks ...dao.TxFn) error {
+	if err := stub.checkIdempotencyKey(ctx, reservation.IdempotencyKey); err != nil {
+		return err
+	}
 	reservation.ID = int64(len(stub.storeAWS)) + 1
+	reservation.AccountID = ctxAccountId(ctx)
 	stub.storeAWS = append(stub.storeAWS, reservation)
-	return nil
+	return runHooks(hooks)
 }
 
-func (stub *reservationDaoStub) CreateAzure(ctx context.Context, reservation *models.AzureReservation) error {
+func (stub *reservationDaoStub) CreateAzure(ctx context.Context, reservation *models.AzureReservation, hooks ...dao.TxFn) error {
+	if err := stub.checkIdempotencyKey(ctx, reservation.IdempotencyKey); err != nil {
+		return err
+	}
 	reservation.ID = int64(len(stub.storeAzure)) + 1
+	reservation.AccountID = ctxAccountId(ctx)
 	stub.storeAzure = append(stub.storeAzure, reservation)
-	return nil
+	return runHooks(hooks)
 }
 
-func (stub *reservationDaoStub) CreateGCP(ctx context.Context, reservation *models.GCPReservation) error {
+func (stub *reservationDaoStub) CreateGCP(ctx context.Context, reservation *models.GCPReservation, hooks ...dao.TxFn) error {
+	if err := stub.checkIdempotencyKey(ctx, reservation.IdempotencyKey); err != nil {
+		return err
+	}
 	reservation.ID = int64(len(stub.storeGCP)) + 1
+	reservation.AccountID = ctxAccountId(ctx)
 	stub.storeGCP = append(stub.storeGCP, reservation)
-	return nil
+	return runHooks(hooks)
 }
 
 func (stub *reservationDaoStub) CreateNoop(ctx context.Context, reservation *models.NoopReservation) error {
 	return nil
 }
 
+func (stub *reservationDaoStub) CreateInstanceAction(ctx context.Context, reservation *models.InstanceActionReservation) error {
+	reservation.ID = int64(len(stub.storeAWS)+len(stub.storeAzure)+len(stub.storeGCP)+len(stub.storeInstanceActions)) + 1
+	reservation.AccountID = ctxAccountId(ctx)
+	stub.storeInstanceActions = append(stub.storeInstanceActions, reservation)
+	return nil
+}
+
 func (stub *reservationDaoStub) CreateInstance(ctx context.Context, resInstance *models.ReservationInstance) error {
 	resId := resInstance.ReservationID
 	stub.instances[resId] = append(stub.instances[resId], resInstance)
@@ -73,9 +102,61 @@ func (stub *reservationDaoStub) GetById(ctx context.Context, id int64) (*models.
 			return &awsReservation.Reservation, nil
 		}
 	}
+	for _, azureReservation := range stub.storeAzure {
+		if azureReservation.AccountID == ctxAccountId(ctx) && azureReservation.ID == id {
+			return &azureReservation.Reservation, nil
+		}
+	}
+	for _, gcpReservation := range stub.storeGCP {
+		if gcpReservation.AccountID == ctxAccountId(ctx) && gcpReservation.ID == id {
+			return &gcpReservation.Reservation, nil
+		}
+	}
+	for _, actionReservation := range stub.storeInstanceActions {
+		if actionReservation.AccountID == ctxAccountId(ctx) && actionReservation.ID == id {
+			return &actionReservation.Reservation, nil
+		}
+	}
 	return nil, dao.ErrNoRows
 }
 
+func (stub *reservationDaoStub) GetByIdempotencyKey(ctx context.Context, key string) (*models.Reservation, error) {
+	for _, res := range stub.all() {
+		if res.AccountID == ctxAccountId(ctx) && res.IdempotencyKey.Valid && res.IdempotencyKey.String == key {
+			return res, nil
+		}
+	}
+	return nil, dao.ErrNoRows
+}
+
+// checkIdempotencyKey emulates the unique index on idempotency key
+func (stub *reservationDaoStub) checkIdempotencyKey(ctx context.Context, key sql.NullString) error {
+	if !key.Valid {
+		return nil
+	}
+	if _, err := stub.GetByIdempotencyKey(ctx, key.String); err == nil {
+		return dao.ErrDuplicateIdempotencyKey
+	}
+	return nil
+}
+
+func (stub *reservationDaoStub) all() []*models.Reservation {
+	all := make([]*models.Reservation, 0, len(stub.storeAWS)+len(stub.storeAzure)+len(stub.storeGCP)+len(stub.storeInstanceActions))
+	for _, awsReservation := range stub.storeAWS {
+		all = append(all, &awsReservation.Reservation)
+	}
+	for _, azureReservation := range stub.storeAzure {
+		all = append(all, &azureReservation.Reservation)
+	}
+	for _, gcpReservation := range stub.storeGCP {
+		all = append(all, &gcpReservation.Reservation)
+	}
+	for _, actionReservation := range stub.storeInstanceActions {
+		all = append(all, &actionReservation.Reservation)
+	}
+	return all
+}
+
 func (stub *reservationDaoStub) GetAWSById(ctx context.Context, id int64) (*models.AWSReservation, error) {
 	for _, awsReservation := range stub.storeAWS {
 		if awsReservation.AccountID == ctxAccountId(ctx) && awsReservation.ID == id {
@@ -103,18 +184,180 @@ func (stub *reservationDaoStub) GetGCPById(ctx context.Context, id int64) (*mode
 	return nil, dao.ErrNoRows
 }
 
-func (stub *reservationDaoStub) Count(ctx context.Context) (int, error) {
-	return len(stub.storeAWS) + len(stub.storeAzure) + len(stub.storeGCP), nil
+func (stub *reservationDaoStub) Count(ctx context.Context, filter *dao.ReservationFilter) (int, error) {
+	return len(stub.filter(ctx, filter)), nil
+}
+
+func (stub *reservationDaoStub) List(ctx context.Context, filter *dao.ReservationFilter, limit, offset int64) ([]*models.Reservation, error) {
+	result := stub.filter(ctx, filter)
+	if offset >= int64(len(result)) {
+		return []*models.Reservation{}, nil
+	}
+	result = result[offset:]
+	if limit < int64(len(result)) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// filter returns reservations of the account matching the filter in the filter order
+func (stub *reservationDaoStub) filter(ctx context.Context, filter *dao.ReservationFilter) []*models.Reservation {
+	result := make([]*models.Reservation, 0)
+	for _, reservation := range stub.all() {
+		if reservation.AccountID == ctxAccountId(ctx) && stub.matches(reservation, filter) {
+			result = append(result, reservation)
+		}
+	}
+	if filter == nil {
+		filter = &dao.ReservationFilter{}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if filter.SortDesc {
+			a, b = b, a
+		}
+		//nolint:exhaustive
+		switch filter.SortBy {
+		case dao.ReservationSortByCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		case dao.ReservationSortByFinishedAt:
+			// unfinished reservations are last in both directions
+			if result[i].FinishedAt.Valid != result[j].FinishedAt.Valid {
+				return result[i].FinishedAt.Valid
+			}
+			if !a.FinishedAt.Time.Equal(b.FinishedAt.Time) {
+				return a.FinishedAt.Time.Before(b.FinishedAt.Time)
+			}
+		}
+		return a.ID < b.ID
+	})
+	return result
+}
+
+func (stub *reservationDaoStub) matches(reservation *models.Reservation, filter *dao.ReservationFilter) bool {
+	if filter == nil {
+		return true
+	}
+	if filter.Provider != models.ProviderTypeUnknown && (reservation.Provider != filter.Provider || reservation.LaunchReservationID.Valid) {
+		return false
+	}
+
+	//nolint:exhaustive
+	switch filter.Status {
+	case dao.ReservationStatusPending:
+		if reservation.Success.Valid {
+			return false
+		}
+	case dao.ReservationStatusSuccess:
+		if !reservation.Success.Valid || !reservation.Success.Bool {
+			return false
+		}
+	case dao.ReservationStatusFailure:
+		if !reservation.Success.Valid || reservation.Success.Bool {
+			return false
+		}
+	}
+
+	if filter.SourceID != "" && stub.sourceID(reservation.ID) != filter.SourceID {
+		return false
+	}
+	if filter.InstanceID != "" && !stub.hasInstance(reservation.ID, filter.InstanceID) {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && reservation.CreatedAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !reservation.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	if filter.AfterID != 0 {
+		if filter.SortDesc && reservation.ID >= filter.AfterID {
+			return false
+		}
+		if !filter.SortDesc && reservation.ID <= filter.AfterID {
+			return false
+		}
+	}
+	return true
+}
+
+func (stub *reservationDaoStub) sourceID(id int64) string {
+	for _, awsReservation := range stub.storeAWS {
+		if awsReservation.ID == id {
+			return awsReservation.SourceID
+		}
+	}
+	for _, azureReservation := range stub.storeAzure {
+		if azureReservation.ID == id {
+			return azureReservation.SourceID
+		}
+	}
+	for _, gcpReservation := range stub.storeGCP {
+		if gcpReservation.ID == id {
+			return gcpReservation.SourceID
+		}
+	}
+	return ""
 }
 
-func (stub *reservationDaoStub) List(ctx context.Context, limit, offset int64) ([]*models.Reservation, error) {
-	return nil, nil
+func (stub *reservationDaoStub) hasInstance(id int64, instanceID string) bool {
+	for _, instance := range stub.instances[id] {
+		if instance.InstanceID == instanceID {
+			return true
+		}
+	}
+	return false
 }
 
 func (stub *reservationDaoStub) ListInstances(ctx context.Context, reservationId int64) ([]*models.ReservationInstance, error) {
 	return stub.instances[reservationId], nil
 }
 
+func (stub *reservationDaoStub) UnscopedListExpired(ctx context.Context, limit int64) ([]*models.Reservation, error) {
+	var result []*models.Reservation
+	now := time.Now()
+	for _, res := range stub.all() {
+		if int64(len(result)) >= limit {
+			break
+		}
+		if res.ExpiresAt.Valid && res.ExpiresAt.Time.Before(now) && !res.ExpiredAt.Valid && res.FinishedAt.Valid {
+			result = append(result, res)
+		}
+	}
+	return result, nil
+}
+
+func (stub *reservationDaoStub) UnscopedMarkExpired(ctx context.Context, id int64) error {
+	expired, err := stub.UnscopedListExpired(ctx, math.MaxInt64)
+	if err != nil {
+		return err
+	}
+	for _, res := range expired {
+		if res.ID == id {
+			res.ExpiredAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+func (stub *reservationDaoStub) UnscopedListStuck(ctx context.Context, olderThan time.Duration, limit int64) ([]*models.Reservation, error) {
+	var result []*models.Reservation
+	createdBefore := time.Now().Add(-olderThan)
+	for _, res := range stub.all() {
+		if int64(len(result)) >= limit {
+			break
+		}
+		if !res.FinishedAt.Valid && res.CreatedAt.Before(createdBefore) {
+			result = append(result, res)
+		}
+	}
+	return result, nil
+}
+
 func (stub *reservationDaoStub) UpdateStatus(ctx context.Context, id int64, status string, addSteps int32) error {
 	return nil
 }
@@ -136,11 +379,42 @@ func (stub *reservationDaoStub) UpdateOperationNameForGCP(ctx context.Context, i
 	return nil
 }
 
+func (stub *reservationDaoStub) Cancel(ctx context.Context, id int64) error {
+	res, err := stub.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if res.FinishedAt.Valid || res.CancelledAt.Valid {
+		return dao.ErrAffectedMismatch
+	}
+	res.CancelledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
 func (stub *reservationDaoStub) FinishWithSuccess(ctx context.Context, id int64) error {
+	res, err := stub.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if res.CancelledAt.Valid || res.FinishedAt.Valid {
+		return dao.ErrAffectedMismatch
+	}
+	res.Success = sql.NullBool{Bool: true, Valid: true}
+	res.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	return nil
 }
 
 func (stub *reservationDaoStub) FinishWithError(ctx context.Context, id int64, errorString string) error {
+	res, err := stub.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if res.FinishedAt.Valid {
+		return dao.ErrAffectedMismatch
+	}
+	res.Success = sql.NullBool{Bool: false, Valid: true}
+	res.Error = errorString
+	res.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
 	return nil
 }
 
@@ -156,6 +430,30 @@ func (stub *reservationDaoStub) UpdateReservationInstance(ctx context.Context, r
 	for _, instRes := range stub.instances[reservationID] {
 		if instRes.InstanceID == instance.ID {
 			instRes.Detail.PublicIPv4 = instance.IPv4
+			instRes.Detail.PublicDNS = instance.DNS
+			instRes.Detail.PrivateIPv4 = instance.PrivateIPv4
+			instRes.Detail.PrivateIPv6 = instance.PrivateIPv6
+			instRes.Detail.State = instance.State
+		}
+	}
+	return nil
+}
+
+func (stub *reservationDaoStub) UnscopedDeleteInstance(ctx context.Context, reservationID int64, instanceID string) error {
+	for i, instRes := range stub.instances[reservationID] {
+		if instRes.InstanceID == instanceID {
+			stub.instances[reservationID] = append(stub.instances[reservationID][:i], stub.instances[reservationID][i+1:]...)
+			return nil
+		}
+	}
+	return dao.ErrAffectedMismatch
+}
+
+// runHooks calls transaction hooks with nil transaction, stubs do not support transactions.
+func runHooks(hooks []dao.TxFn) error {
+	for _, hook := range hooks {
+		if err := hook(nil); err != nil {
+			return err
 		}
 	}
 	return nil
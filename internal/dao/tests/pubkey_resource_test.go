@@ -131,6 +131,57 @@ func TestPubkeyResourceListByPubkeyId(t *testing.T) {
 	})
 }
 
+func TestPubkeyResourceUpdate(t *testing.T) {
+	pubkeyDao, ctx := setupPubkeyResource(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		resource := newPubkeyResourceNoop()
+		err := pubkeyDao.UnscopedCreateResource(ctx, resource)
+		require.NoError(t, err)
+
+		resource.Handle = factories.SeqNameWithPrefix("handle")
+		resource.Tag = "tag2"
+		err = pubkeyDao.UnscopedUpdateResource(ctx, resource)
+		require.NoError(t, err)
+
+		updated, err := pubkeyDao.UnscopedGetResourceBySourceAndRegion(ctx, resource.PubkeyID, resource.SourceID, resource.Region)
+		require.NoError(t, err)
+		assert.Equal(t, resource, updated)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		resource := newPubkeyResourceNoop()
+		resource.ID = math.MaxInt64
+		err := pubkeyDao.UnscopedUpdateResource(ctx, resource)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
+func TestPubkeyResourceVerify(t *testing.T) {
+	pubkeyDao, ctx := setupPubkeyResource(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		resource := newPubkeyResourceNoop()
+		err := pubkeyDao.UnscopedCreateResource(ctx, resource)
+		require.NoError(t, err)
+
+		err = pubkeyDao.UnscopedVerifyResource(ctx, resource.ID)
+		require.NoError(t, err)
+
+		updated, err := pubkeyDao.UnscopedGetResourceBySourceAndRegion(ctx, resource.PubkeyID, resource.SourceID, resource.Region)
+		require.NoError(t, err)
+		assert.True(t, updated.VerifiedAt.Valid)
+		assert.Equal(t, resource.Handle, updated.Handle)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := pubkeyDao.UnscopedVerifyResource(ctx, math.MaxInt64)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
 func TestPubkeyResourceDelete(t *testing.T) {
 	pubkeyDao, ctx := setupPubkeyResource(t)
 	defer reset()
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
 	"github.com/go-playground/validator/v10"
@@ -39,7 +40,7 @@ func TestPubkeyCreate(t *testing.T) {
 	t.Run("fingerprint generation of unsupported key", func(t *testing.T) {
 		pk := factories.NewPubkeyDSS()
 		err := pkDao.Create(ctx, pk)
-		require.ErrorContains(t, err, "x509: unsupported public key type")
+		require.ErrorIs(t, err, models.ErrUnsupportedPubkeyType)
 	})
 
 	t.Run("validation error on name", func(t *testing.T) {
@@ -62,7 +63,7 @@ func TestPubkeyList(t *testing.T) {
 	defer reset()
 
 	t.Run("success", func(t *testing.T) {
-		pubkeys, err := pkDao.List(ctx, 1, 0)
+		pubkeys, err := pkDao.List(ctx, nil, 1, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 	})
@@ -72,15 +73,46 @@ func TestPubkeyList(t *testing.T) {
 		err := pkDao.Create(ctx, newKey)
 		require.NoError(t, err)
 
-		pubkeys, err := pkDao.List(ctx, 1, 0)
+		pubkeys, err := pkDao.List(ctx, nil, 1, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 
-		pubkeys, err = pkDao.List(ctx, 1, 1)
+		pubkeys, err = pkDao.List(ctx, nil, 1, 1)
 		require.NoError(t, err)
 		assert.Equal(t, 1, len(pubkeys))
 		require.Contains(t, pubkeys, newKey)
 	})
+
+	t.Run("with keyset", func(t *testing.T) {
+		pubkeys, err := pkDao.List(ctx, nil, 1, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(pubkeys))
+
+		next, err := pkDao.List(ctx, &dao.PubkeyFilter{AfterID: pubkeys[0].ID}, 10, 0)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(next))
+		assert.Greater(t, next[0].ID, pubkeys[0].ID)
+	})
+}
+
+func TestPubkeyUnscopedList(t *testing.T) {
+	pkDao, ctx := setupPubkey(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		newKey := factories.NewPubkeyRSA()
+		err := pkDao.Create(ctx, newKey)
+		require.NoError(t, err)
+
+		pubkeys, err := pkDao.UnscopedList(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, len(pubkeys))
+
+		pubkeys, err = pkDao.UnscopedList(ctx, pubkeys[0].ID, 10)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(pubkeys))
+		assert.Equal(t, newKey.ID, pubkeys[0].ID)
+	})
 }
 
 func TestPubkeyUpdate(t *testing.T) {
@@ -5,6 +5,7 @@ package tests
 
 import (
 	"context"
+	"database/sql"
 	"math"
 	"testing"
 	"time"
@@ -178,6 +179,78 @@ func TestReservationCreateAWSInstance(t *testing.T) {
 		assert.Equal(t, instance.InstanceID, instancesList[0].InstanceID)
 		assert.Equal(t, instance.Detail.PublicIPv4, instancesList[0].Detail.PublicIPv4)
 	})
+
+	t.Run("delete", func(t *testing.T) {
+		reservation := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, reservation)
+		require.NoError(t, err)
+
+		instance := newReservationInstance(reservation.ID)
+		err = reservationDao.CreateInstance(ctx, instance)
+		require.NoError(t, err)
+
+		err = reservationDao.UnscopedDeleteInstance(ctx, reservation.ID, instance.InstanceID)
+		require.NoError(t, err)
+
+		instancesList, err := reservationDao.ListInstances(ctx, reservation.ID)
+		require.NoError(t, err)
+		assert.Empty(t, instancesList)
+
+		err = reservationDao.UnscopedDeleteInstance(ctx, reservation.ID, instance.InstanceID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
+func newInstanceActionReservation(launchReservationID int64) *models.InstanceActionReservation {
+	return &models.InstanceActionReservation{
+		Reservation: models.Reservation{
+			Steps:               1,
+			StepTitles:          []string{"Stop instance(s)"},
+			Provider:            models.ProviderTypeAWS,
+			AccountID:           1,
+			Status:              "Created",
+			LaunchReservationID: sql.NullInt64{Int64: launchReservationID, Valid: true},
+		},
+	}
+}
+
+func TestReservationCreateInstanceAction(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+
+	t.Run("success", func(t *testing.T) {
+		defer reset()
+		launch := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, launch)
+		require.NoError(t, err)
+
+		action := newInstanceActionReservation(launch.ID)
+		err = reservationDao.CreateInstanceAction(ctx, action)
+		require.NoError(t, err)
+
+		reservation, err := reservationDao.GetById(ctx, action.ID)
+		require.NoError(t, err)
+		assert.Equal(t, launch.ID, reservation.LaunchReservationID.Int64)
+
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{Provider: models.ProviderTypeAWS}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, launch.ID, reservations[0].ID)
+	})
+
+	t.Run("not rate limited", func(t *testing.T) {
+		defer reset()
+		launch := newAWSReservation()
+		err := reservationDao.CreateAWS(ctx, launch)
+		require.NoError(t, err)
+
+		for i := 1; i <= 5; i++ {
+			err = reservationDao.CreateInstanceAction(ctx, newInstanceActionReservation(launch.ID))
+			require.NoError(t, err)
+		}
+
+		err = reservationDao.CreateAWS(ctx, newAWSReservation())
+		require.NoError(t, err)
+	})
 }
 
 func TestReservationList(t *testing.T) {
@@ -185,7 +258,7 @@ func TestReservationList(t *testing.T) {
 	defer reset()
 
 	t.Run("empty", func(t *testing.T) {
-		reservations, err := reservationDao.List(ctx, 10, 0)
+		reservations, err := reservationDao.List(ctx, nil, 10, 0)
 		require.NoError(t, err)
 		require.Empty(t, reservations)
 	})
@@ -199,12 +272,96 @@ func TestReservationList(t *testing.T) {
 		err = reservationDao.CreateNoop(ctx, noopReservation)
 		require.NoError(t, err)
 
-		reservations, err := reservationDao.List(ctx, 10, 0)
+		reservations, err := reservationDao.List(ctx, nil, 10, 0)
 		require.NoError(t, err)
 		assert.Equal(t, 2, len(reservations))
 	})
 }
 
+func TestReservationListFilter(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	awsReservation := newAWSReservation()
+	awsReservation.SourceID = "src-1"
+	err := reservationDao.CreateAWS(ctx, awsReservation)
+	require.NoError(t, err)
+	err = reservationDao.FinishWithSuccess(ctx, awsReservation.ID)
+	require.NoError(t, err)
+
+	noopReservation := newNoopReservation()
+	err = reservationDao.CreateNoop(ctx, noopReservation)
+	require.NoError(t, err)
+
+	t.Run("provider", func(t *testing.T) {
+		filter := &dao.ReservationFilter{Provider: models.ProviderTypeNoop}
+		reservations, err := reservationDao.List(ctx, filter, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, noopReservation.ID, reservations[0].ID)
+
+		count, err := reservationDao.Count(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{Status: dao.ReservationStatusSuccess}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, awsReservation.ID, reservations[0].ID)
+
+		reservations, err = reservationDao.List(ctx, &dao.ReservationFilter{Status: dao.ReservationStatusPending}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, noopReservation.ID, reservations[0].ID)
+
+		reservations, err = reservationDao.List(ctx, &dao.ReservationFilter{Status: dao.ReservationStatusFailure}, 10, 0)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+	})
+
+	t.Run("source", func(t *testing.T) {
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{SourceID: "src-1"}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, awsReservation.ID, reservations[0].ID)
+
+		reservations, err = reservationDao.List(ctx, &dao.ReservationFilter{SourceID: "src-2"}, 10, 0)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+	})
+
+	t.Run("created", func(t *testing.T) {
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{CreatedBefore: time.Now().Add(-time.Hour)}, 10, 0)
+		require.NoError(t, err)
+		require.Empty(t, reservations)
+
+		reservations, err = reservationDao.List(ctx, &dao.ReservationFilter{CreatedAfter: time.Now().Add(-time.Hour)}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 2)
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{SortBy: dao.ReservationSortByID, SortDesc: true}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 2)
+		assert.Greater(t, reservations[0].ID, reservations[1].ID)
+	})
+
+	t.Run("keyset", func(t *testing.T) {
+		reservations, err := reservationDao.List(ctx, &dao.ReservationFilter{AfterID: awsReservation.ID}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, noopReservation.ID, reservations[0].ID)
+
+		reservations, err = reservationDao.List(ctx, &dao.ReservationFilter{AfterID: noopReservation.ID, SortDesc: true}, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, reservations, 1)
+		assert.Equal(t, awsReservation.ID, reservations[0].ID)
+	})
+}
+
 func TestUnscopedUpdateAWSDetail(t *testing.T) {
 	reservationDao, ctx := setupReservation(t)
 	defer reset()
@@ -353,6 +510,199 @@ func TestReservationFinish(t *testing.T) {
 		err := reservationDao.FinishWithError(ctx, math.MaxInt64, "")
 		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
 	})
+
+	t.Run("error already finished", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithError(ctx, res.ID, "error")
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, newRes.Success.Bool)
+		assert.Empty(t, newRes.Error)
+	})
+
+	t.Run("success already finished", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithError(ctx, res.ID, "error")
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.False(t, newRes.Success.Bool)
+		assert.Equal(t, "error", newRes.Error)
+	})
+}
+
+func TestReservationCancel(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.Cancel(ctx, res.ID)
+		require.NoError(t, err)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, newRes.CancelledAt.Valid)
+	})
+
+	t.Run("already finished", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.NoError(t, err)
+
+		err = reservationDao.Cancel(ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+
+	t.Run("finish cancelled", func(t *testing.T) {
+		res := newNoopReservation()
+		err := reservationDao.CreateNoop(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.Cancel(ctx, res.ID)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.False(t, newRes.FinishedAt.Valid)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := reservationDao.Cancel(ctx, math.MaxInt64)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
+func TestReservationExpiry(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	t.Run("success", func(t *testing.T) {
+		res := newAWSReservation()
+		res.ExpiresAt = sql.NullTime{Time: time.Now().UTC().Add(-time.Minute), Valid: true}
+		err := reservationDao.CreateAWS(ctx, res)
+		require.NoError(t, err)
+
+		expired, err := reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, expired, "unfinished reservation must not expire")
+
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.NoError(t, err)
+
+		expired, err = reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		assert.Equal(t, res.ID, expired[0].ID)
+
+		err = reservationDao.UnscopedMarkExpired(ctx, res.ID)
+		require.NoError(t, err)
+
+		newRes, err := reservationDao.GetById(ctx, res.ID)
+		require.NoError(t, err)
+		assert.True(t, newRes.ExpiredAt.Valid)
+
+		expired, err = reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+	})
+
+	t.Run("not expired", func(t *testing.T) {
+		res := newAWSReservation()
+		res.ExpiresAt = sql.NullTime{Time: time.Now().UTC().Add(time.Hour), Valid: true}
+		err := reservationDao.CreateAWS(ctx, res)
+		require.NoError(t, err)
+
+		err = reservationDao.FinishWithSuccess(ctx, res.ID)
+		require.NoError(t, err)
+
+		expired, err := reservationDao.UnscopedListExpired(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		err := reservationDao.UnscopedMarkExpired(ctx, math.MaxInt64)
+		require.ErrorIs(t, err, dao.ErrAffectedMismatch)
+	})
+}
+
+func TestReservationIdempotencyKey(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	res := newAWSReservation()
+	res.IdempotencyKey = sql.NullString{String: "key-1", Valid: true}
+	err := reservationDao.CreateAWS(ctx, res)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		found, err := reservationDao.GetByIdempotencyKey(ctx, "key-1")
+		require.NoError(t, err)
+		assert.Equal(t, res.ID, found.ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := reservationDao.GetByIdempotencyKey(ctx, "key-2")
+		require.ErrorIs(t, err, dao.ErrNoRows)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		dup := newAWSReservation()
+		dup.IdempotencyKey = sql.NullString{String: "key-1", Valid: true}
+		err := reservationDao.CreateAWS(ctx, dup)
+		require.ErrorIs(t, err, dao.ErrDuplicateIdempotencyKey)
+	})
+}
+
+func TestReservationStuck(t *testing.T) {
+	reservationDao, ctx := setupReservation(t)
+	defer reset()
+
+	res := newAWSReservation()
+	err := reservationDao.CreateAWS(ctx, res)
+	require.NoError(t, err)
+
+	stuck, err := reservationDao.UnscopedListStuck(ctx, time.Hour, 10)
+	require.NoError(t, err)
+	assert.Empty(t, stuck, "new reservation must not be stuck")
+
+	// negative duration moves the threshold into the future
+	stuck, err = reservationDao.UnscopedListStuck(ctx, -time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, stuck, 1)
+	assert.Equal(t, res.ID, stuck[0].ID)
+
+	err = reservationDao.FinishWithError(ctx, res.ID, "stuck")
+	require.NoError(t, err)
+
+	stuck, err = reservationDao.UnscopedListStuck(ctx, -time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, stuck, "finished reservation must not be stuck")
 }
 
 func TestReservationRate(t *testing.T) {
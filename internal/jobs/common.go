@@ -10,30 +10,47 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/metrics"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/telemetry"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/rs/zerolog"
 )
 
 const TraceName = telemetry.TracePrefix + "internal/jobs"
 
 var (
-	ErrTypeAssertion = errors.New("type assert error")
-	ErrPanicInJob    = errors.New("panic during job")
+	ErrTypeAssertion        = errors.New("type assert error")
+	ErrPanicInJob           = errors.New("panic during job")
+	ErrReservationCancelled = errors.New("reservation cancelled")
 )
 
-func finishJob(ctx context.Context, reservationId int64, jobErr error) {
+// finishJob closes a reservation and sends a notification. When the reservation was cancelled
+// after the last check, it cannot be finished with success and instances are rolled back via
+// the terminate function instead. Jobs which do not launch instances pass nil. No notification
+// is sent when the reservation was not finished by this call.
+func finishJob(ctx context.Context, reservationId int64, jobErr error, terminate func(ctx context.Context, instanceIds []string) error) {
 	nc := notifications.GetNotificationClient(ctx)
 
 	if jobErr != nil {
 		nc.FailedLaunch(ctx, reservationId, jobErr)
 		finishWithError(ctx, reservationId, jobErr)
-	} else {
-		nc.SuccessfulLaunch(ctx, reservationId)
-		finishWithSuccess(ctx, reservationId)
+		return
+	}
+
+	err := finishWithSuccess(ctx, reservationId)
+	if errors.Is(err, dao.ErrAffectedMismatch) && terminate != nil && isCancelled(ctx, reservationId) {
+		finishCancelled(ctx, reservationId, terminate)
+		return
+	} else if err != nil {
+		// already finished (e.g. by the stuck reservation reaper) or the update failed, it is logged
+		return
 	}
+	nc.SuccessfulLaunch(ctx, reservationId)
 }
 
-func finishWithSuccess(ctx context.Context, reservationId int64) {
+// finishWithSuccess closes a reservation and sets it into success state. The error is only
+// returned when the reservation could not be finished, it is already logged.
+func finishWithSuccess(ctx context.Context, reservationId int64) error {
 	logger := zerolog.Ctx(ctx)
 	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 		// the original context is expired and unusable at this point
@@ -45,7 +62,7 @@ func finishWithSuccess(ctx context.Context, reservationId int64) {
 	reservation, err := rDao.GetById(ctx, reservationId)
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update job status: get by id")
-		return
+		return fmt.Errorf("cannot finish reservation: %w", err)
 	}
 	if reservation.Step == reservation.Steps {
 		logger.Info().Msgf("Finishing reservation with success at step %d/%d", reservation.Step, reservation.Steps)
@@ -53,14 +70,16 @@ func finishWithSuccess(ctx context.Context, reservationId int64) {
 		logger.Error().Msgf("Finishing reservation with success at step %d/%d", reservation.Step, reservation.Steps)
 	}
 
-	// total count of reservations
-	metrics.IncReservationCount(reservation.Provider.String(), "success")
-
-	// and finish
+	// and finish, cancelled and finished reservations are not updated
 	err = rDao.FinishWithSuccess(ctx, reservationId)
 	if err != nil {
 		logger.Warn().Err(err).Msg("unable to update job status: finish")
+		return fmt.Errorf("cannot finish reservation: %w", err)
 	}
+
+	// total count of reservations
+	metrics.IncReservationCount(reservation.Provider.String(), "success")
+	return nil
 }
 
 // finishWithError closes a reservation and sets it into error state. Error message is also
@@ -91,6 +110,119 @@ func finishWithError(ctx context.Context, reservationId int64, jobError error) {
 	}
 }
 
+// isCancelled is called between job steps and returns true when the reservation was cancelled
+// by the user. Errors are logged and treated as not cancelled.
+func isCancelled(ctx context.Context, reservationId int64) bool {
+	logger := zerolog.Ctx(ctx)
+
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to check reservation cancellation: get by id")
+		return false
+	}
+
+	return reservation.CancelledAt.Valid
+}
+
+// isFinished returns true when the reservation is already finished, this happens when a job is
+// redelivered after a worker crashed. Errors are logged and treated as not finished.
+func isFinished(ctx context.Context, reservationId int64) bool {
+	logger := zerolog.Ctx(ctx)
+
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to check reservation state: get by id")
+		return false
+	}
+
+	return reservation.FinishedAt.Valid
+}
+
+// reservationStep returns the current step of the reservation. Errors are logged and zero is returned.
+func reservationStep(ctx context.Context, reservationId int64) int32 {
+	logger := zerolog.Ctx(ctx)
+
+	rDao := dao.GetReservationDao(ctx)
+	reservation, err := rDao.GetById(ctx, reservationId)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to get reservation step: get by id")
+		return 0
+	}
+
+	return reservation.Step
+}
+
+// failJob is called when a job fails. The reservation is only finished with the error on the last
+// attempt, otherwise it stays open and its step counter is set back to the value when the job
+// started, so the retried job starts over. The error is always returned and the worker either
+// retries the job or moves it into the dead-letter list.
+func failJob(ctx context.Context, reservationId int64, startStep int32, jobErr error) error {
+	if worker.IsLastAttempt(ctx) {
+		finishWithError(ctx, reservationId, jobErr)
+		return jobErr
+	}
+
+	logger := zerolog.Ctx(ctx)
+	logger.Warn().Err(jobErr).Msg("Job failed, it will be retried")
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// the original context is expired and unusable at this point
+		ctx = copyContext(ctx)
+	}
+
+	rDao := dao.GetReservationDao(ctx)
+	step := reservationStep(ctx, reservationId)
+	err := rDao.UpdateStatus(ctx, reservationId, "Retrying", startStep-step)
+	if err != nil {
+		logger.Warn().Err(err).Msg("unable to update job status: retry")
+	}
+	return jobErr
+}
+
+// launchedInZone returns the amount of instances launched in the zone by previous attempts of a
+// retried job, so only the remaining instances are launched.
+func launchedInZone(launched []*models.ReservationInstance, zone string) int64 {
+	var count int64
+	for _, instance := range launched {
+		if instance.Detail.Zone == zone {
+			count++
+		}
+	}
+	return count
+}
+
+// finishCancelled rolls back all instances created by a cancelled reservation via terminate function
+// and closes the reservation with an error.
+func finishCancelled(ctx context.Context, reservationId int64, terminate func(ctx context.Context, instanceIds []string) error) {
+	logger := zerolog.Ctx(ctx)
+	logger.Info().Msg("Reservation was cancelled, rolling back")
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// the original context is expired and unusable at this point
+		ctx = copyContext(ctx)
+	}
+
+	jobErr := ErrReservationCancelled
+	rDao := dao.GetReservationDao(ctx)
+	instances, err := rDao.ListInstances(ctx, reservationId)
+	if err != nil {
+		jobErr = fmt.Errorf("%w: cannot list instances to roll back: %s", ErrReservationCancelled, err.Error())
+	} else if len(instances) > 0 {
+		updateStatusBefore(ctx, reservationId, "Rolling back instance(s)")
+
+		ids := make([]string, len(instances))
+		for i, instance := range instances {
+			ids[i] = instance.InstanceID
+		}
+		logger.Debug().Msgf("Terminating %d instance(s) of cancelled reservation", len(ids))
+		if err := terminate(ctx, ids); err != nil {
+			jobErr = fmt.Errorf("%w: rollback of instance(s) failed: %s", ErrReservationCancelled, err.Error())
+		}
+	}
+
+	finishWithError(ctx, reservationId, jobErr)
+}
+
 // updateStatusBefore is called after every step function within a job. It updates reservation status
 // message.
 func updateStatusBefore(ctx context.Context, id int64, status string) {
@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+)
+
+var ErrUnknownInstanceAction = errors.New("unknown instance action")
+
+// InstanceActionSteps returns user-facing step titles for a reservation tracking an instance action.
+func InstanceActionSteps(action models.InstanceAction) []string {
+	switch action {
+	case models.InstanceActionStart:
+		return []string{"Start instance(s)"}
+	case models.InstanceActionStop:
+		return []string{"Stop instance(s)"}
+	case models.InstanceActionReboot:
+		return []string{"Reboot instance(s)"}
+	case models.InstanceActionTerminate:
+		return []string{"Terminate instance(s)"}
+	case models.InstanceActionUnknown:
+	}
+	return []string{"Unknown action"}
+}
+
+// finishInstanceActionJob closes a reservation tracking an instance action, failed actions are
+// retried via failJob. Unlike finishJob, it does not send launch notifications.
+func finishInstanceActionJob(ctx context.Context, reservationId int64, startStep int32, jobErr error) error {
+	if jobErr != nil {
+		return failJob(ctx, reservationId, startStep, jobErr)
+	}
+
+	_ = finishWithSuccess(ctx, reservationId)
+	return nil
+}
+
+// deleteTerminatedInstance removes a terminated instance from the reservation which launched it,
+// so it is no longer listed nor terminated again when the reservation expires. Jobs enqueued
+// without the launch reservation are skipped, an already removed instance is not an error.
+func deleteTerminatedInstance(ctx context.Context, launchReservationId int64, instanceId string) error {
+	if launchReservationId == 0 {
+		return nil
+	}
+
+	err := dao.GetReservationDao(ctx).UnscopedDeleteInstance(ctx, launchReservationId, instanceId)
+	if err != nil && !errors.Is(err, dao.ErrAffectedMismatch) {
+		return fmt.Errorf("cannot delete terminated instance %s: %w", instanceId, err)
+	}
+	return nil
+}
+
+func unknownInstanceActionError(action models.InstanceAction) error {
+	return fmt.Errorf("%w: '%s'", ErrUnknownInstanceAction, action)
+}
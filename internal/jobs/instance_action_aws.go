@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type InstanceActionAWSTaskArgs struct {
+	// Associated reservation tracking the action
+	ReservationID int64
+
+	// Reservation which launched the instances, terminated instances are removed from it
+	LaunchReservationID int64
+
+	// Action to perform
+	Action models.InstanceAction
+
+	// Region the instances were provisioned into
+	Region string
+
+	// EC2 instance IDs
+	InstanceIDs []string
+
+	// The ARN fetched from Sources which is linked to a specific source
+	ARN *clients.Authentication
+}
+
+// HandleInstanceActionAWS unmarshalls arguments and handles error
+func HandleInstanceActionAWS(ctx context.Context, job *worker.Job) (err error) {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msg("No job for HandleInstanceActionAWS")
+		return nil
+	}
+
+	args, ok := job.Args.(InstanceActionAWSTaskArgs)
+	if !ok {
+		err = fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	// context and logger
+	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
+	logger.Info().Msgf("Started instance action '%s' AWS job", args.Action)
+
+	// ensure panic finishes or retries the job
+	startStep := reservationStep(ctx, args.ReservationID)
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
+			err = failJob(ctx, args.ReservationID, startStep, panicErr)
+		}
+	}()
+
+	jobErr := DoInstanceActionAWS(ctx, &args)
+	return finishInstanceActionJob(ctx, args.ReservationID, startStep, jobErr)
+}
+
+// DoInstanceActionAWS is a job logic, when error is returned the job status is updated accordingly
+func DoInstanceActionAWS(ctx context.Context, args *InstanceActionAWSTaskArgs) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DoInstanceActionAWS")
+	defer span.End()
+
+	logger := zerolog.Ctx(ctx)
+	logger.Info().Interface("args", args).Msg("Processing instance action AWS job")
+
+	// status updates before and after the code logic
+	updateStatusBefore(ctx, args.ReservationID, fmt.Sprintf("Performing %s action", args.Action))
+	defer updateStatusAfter(ctx, args.ReservationID, fmt.Sprintf("Performed %s action", args.Action), 1)
+
+	ec2Client, err := clients.GetEC2Client(ctx, args.ARN, args.Region)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot create new ec2 client from config")
+		return fmt.Errorf("cannot create new ec2 client from config: %w", err)
+	}
+
+	switch args.Action {
+	case models.InstanceActionStart:
+		err = ec2Client.StartInstances(ctx, args.InstanceIDs)
+	case models.InstanceActionStop:
+		err = ec2Client.StopInstances(ctx, args.InstanceIDs)
+	case models.InstanceActionReboot:
+		err = ec2Client.RebootInstances(ctx, args.InstanceIDs)
+	case models.InstanceActionTerminate:
+		err = ec2Client.TerminateInstances(ctx, args.InstanceIDs)
+	case models.InstanceActionUnknown:
+		err = unknownInstanceActionError(args.Action)
+	default:
+		err = unknownInstanceActionError(args.Action)
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot perform instance action")
+		return fmt.Errorf("cannot %s instances: %w", args.Action, err)
+	}
+
+	if args.Action == models.InstanceActionTerminate {
+		for _, id := range args.InstanceIDs {
+			err = deleteTerminatedInstance(ctx, args.LaunchReservationID, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nilUnlessTimeout(ctx)
+}
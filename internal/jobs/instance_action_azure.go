@@ -0,0 +1,111 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type InstanceActionAzureTaskArgs struct {
+	// Associated reservation tracking the action
+	ReservationID int64
+
+	// Reservation which launched the instances, terminated instances are removed from it
+	LaunchReservationID int64
+
+	// Action to perform
+	Action models.InstanceAction
+
+	// Full Azure resource IDs of the virtual machines
+	InstanceIDs []string
+
+	// The Subscription fetched from Sources which is linked to a specific source
+	Subscription *clients.Authentication
+}
+
+// HandleInstanceActionAzure unmarshalls arguments and handles error
+func HandleInstanceActionAzure(ctx context.Context, job *worker.Job) (err error) {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msg("No job for HandleInstanceActionAzure")
+		return nil
+	}
+
+	args, ok := job.Args.(InstanceActionAzureTaskArgs)
+	if !ok {
+		err = fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	// context and logger
+	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
+	logger.Info().Msgf("Started instance action '%s' Azure job", args.Action)
+
+	// ensure panic finishes or retries the job
+	startStep := reservationStep(ctx, args.ReservationID)
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
+			err = failJob(ctx, args.ReservationID, startStep, panicErr)
+		}
+	}()
+
+	jobErr := DoInstanceActionAzure(ctx, &args)
+	return finishInstanceActionJob(ctx, args.ReservationID, startStep, jobErr)
+}
+
+// DoInstanceActionAzure is a job logic, when error is returned the job status is updated accordingly
+func DoInstanceActionAzure(ctx context.Context, args *InstanceActionAzureTaskArgs) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DoInstanceActionAzure")
+	defer span.End()
+
+	logger := zerolog.Ctx(ctx)
+	logger.Info().Interface("args", args).Msg("Processing instance action Azure job")
+
+	// status updates before and after the code logic
+	updateStatusBefore(ctx, args.ReservationID, fmt.Sprintf("Performing %s action", args.Action))
+	defer updateStatusAfter(ctx, args.ReservationID, fmt.Sprintf("Performed %s action", args.Action), 1)
+
+	azureClient, err := clients.GetAzureClient(ctx, args.Subscription)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot instantiate Azure client")
+		return fmt.Errorf("failed to instantiate Azure client: %w", err)
+	}
+
+	for _, id := range args.InstanceIDs {
+		switch args.Action {
+		case models.InstanceActionStart:
+			err = azureClient.StartVM(ctx, id)
+		case models.InstanceActionStop:
+			err = azureClient.DeallocateVM(ctx, id)
+		case models.InstanceActionReboot:
+			err = azureClient.RestartVM(ctx, id)
+		case models.InstanceActionTerminate:
+			err = azureClient.DeleteVM(ctx, id)
+		case models.InstanceActionUnknown:
+			err = unknownInstanceActionError(args.Action)
+		default:
+			err = unknownInstanceActionError(args.Action)
+		}
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot perform instance action")
+			return fmt.Errorf("cannot %s instance %s: %w", args.Action, id, err)
+		}
+
+		if args.Action == models.InstanceActionTerminate {
+			err = deleteTerminatedInstance(ctx, args.LaunchReservationID, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nilUnlessTimeout(ctx)
+}
@@ -0,0 +1,114 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type InstanceActionGCPTaskArgs struct {
+	// Associated reservation tracking the action
+	ReservationID int64
+
+	// Reservation which launched the instances, terminated instances are removed from it
+	LaunchReservationID int64
+
+	// Action to perform
+	Action models.InstanceAction
+
+	// Zone the instances were provisioned into
+	Zone string
+
+	// GCP instance IDs
+	InstanceIDs []string
+
+	// The project id from Sources which is linked to a specific source
+	ProjectID *clients.Authentication
+}
+
+// HandleInstanceActionGCP unmarshalls arguments and handles error
+func HandleInstanceActionGCP(ctx context.Context, job *worker.Job) (err error) {
+	logger := zerolog.Ctx(ctx)
+	if job == nil {
+		logger.Error().Msg("No job for HandleInstanceActionGCP")
+		return nil
+	}
+
+	args, ok := job.Args.(InstanceActionGCPTaskArgs)
+	if !ok {
+		err = fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		logger.Error().Err(err).Msg("Type assertion error for job")
+		return err
+	}
+
+	// context and logger
+	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
+	logger.Info().Msgf("Started instance action '%s' GCP job", args.Action)
+
+	// ensure panic finishes or retries the job
+	startStep := reservationStep(ctx, args.ReservationID)
+	defer func() {
+		if r := recover(); r != nil {
+			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
+			err = failJob(ctx, args.ReservationID, startStep, panicErr)
+		}
+	}()
+
+	jobErr := DoInstanceActionGCP(ctx, &args)
+	return finishInstanceActionJob(ctx, args.ReservationID, startStep, jobErr)
+}
+
+// DoInstanceActionGCP is a job logic, when error is returned the job status is updated accordingly
+func DoInstanceActionGCP(ctx context.Context, args *InstanceActionGCPTaskArgs) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "DoInstanceActionGCP")
+	defer span.End()
+
+	logger := zerolog.Ctx(ctx)
+	logger.Info().Interface("args", args).Msg("Processing instance action GCP job")
+
+	// status updates before and after the code logic
+	updateStatusBefore(ctx, args.ReservationID, fmt.Sprintf("Performing %s action", args.Action))
+	defer updateStatusAfter(ctx, args.ReservationID, fmt.Sprintf("Performed %s action", args.Action), 1)
+
+	gcpClient, err := clients.GetGCPClient(ctx, args.ProjectID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot create new gcp client")
+		return fmt.Errorf("cannot create new gcp client: %w", err)
+	}
+
+	for _, id := range args.InstanceIDs {
+		switch args.Action {
+		case models.InstanceActionStart:
+			err = gcpClient.StartInstance(ctx, id, args.Zone)
+		case models.InstanceActionStop:
+			err = gcpClient.StopInstance(ctx, id, args.Zone)
+		case models.InstanceActionReboot:
+			err = gcpClient.ResetInstance(ctx, id, args.Zone)
+		case models.InstanceActionTerminate:
+			err = gcpClient.DeleteInstance(ctx, id, args.Zone)
+		case models.InstanceActionUnknown:
+			err = unknownInstanceActionError(args.Action)
+		default:
+			err = unknownInstanceActionError(args.Action)
+		}
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot perform instance action")
+			return fmt.Errorf("cannot %s instance %s: %w", args.Action, id, err)
+		}
+
+		if args.Action == models.InstanceActionTerminate {
+			err = deleteTerminatedInstance(ctx, args.LaunchReservationID, id)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nilUnlessTimeout(ctx)
+}
@@ -0,0 +1,144 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareInstanceActionReservation(t *testing.T, provider models.ProviderType, action models.InstanceAction) *models.InstanceActionReservation {
+	t.Helper()
+
+	return &models.InstanceActionReservation{
+		Reservation: models.Reservation{
+			Provider:   provider,
+			AccountID:  1,
+			Status:     "Created",
+			Steps:      1,
+			StepTitles: jobs.InstanceActionSteps(action),
+		},
+	}
+}
+
+func TestDoInstanceActionAWS(t *testing.T) {
+	actions := []models.InstanceAction{
+		models.InstanceActionStart,
+		models.InstanceActionStop,
+		models.InstanceActionReboot,
+		models.InstanceActionTerminate,
+	}
+
+	for _, action := range actions {
+		t.Run(action.String(), func(t *testing.T) {
+			ctx := prepareEC2Context(t)
+
+			res := prepareInstanceActionReservation(t, models.ProviderTypeAWS, action)
+			err := dao.GetReservationDao(ctx).CreateInstanceAction(ctx, res)
+			require.NoError(t, err, "failed to add stubbed reservation")
+
+			args := &jobs.InstanceActionAWSTaskArgs{
+				ReservationID: res.ID,
+				Action:        action,
+				Region:        "us-east-1",
+				InstanceIDs:   []string{"i-0a4caa2cf5b097ce1", "i-0a4caa2cf5b097ce2"},
+				ARN:           &clients.Authentication{},
+			}
+
+			err = jobs.DoInstanceActionAWS(ctx, args)
+			require.NoError(t, err, "instance action failed to run")
+
+			assert.Equal(t, action.String(), clientStubs.StubLastInstanceActionEC2(ctx, "i-0a4caa2cf5b097ce1"))
+			assert.Equal(t, action.String(), clientStubs.StubLastInstanceActionEC2(ctx, "i-0a4caa2cf5b097ce2"))
+		})
+	}
+
+	t.Run("unknown action", func(t *testing.T) {
+		ctx := prepareEC2Context(t)
+
+		res := prepareInstanceActionReservation(t, models.ProviderTypeAWS, models.InstanceActionUnknown)
+		err := dao.GetReservationDao(ctx).CreateInstanceAction(ctx, res)
+		require.NoError(t, err, "failed to add stubbed reservation")
+
+		args := &jobs.InstanceActionAWSTaskArgs{
+			ReservationID: res.ID,
+			Action:        models.InstanceActionUnknown,
+			Region:        "us-east-1",
+			InstanceIDs:   []string{"i-0a4caa2cf5b097ce1"},
+			ARN:           &clients.Authentication{},
+		}
+
+		err = jobs.DoInstanceActionAWS(ctx, args)
+		require.ErrorIs(t, err, jobs.ErrUnknownInstanceAction)
+	})
+}
+
+func TestDoInstanceActionAzure(t *testing.T) {
+	ctx := prepareAzureContext(t)
+	azureClient, err := clients.GetAzureClient(ctx, &clients.Authentication{})
+	require.NoError(t, err)
+
+	vms, err := azureClient.CreateVMs(ctx, clients.AzureInstanceParams{Location: "useast"}, 1, "redhat-vm")
+	require.NoError(t, err, "failed to create stubbed VM")
+
+	res := prepareInstanceActionReservation(t, models.ProviderTypeAzure, models.InstanceActionStop)
+	err = dao.GetReservationDao(ctx).CreateInstanceAction(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	t.Run("stop", func(t *testing.T) {
+		args := &jobs.InstanceActionAzureTaskArgs{
+			ReservationID: res.ID,
+			Action:        models.InstanceActionStop,
+			InstanceIDs:   []string{vms[0].ID},
+			Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+		}
+
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.NoError(t, err, "instance action failed to run")
+
+		assert.Equal(t, "stop", clientStubs.StubLastAzureVMAction(ctx, vms[0].ID))
+	})
+
+	t.Run("terminate", func(t *testing.T) {
+		var launchReservationID int64 = 1000
+		rDao := dao.GetReservationDao(ctx)
+		err = rDao.CreateInstance(ctx, &models.ReservationInstance{
+			ReservationID: launchReservationID,
+			InstanceID:    vms[0].ID,
+		})
+		require.NoError(t, err, "failed to add stubbed instance")
+
+		args := &jobs.InstanceActionAzureTaskArgs{
+			ReservationID:       res.ID,
+			LaunchReservationID: launchReservationID,
+			Action:              models.InstanceActionTerminate,
+			InstanceIDs:         []string{vms[0].ID},
+			Subscription:        clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+		}
+
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.NoError(t, err, "instance action failed to run")
+
+		assert.Equal(t, "terminate", clientStubs.StubLastAzureVMAction(ctx, vms[0].ID))
+		instances, err := rDao.ListInstances(ctx, launchReservationID)
+		require.NoError(t, err, "failed to list instances")
+		assert.Empty(t, instances, "terminated instance was not removed")
+	})
+
+	t.Run("missing instance", func(t *testing.T) {
+		args := &jobs.InstanceActionAzureTaskArgs{
+			ReservationID: res.ID,
+			Action:        models.InstanceActionTerminate,
+			InstanceIDs:   []string{"missing"},
+			Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+		}
+
+		err = jobs.DoInstanceActionAzure(ctx, args)
+		require.ErrorIs(t, err, clients.ErrNotFound)
+	})
+}
@@ -7,4 +7,7 @@ const (
 	TypeLaunchInstanceAws   worker.JobType = "launch_instances_aws"
 	TypeLaunchInstanceAzure worker.JobType = "launch_instances_azure"
 	TypeLaunchInstanceGcp   worker.JobType = "launch_instances_gcp"
+	TypeInstanceActionAws   worker.JobType = "instance_action_aws"
+	TypeInstanceActionAzure worker.JobType = "instance_action_azure"
+	TypeInstanceActionGcp   worker.JobType = "instance_action_gcp"
 )
@@ -44,50 +44,92 @@ type LaunchInstanceAWSTaskArgs struct {
 }
 
 // HandleLaunchInstanceAWS unmarshalls arguments and handles error
-func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) {
+func HandleLaunchInstanceAWS(ctx context.Context, job *worker.Job) (err error) {
 	logger := zerolog.Ctx(ctx)
 	if job == nil {
 		logger.Error().Msg("No job for HandleLaunchInstanceAWS")
-		return
+		return nil
 	}
 
 	args, ok := job.Args.(LaunchInstanceAWSTaskArgs)
 	if !ok {
-		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		err = fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		logger.Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
 	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
 	logger.Info().Msg("Started launch instance AWS job")
 
-	// ensure panic finishes the job
+	// ensure panic finishes or retries the job
+	startStep := reservationStep(ctx, args.ReservationID)
 	defer func() {
 		if r := recover(); r != nil {
 			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
-			finishWithError(ctx, args.ReservationID, panicErr)
+			err = failJob(ctx, args.ReservationID, startStep, panicErr)
 		}
 	}()
 
+	// retried jobs must not launch instances for a finished reservation again
+	if isFinished(ctx, args.ReservationID) {
+		logger.Warn().Msg("Reservation is already finished, skipping the job")
+		return nil
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAWS(&args))
+		return nil
+	}
+
 	jobErr := DoEnsurePubkeyOnAWS(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAWS(&args))
+		return nil
 	}
 
 	jobErr = DoLaunchInstanceAWS(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAWS(&args))
+		return nil
 	}
 
 	jobErr = FetchInstancesDescriptionAWS(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAWS(&args))
+		return nil
+	}
+
+	finishJob(ctx, args.ReservationID, nil, terminateInstancesAWS(&args))
+	return nil
+}
+
+// terminateInstancesAWS returns a function which terminates instances when a reservation is cancelled
+func terminateInstancesAWS(args *LaunchInstanceAWSTaskArgs) func(ctx context.Context, instanceIds []string) error {
+	return func(ctx context.Context, instanceIds []string) error {
+		ec2Client, err := clients.GetEC2Client(ctx, args.ARN, args.Region)
+		if err != nil {
+			return fmt.Errorf("cannot create new ec2 client from config: %w", err)
+		}
+
+		err = ec2Client.TerminateInstances(ctx, instanceIds)
+		if err != nil {
+			return fmt.Errorf("cannot terminate instances: %w", err)
+		}
+		return nil
+	}
 }
 
 // DoEnsurePubkeyOnAWS is a job logic, when error is returned the job status is updated accordingly
@@ -196,6 +238,12 @@ func DoEnsurePubkeyOnAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		}
 	}
 
+	err = pkDao.UnscopedVerifyResource(ctx, pkr.ID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot verify resource for aws pubkey")
+		return fmt.Errorf("cannot verify resource for aws pubkey: %w", err)
+	}
+
 	return nilUnlessTimeout(ctx)
 }
 
@@ -225,6 +273,7 @@ func DoLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 		Type:         models.ProviderTypeAWS,
 		PowerOff:     args.Detail.PowerOff,
 		InsightsTags: true,
+		Custom:       args.Detail.UserData,
 	}
 	userData, err := userdata.GenerateUserData(ctx, &userDataInput)
 	if err != nil {
@@ -239,39 +288,74 @@ func DoLaunchInstanceAWS(ctx context.Context, args *LaunchInstanceAWSTaskArgs) e
 	}
 
 	req := &clients.AWSInstanceParams{
-		LaunchTemplateID: args.LaunchTemplateID,
-		InstanceType:     types.InstanceType(args.Detail.InstanceType),
-		AMI:              args.AMI,
-		KeyName:          reservation.Detail.PubkeyName,
-		UserData:         userData,
+		LaunchTemplateID:  args.LaunchTemplateID,
+		InstanceType:      types.InstanceType(args.Detail.InstanceType),
+		AMI:               args.AMI,
+		KeyName:           reservation.Detail.PubkeyName,
+		UserData:          userData,
+		SubnetID:          args.Detail.SubnetID,
+		SecurityGroupIDs:  args.Detail.SecurityGroupIDs,
+		AssociatePublicIP: args.Detail.AssociatePublicIP,
+		Volumes:           args.Detail.Volumes,
+		Tags:              args.Detail.Tags,
+		Spot:              args.Detail.Spot,
 	}
 
-	logger.Trace().Msg("Executing RunInstances")
-	instances, awsReservationId, err := ec2Client.RunInstances(ctx, req, args.Detail.Amount, args.Detail.Name, reservation)
+	// instances launched by previous attempts of a retried job are not launched again
+	launched, err := resD.ListInstances(ctx, args.ReservationID)
 	if err != nil {
-		span.SetStatus(codes.Error, "cannot run instances")
-		return fmt.Errorf("cannot run instances: %w", err)
+		span.SetStatus(codes.Error, "cannot list launched instances")
+		return fmt.Errorf("cannot list launched instances: %w", err)
 	}
 
-	// For each instance that was created in AWS, add it as a DB record
-	for _, instanceId := range instances {
-		err = resD.CreateInstance(ctx, &models.ReservationInstance{
-			ReservationID: args.ReservationID,
-			InstanceID:    *instanceId,
-		})
+	// Instances are launched with one request per placement zone, the first AWS reservation ID is stored
+	var awsReservationId *string
+	for _, placement := range args.Detail.Placement.Distribute(int64(args.Detail.Amount)) {
+		req.Zone = placement.Zone
+		amount := placement.Amount - launchedInZone(launched, placement.Zone)
+		if amount <= 0 {
+			logger.Info().Str("zone", placement.Zone).Msg("Instances were already launched by a previous attempt")
+			continue
+		}
+
+		logger.Trace().Str("zone", placement.Zone).Msg("Executing RunInstances")
+		var instances []*string
+		var zoneReservationId *string
+		instances, zoneReservationId, err = ec2Client.RunInstances(ctx, req, int32(amount), args.Detail.Name, reservation)
 		if err != nil {
-			span.SetStatus(codes.Error, "cannot create instance reservation")
-			return fmt.Errorf("cannot create instance reservation for id %d: %w", instanceId, err)
+			span.SetStatus(codes.Error, "cannot run instances")
+			return fmt.Errorf("cannot run instances: %w", err)
+		}
+		if awsReservationId == nil {
+			awsReservationId = zoneReservationId
+		}
+
+		// For each instance that was created in AWS, add it as a DB record
+		for _, instanceId := range instances {
+			err = resD.CreateInstance(ctx, &models.ReservationInstance{
+				ReservationID: args.ReservationID,
+				InstanceID:    *instanceId,
+				Detail: models.ReservationInstanceDetail{
+					Zone: placement.Zone,
+				},
+			})
+			if err != nil {
+				span.SetStatus(codes.Error, "cannot create instance reservation")
+				return fmt.Errorf("cannot create instance reservation for id %d: %w", instanceId, err)
+			}
+			logger.Info().Str("instance_id", *instanceId).Msgf("Created new instance via AWS reservation %s", *zoneReservationId)
 		}
-		logger.Info().Str("instance_id", *instanceId).Msgf("Created new instance via AWS reservation %s", *awsReservationId)
 	}
 
-	logger.Info().Str("aws_reservation_id", *awsReservationId).Msg("Adding aws reservation id")
-	// Save the AWS reservation id in aws_reservation_details table
-	err = resD.UpdateReservationIDForAWS(ctx, args.ReservationID, *awsReservationId)
-	if err != nil {
-		span.SetStatus(codes.Error, "cannot UpdateReservationIDForAWS")
-		return fmt.Errorf("cannot UpdateReservationIDForAWS: %w", err)
+	// the reservation id was stored by the previous attempt when no instances were launched
+	if awsReservationId != nil {
+		logger.Info().Str("aws_reservation_id", *awsReservationId).Msg("Adding aws reservation id")
+		// Save the AWS reservation id in aws_reservation_details table
+		err = resD.UpdateReservationIDForAWS(ctx, args.ReservationID, *awsReservationId)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot UpdateReservationIDForAWS")
+			return fmt.Errorf("cannot UpdateReservationIDForAWS: %w", err)
+		}
 	}
 
 	return nilUnlessTimeout(ctx)
@@ -13,6 +13,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -152,3 +153,79 @@ func TestHandleEnsurePubkeyOnAWS(t *testing.T) {
 		assert.Equal(t, 1, len(pkrList))
 	})
 }
+
+func TestHandleLaunchInstanceAWSCancelled(t *testing.T) {
+	ctx := prepareEC2Context(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAWSReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAWS(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	err = rDao.CreateInstance(ctx, &models.ReservationInstance{ReservationID: res.ID, InstanceID: "i-0a4caa2cf5b097ce1"})
+	require.NoError(t, err, "failed to add stubbed instance")
+
+	err = rDao.Cancel(ctx, res.ID)
+	require.NoError(t, err, "failed to cancel reservation")
+
+	job := &worker.Job{
+		Type: jobs.TypeLaunchInstanceAws,
+		Args: jobs.LaunchInstanceAWSTaskArgs{
+			ReservationID: res.ID,
+			Region:        res.Detail.Region,
+			PubkeyID:      pk.ID,
+			Detail:        res.Detail,
+			AMI:           "ami-xxxxx",
+			ARN:           &clients.Authentication{},
+		},
+	}
+	jobs.HandleLaunchInstanceAWS(ctx, job)
+
+	assert.Equal(t, "terminate", clientStubs.StubLastInstanceActionEC2(ctx, "i-0a4caa2cf5b097ce1"))
+	result, err := rDao.GetById(ctx, res.ID)
+	require.NoError(t, err)
+	assert.False(t, result.Success.Bool)
+	assert.Equal(t, jobs.ErrReservationCancelled.Error(), result.Error)
+}
+
+func TestHandleLaunchInstanceAWSFinished(t *testing.T) {
+	ctx := prepareEC2Context(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAWSReservation(t, ctx, pk)
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAWS(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	err = rDao.FinishWithSuccess(ctx, res.ID)
+	require.NoError(t, err, "failed to finish reservation")
+
+	// redelivered job of a finished reservation
+	job := &worker.Job{
+		Type:    jobs.TypeLaunchInstanceAws,
+		Attempt: 1,
+		Args: jobs.LaunchInstanceAWSTaskArgs{
+			ReservationID: res.ID,
+			Region:        res.Detail.Region,
+			PubkeyID:      pk.ID,
+			Detail:        res.Detail,
+			AMI:           "ami-xxxxx",
+			ARN:           &clients.Authentication{},
+		},
+	}
+	jobs.HandleLaunchInstanceAWS(ctx, job)
+
+	instances, err := rDao.ListInstances(ctx, res.ID)
+	require.NoError(t, err)
+	assert.Empty(t, instances, "Instances were launched for a finished reservation")
+	result, err := rDao.GetById(ctx, res.ID)
+	require.NoError(t, err)
+	assert.True(t, result.Success.Bool)
+}
@@ -2,13 +2,16 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
@@ -21,17 +24,16 @@ import (
 
 const (
 	DefaultAzureResourceGroupName = "redhat-deployed"
-	location                      = "eastus"
 	vmNamePrefix                  = "redhat-vm"
 )
 
-var LaunchInstanceAzureSteps = []string{"Prepare resource group", "Launch instance(s)"}
+var LaunchInstanceAzureSteps = []string{"Prepare resource group", "Launch instance(s)", "Fetch instance(s) description"}
 
 type LaunchInstanceAzureTaskArgs struct {
 	// Associated reservation
 	ReservationID int64
 
-	// Location to provision the instances into
+	// Location to provision the instances into, optionally with availability zone suffix (e.g. eastus_1)
 	Location string
 
 	// Associated public key
@@ -50,18 +52,24 @@ type LaunchInstanceAzureTaskArgs struct {
 	Subscription *clients.Authentication
 }
 
-func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) {
+// region returns the Azure region of the location without the availability zone suffix
+func (args *LaunchInstanceAzureTaskArgs) region() string {
+	region, _, _ := strings.Cut(args.Location, "_")
+	return region
+}
+
+func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) (err error) {
 	logger := zerolog.Ctx(ctx)
 	if job == nil {
 		logger.Error().Msg("No job for HandleLaunchInstanceAzure")
-		return
+		return nil
 	}
 
 	args, ok := job.Args.(LaunchInstanceAzureTaskArgs)
 	if !ok {
-		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		err = fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		logger.Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
@@ -73,30 +81,79 @@ func HandleLaunchInstanceAzure(ctx context.Context, job *worker.Job) {
 		args.ResourceGroupName = DefaultAzureResourceGroupName
 	}
 
-	// ensure panic finishes the job
+	// ensure panic finishes or retries the job
+	startStep := reservationStep(ctx, args.ReservationID)
 	defer func() {
 		if r := recover(); r != nil {
 			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
-			finishWithError(ctx, args.ReservationID, panicErr)
+			err = failJob(ctx, args.ReservationID, startStep, panicErr)
 		}
 	}()
 
 	ctx, span := otel.Tracer(TraceName).Start(ctx, "LaunchInstanceAzureJob")
 	defer span.End()
 
+	// retried jobs must not launch instances for a finished reservation again
+	if isFinished(ctx, args.ReservationID) {
+		logger.Warn().Msg("Reservation is already finished, skipping the job")
+		return nil
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAzure(&args))
+		return nil
+	}
+
 	jobErr := DoEnsureAzureResourceGroup(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAzure(&args))
+		return nil
 	}
 
 	jobErr = DoLaunchInstanceAzure(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAzure(&args))
+		return nil
+	}
+
+	jobErr = FetchInstancesDescriptionAzure(ctx, &args)
+	if jobErr != nil {
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesAzure(&args))
+		return nil
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	finishJob(ctx, args.ReservationID, nil, terminateInstancesAzure(&args))
+	return nil
+}
+
+// terminateInstancesAzure returns a function which deletes VMs when a reservation is cancelled
+func terminateInstancesAzure(args *LaunchInstanceAzureTaskArgs) func(ctx context.Context, instanceIds []string) error {
+	return func(ctx context.Context, instanceIds []string) error {
+		azureClient, err := clients.GetAzureClient(ctx, args.Subscription)
+		if err != nil {
+			return fmt.Errorf("failed to instantiate Azure client: %w", err)
+		}
+
+		for _, id := range instanceIds {
+			err = azureClient.DeleteVM(ctx, id)
+			if err != nil {
+				return fmt.Errorf("cannot delete VM %s: %w", id, err)
+			}
+		}
+		return nil
+	}
 }
 
 func DoEnsureAzureResourceGroup(ctx context.Context, args *LaunchInstanceAzureTaskArgs) error {
@@ -116,7 +173,7 @@ func DoEnsureAzureResourceGroup(ctx context.Context, args *LaunchInstanceAzureTa
 		return fmt.Errorf("cannot create new Azure client: %w", err)
 	}
 
-	resourceGroupID, err := azureClient.EnsureResourceGroup(ctx, args.ResourceGroupName, location)
+	resourceGroupID, err := azureClient.EnsureResourceGroup(ctx, args.ResourceGroupName, args.region())
 	if err != nil {
 		span.SetStatus(codes.Error, "cannot create resource group")
 		logger.Error().Err(err).Msg("Cannot create resource group")
@@ -155,11 +212,18 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 		span.SetStatus(codes.Error, "cannot instantiate Azure client")
 		return fmt.Errorf("failed to instantiate Azure client: %w", err)
 	}
+	// the key is passed inline too, so the resource is only a convenience for users
+	err = ensurePubkeyOnAzure(ctx, azureClient, pubkey, args)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Int64("pubkey_id", pubkey.ID).Msg("Unable to upload SSH public key resource")
+	}
+
 	// Generate user data
 	userDataInput := userdata.UserData{
 		Type:         models.ProviderTypeAzure,
 		PowerOff:     reservation.Detail.PowerOff,
 		InsightsTags: true,
+		Custom:       reservation.Detail.UserData,
 	}
 	userData, err := userdata.GenerateUserData(ctx, &userDataInput)
 	if err != nil {
@@ -168,36 +232,183 @@ func DoLaunchInstanceAzure(ctx context.Context, args *LaunchInstanceAzureTaskArg
 	}
 
 	vmParams := clients.AzureInstanceParams{
-		Location:          location,
+		Location:          args.region(),
 		ResourceGroupName: args.ResourceGroupName,
 		ImageID:           args.AzureImageID,
 		Pubkey:            pubkey,
 		InstanceType:      clients.InstanceTypeName(reservation.Detail.InstanceSize),
 		UserData:          userData,
+		Volumes:           reservation.Detail.Volumes,
+		Spot:              reservation.Detail.Spot,
+		LaunchTemplateID:  reservation.Detail.LaunchTemplateID,
+		NetworkID:         reservation.Detail.NetworkID,
+		SubnetID:          reservation.Detail.SubnetID,
+		SecurityGroupID:   reservation.Detail.SecurityGroupID,
 		Tags: map[string]*string{
 			"rh-rid": ptr.To(config.EnvironmentPrefix("r", strconv.FormatInt(reservation.ID, 10))),
 			"rh-org": ptr.To(identity.Identity(ctx).Identity.OrgID),
 		},
 	}
+	for key, value := range reservation.Detail.Tags {
+		vmParams.Tags[key] = ptr.To(value)
+	}
+
+	// instances launched by previous attempts of a retried job are not launched again
+	launched, err := resDao.ListInstances(ctx, args.ReservationID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot list launched instances")
+		return fmt.Errorf("cannot list launched instances: %w", err)
+	}
+
+	for _, placement := range reservation.Detail.Placement.Distribute(reservation.Detail.Amount) {
+		vmParams.Zone = placement.Zone
+		amount := placement.Amount - launchedInZone(launched, placement.Zone)
+		if amount <= 0 {
+			zerolog.Ctx(ctx).Info().Str("zone", placement.Zone).Msg("Instances were already launched by a previous attempt")
+			continue
+		}
+
+		var instanceDescriptions []clients.InstanceDescription
+		instanceDescriptions, err = azureClient.CreateVMs(ctx, vmParams, amount, vmNamePrefix)
+		if err != nil {
+			span.SetStatus(codes.Error, "failed to create instances")
+			return fmt.Errorf("cannot create Azure instance: %w", err)
+		}
+
+		for _, instanceDescription := range instanceDescriptions {
+			err = resDao.CreateInstance(ctx, &models.ReservationInstance{
+				ReservationID: args.ReservationID,
+				InstanceID:    instanceDescription.ID,
+				Detail: models.ReservationInstanceDetail{
+					PublicIPv4:  instanceDescription.IPv4,
+					PrivateIPv4: instanceDescription.PrivateIPv4,
+					Zone:        placement.Zone,
+				},
+			})
+			if err != nil {
+				span.SetStatus(codes.Error, "failed to save instance to DB")
+				return fmt.Errorf("cannot create instance reservation for id %s: %w", instanceDescription.ID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ensurePubkeyOnAzure uploads the pubkey as an SSH public key resource into the resource group
+// unless it was already uploaded there and records it as a pubkey resource.
+func ensurePubkeyOnAzure(ctx context.Context, azureClient clients.Azure, pubkey *models.Pubkey, args *LaunchInstanceAzureTaskArgs) error {
+	logger := zerolog.Ctx(ctx)
+
+	// Azure SSH public key resources only accept RSA keys
+	if pubkey.Type != "ssh-rsa" {
+		logger.Debug().Msgf("Skipping upload of SSH public key resource of type %s", pubkey.Type)
+		return nil
+	}
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	pkr, err := pkDao.UnscopedGetResourceBySourceAndRegion(ctx, pubkey.ID, args.SourceID, args.region())
+	if errors.Is(err, dao.ErrNoRows) {
+		pkr = &models.PubkeyResource{
+			PubkeyID: pubkey.ID,
+			Provider: models.ProviderTypeAzure,
+			SourceID: args.SourceID,
+			Region:   args.region(),
+		}
+	} else if err != nil {
+		return fmt.Errorf("unable to check pubkey resource: %w", err)
+	}
+
+	if pkr.ID != 0 {
+		_, err = azureClient.GetSSHKey(ctx, pkr.Handle)
+		if err == nil {
+			return nil
+		} else if !errors.Is(err, http.ErrPubkeyNotFound) {
+			return fmt.Errorf("cannot get SSH public key resource: %w", err)
+		}
+		logger.Debug().Msgf("SSH public key resource %s was deleted, uploading again", pkr.Handle)
+	} else {
+		pkr.RandomizeTag()
+	}
+
+	pkr.Handle, err = azureClient.ImportSSHKey(ctx, args.ResourceGroupName, args.region(), pubkey, pkr.FormattedTag())
+	if err != nil {
+		return fmt.Errorf("cannot upload SSH public key resource: %w", err)
+	}
+
+	if pkr.ID == 0 {
+		err = pkDao.UnscopedCreateResource(ctx, pkr)
+	} else {
+		err = pkDao.UnscopedUpdateResource(ctx, pkr)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot save pubkey resource: %w", err)
+	}
+
+	err = pkDao.UnscopedVerifyResource(ctx, pkr.ID)
+	if err != nil {
+		return fmt.Errorf("cannot verify pubkey resource: %w", err)
+	}
+
+	return nil
+}
+
+// FetchInstancesDescriptionAzure waits until public IP and DNS name of launched instances are assigned
+// and stores them. Addresses returned by CreateVMs are often empty because the VM has not finished
+// creating yet.
+func FetchInstancesDescriptionAzure(ctx context.Context, args *LaunchInstanceAzureTaskArgs) error {
+	ctx, span := otel.Tracer(TraceName).Start(ctx, "FetchInstancesDescriptionAzure")
+	defer span.End()
+
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msg("Started fetch instances description")
+
+	updateStatusBefore(ctx, args.ReservationID, "Fetching instance(s) description")
+	defer updateStatusAfter(ctx, args.ReservationID, "Instance(s) description fetched", 1)
+
+	rDao := dao.GetReservationDao(ctx)
+	instances, err := rDao.ListInstances(ctx, args.ReservationID)
+	if err != nil {
+		span.SetStatus(codes.Error, "cannot get instances list")
+		return fmt.Errorf("cannot get instances list: %w", err)
+	}
 
-	instanceDescriptions, err := azureClient.CreateVMs(ctx, vmParams, reservation.Detail.Amount, vmNamePrefix)
+	azureClient, err := clients.GetAzureClient(ctx, args.Subscription)
 	if err != nil {
-		span.SetStatus(codes.Error, "failed to create instances")
-		return fmt.Errorf("cannot create Azure instance: %w", err)
-	}
-
-	for _, instanceDescription := range instanceDescriptions {
-		err = resDao.CreateInstance(ctx, &models.ReservationInstance{
-			ReservationID: args.ReservationID,
-			InstanceID:    instanceDescription.ID,
-			Detail: models.ReservationInstanceDetail{
-				PublicIPv4:  instanceDescription.IPv4,
-				PrivateIPv4: instanceDescription.PrivateIPv4,
-			},
-		})
+		span.SetStatus(codes.Error, "cannot instantiate Azure client")
+		return fmt.Errorf("failed to instantiate Azure client: %w", err)
+	}
+
+	for _, instance := range instances {
+		var instanceDesc *clients.InstanceDescription
+		err = waitAndRetry(ctx, func() error {
+			description, errRetry := azureClient.DescribeVM(ctx, instance.InstanceID)
+			if errRetry != nil {
+				return fmt.Errorf("cannot get instance description: %w", errRetry)
+			}
+			instanceDesc = description
+
+			if instanceDesc.IPv4 == "" || instanceDesc.DNS == "" {
+				return ErrTryAgain
+			}
+
+			return nil
+		}, 1, 500, 500, 1000, 2000, 2000)
+
+		if instanceDesc == nil {
+			logger.Error().Err(err).Str("instance_id", instance.InstanceID).Msg("Cannot get instance description, skipping")
+
+			// try to get the others
+			continue
+		} else if err != nil {
+			// VMs launched from templates might have no DNS name, store what was assigned
+			logger.Warn().Err(err).Str("instance_id", instance.InstanceID).Msg("Instance description is not complete")
+		}
+
+		err = rDao.UpdateReservationInstance(ctx, args.ReservationID, instanceDesc)
 		if err != nil {
-			span.SetStatus(codes.Error, "failed to save instance to DB")
-			return fmt.Errorf("cannot create instance reservation for id %s: %w", instanceDescription.ID, err)
+			span.SetStatus(codes.Error, "cannot update instance description")
+			return fmt.Errorf("cannot update instance description: %w", err)
 		}
 	}
 
@@ -2,6 +2,7 @@ package jobs_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
@@ -46,7 +47,7 @@ func prepareAzureReservation(t *testing.T, ctx context.Context, pk *models.Pubke
 	reservation.AccountID = 1
 	reservation.Status = "Created"
 	reservation.Provider = models.ProviderTypeAzure
-	reservation.Steps = 2
+	reservation.Steps = 3
 	return reservation
 }
 
@@ -65,7 +66,7 @@ func TestDoEnsureAzureResourceGroup(t *testing.T) {
 
 	args := &jobs.LaunchInstanceAzureTaskArgs{
 		AzureImageID:      "/subscriptions/subUUID/rgName/images/uuid2",
-		Location:          "useast",
+		Location:          "westeurope_1",
 		PubkeyID:          pk.ID,
 		ReservationID:     res.ID,
 		SourceID:          "2",
@@ -77,6 +78,7 @@ func TestDoEnsureAzureResourceGroup(t *testing.T) {
 	require.NoError(t, err, "the ensure resource group failed to run")
 
 	assert.True(t, clientStubs.DidCreateAzureResourceGroup(ctx, "testGroup"))
+	assert.Equal(t, "westeurope", clientStubs.StubAzureResourceGroupLocation(ctx, "testGroup"))
 }
 
 func TestDoLaunchInstanceAzure(t *testing.T) {
@@ -95,7 +97,7 @@ func TestDoLaunchInstanceAzure(t *testing.T) {
 
 	args := &jobs.LaunchInstanceAzureTaskArgs{
 		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
-		Location:      "useast",
+		Location:      "westeurope_1",
 		PubkeyID:      pk.ID,
 		ReservationID: res.ID,
 		SourceID:      "2",
@@ -110,4 +112,135 @@ func TestDoLaunchInstanceAzure(t *testing.T) {
 	require.NoError(t, err, "failed to fetch created instances")
 	assert.Equal(t, 2, len(resultInstances))
 	assert.NotEmpty(t, resultInstances[0].Detail.PublicIPv4)
+
+	assert.Equal(t, 1, clientStubs.CountStubAzureSSHKeys(ctx))
+	pkr, err := dao.GetPubkeyDao(ctx).UnscopedGetResourceBySourceAndRegion(ctx, pk.ID, "2", "westeurope")
+	require.NoError(t, err, "pubkey resource was not created")
+	assert.Equal(t, models.ProviderTypeAzure, pkr.Provider)
+	assert.Contains(t, pkr.Handle, "/sshPublicKeys/"+pkr.FormattedTag())
+}
+
+func TestDoLaunchInstanceAzureSpread(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	res.Detail.Amount = 5
+	res.Detail.Placement = &models.Placement{
+		Strategy: models.PlacementStrategySpread,
+		Zones:    []string{"1", "2", "3"},
+	}
+
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      "useast",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      "2",
+		Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+	}
+
+	err = jobs.DoLaunchInstanceAzure(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+
+	assert.Equal(t, 5, clientStubs.CountStubAzureVMs(ctx))
+	resultInstances, err := rDao.ListInstances(ctx, res.ID)
+	require.NoError(t, err, "failed to fetch created instances")
+	zones := make(map[string]int)
+	for _, instance := range resultInstances {
+		zones[instance.Detail.Zone]++
+	}
+	assert.Equal(t, map[string]int{"1": 2, "2": 2, "3": 1}, zones)
+}
+
+func TestDoLaunchInstanceAzureRetried(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+	res.Detail.Amount = 5
+	res.Detail.Placement = &models.Placement{
+		Strategy: models.PlacementStrategySpread,
+		Zones:    []string{"1", "2", "3"},
+	}
+
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	// previous attempt launched instances in the first zone only
+	for i := 0; i < 2; i++ {
+		err = rDao.CreateInstance(ctx, &models.ReservationInstance{
+			ReservationID: res.ID,
+			InstanceID:    fmt.Sprintf("/subscriptions/subUUID/resourceGroups/redhat-deployed/providers/Microsoft.Compute/virtualMachines/previous-%d", i),
+			Detail:        models.ReservationInstanceDetail{Zone: "1"},
+		})
+		require.NoError(t, err, "failed to add stubbed instance")
+	}
+
+	args := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      "useast",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      "2",
+		Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+	}
+
+	err = jobs.DoLaunchInstanceAzure(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+
+	assert.Equal(t, 3, clientStubs.CountStubAzureVMs(ctx))
+	resultInstances, err := rDao.ListInstances(ctx, res.ID)
+	require.NoError(t, err, "failed to fetch created instances")
+	zones := make(map[string]int)
+	for _, instance := range resultInstances {
+		zones[instance.Detail.Zone]++
+	}
+	assert.Equal(t, map[string]int{"1": 2, "2": 2, "3": 1}, zones)
+}
+
+func TestFetchInstancesDescriptionAzure(t *testing.T) {
+	ctx := prepareAzureContext(t)
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareAzureReservation(t, ctx, pk)
+
+	rDao := dao.GetReservationDao(ctx)
+	err = rDao.CreateAzure(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceAzureTaskArgs{
+		AzureImageID:  "/subscriptions/subUUID/rgName/images/uuid2",
+		Location:      "useast",
+		PubkeyID:      pk.ID,
+		ReservationID: res.ID,
+		SourceID:      "2",
+		Subscription:  clients.NewAuthentication("subUUID", models.ProviderTypeAzure),
+	}
+
+	err = jobs.DoLaunchInstanceAzure(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+
+	err = jobs.FetchInstancesDescriptionAzure(ctx, args)
+	require.NoError(t, err, "fetch instances description failed to run")
+
+	resultInstances, err := rDao.ListInstances(ctx, res.ID)
+	require.NoError(t, err, "failed to fetch created instances")
+	require.Equal(t, 1, len(resultInstances))
+	assert.NotEmpty(t, resultInstances[0].Detail.PublicIPv4)
+	assert.Contains(t, resultInstances[0].Detail.PublicDNS, ".cloudapp.azure.com")
 }
@@ -2,13 +2,14 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
-	_ "github.com/RHEnVision/provisioning-backend/internal/clients/http/gcp"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/userdata"
@@ -28,6 +29,9 @@ type LaunchInstanceGCPTaskArgs struct {
 	// Associated public key
 	PubkeyID int64
 
+	// SourceID that was used to get the project id
+	SourceID string
+
 	// Detail information
 	Detail *models.GCPDetail
 
@@ -42,44 +46,97 @@ type LaunchInstanceGCPTaskArgs struct {
 }
 
 // HandleLaunchInstanceGCP unmarshalls arguments and handles error
-func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) {
+func HandleLaunchInstanceGCP(ctx context.Context, job *worker.Job) (err error) {
 	logger := zerolog.Ctx(ctx)
 	if job == nil {
 		logger.Error().Msg("No job for HandleLaunchInstanceGCP")
-		return
+		return nil
 	}
 	args, ok := job.Args.(LaunchInstanceGCPTaskArgs)
 	if !ok {
-		err := fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
+		err = fmt.Errorf("%w: job %s, reservation: %#v", ErrTypeAssertion, job.ID, job.Args)
 		logger.Error().Err(err).Msg("Type assertion error for job")
-		return
+		return err
 	}
 
 	// context and logger
 	ctx, logger = reservationContextLogger(ctx, args.ReservationID)
 	logger.Info().Msg("Started launch instance GCP job")
 
-	// ensure panic finishes the job
+	// ensure panic finishes or retries the job
+	startStep := reservationStep(ctx, args.ReservationID)
 	defer func() {
 		if r := recover(); r != nil {
 			panicErr := fmt.Errorf("%w: %s", ErrPanicInJob, r)
-			finishWithError(ctx, args.ReservationID, panicErr)
+			err = failJob(ctx, args.ReservationID, startStep, panicErr)
 		}
 	}()
 
+	// retried jobs must not launch instances for a finished reservation again
+	if isFinished(ctx, args.ReservationID) {
+		logger.Warn().Msg("Reservation is already finished, skipping the job")
+		return nil
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesGCP(&args))
+		return nil
+	}
+
 	jobErr := DoLaunchInstanceGCP(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesGCP(&args))
+		return nil
 	}
 
 	jobErr = FetchInstancesDescriptionGCP(ctx, &args)
 	if jobErr != nil {
-		finishWithError(ctx, args.ReservationID, jobErr)
-		return
+		return failJob(ctx, args.ReservationID, startStep, jobErr)
+	}
+
+	if isCancelled(ctx, args.ReservationID) {
+		finishCancelled(ctx, args.ReservationID, terminateInstancesGCP(&args))
+		return nil
 	}
 
-	finishJob(ctx, args.ReservationID, jobErr)
+	finishJob(ctx, args.ReservationID, nil, terminateInstancesGCP(&args))
+	return nil
+}
+
+// terminateInstancesGCP returns a function which deletes instances when a reservation is cancelled
+func terminateInstancesGCP(args *LaunchInstanceGCPTaskArgs) func(ctx context.Context, instanceIds []string) error {
+	return func(ctx context.Context, instanceIds []string) error {
+		gcpClient, err := clients.GetGCPClient(ctx, args.ProjectID)
+		if err != nil {
+			return fmt.Errorf("cannot create new gcp client: %w", err)
+		}
+
+		// instances spread across zones have their zone recorded
+		zones := make(map[string]string, len(instanceIds))
+		instances, err := dao.GetReservationDao(ctx).ListInstances(ctx, args.ReservationID)
+		if err != nil {
+			return fmt.Errorf("cannot list instances: %w", err)
+		}
+		for _, instance := range instances {
+			zones[instance.InstanceID] = instance.ZoneOrDefault(args.Zone)
+		}
+
+		for _, id := range instanceIds {
+			zone, ok := zones[id]
+			if !ok {
+				zone = args.Zone
+			}
+			err = gcpClient.DeleteInstance(ctx, id, zone)
+			if err != nil {
+				return fmt.Errorf("cannot delete instance %s: %w", id, err)
+			}
+		}
+		return nil
+	}
 }
 
 // DoLaunchInstanceGCP is a job logic, when error is returned the job status is updated accordingly
@@ -113,6 +170,7 @@ func DoLaunchInstanceGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) e
 		Type:         models.ProviderTypeGCP,
 		PowerOff:     args.Detail.PowerOff,
 		InsightsTags: true,
+		Custom:       args.Detail.UserData,
 	}
 	userData, err := userdata.GenerateUserData(ctx, &userDataInput)
 	if err != nil {
@@ -120,43 +178,147 @@ func DoLaunchInstanceGCP(ctx context.Context, args *LaunchInstanceGCPTaskArgs) e
 		return fmt.Errorf("cannot generate user data: %w", err)
 	}
 
+	// keys uploaded into project metadata are not passed into instance metadata
+	keyBody := pk.Body
+	if config.GCP.ProjectKeys {
+		err = ensurePubkeyOnGCP(ctx, gcpClient, pk, args)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot upload pubkey into project metadata")
+			return err
+		}
+		keyBody = ""
+	}
+
 	params := &clients.GCPInstanceParams{
 		NamePattern:      args.Detail.NamePattern,
 		ImageName:        args.ImageName,
 		MachineType:      args.Detail.MachineType,
 		Zone:             args.Zone,
-		KeyBody:          pk.Body,
+		KeyBody:          keyBody,
 		StartupScript:    string(userData),
 		ReservationID:    args.ReservationID,
 		UUID:             args.Detail.UUID,
 		LaunchTemplateID: args.LaunchTemplateID,
+		Volumes:          args.Detail.Volumes,
+		Labels:           args.Detail.Labels,
+		Spot:             args.Detail.Spot,
+		NetworkID:        args.Detail.NetworkID,
+		SubnetID:         args.Detail.SubnetID,
+		ExternalIP:       args.Detail.ExternalIP,
 	}
 
-	instances, opName, err := gcpClient.InsertInstances(ctx, params, args.Detail.Amount)
+	rDao := dao.GetReservationDao(ctx)
+
+	// instances launched by previous attempts of a retried job are not launched again
+	launched, err := rDao.ListInstances(ctx, args.ReservationID)
 	if err != nil {
-		span.SetStatus(codes.Error, "cannot run instances for gcp client")
-		return fmt.Errorf("cannot run instances for gcp client: %w", err)
+		span.SetStatus(codes.Error, "cannot list launched instances")
+		return fmt.Errorf("cannot list launched instances: %w", err)
 	}
 
-	rDao := dao.GetReservationDao(ctx)
+	// Instances are inserted with one bulk request per placement zone, the instances are found
+	// by the reservation label so instances from previous zones and attempts must be skipped
+	var opName *string
+	created := make(map[string]struct{})
+	for _, instance := range launched {
+		created[instance.InstanceID] = struct{}{}
+	}
+	for _, placement := range args.Detail.Placement.Distribute(args.Detail.Amount) {
+		params.Zone = args.Zone
+		if placement.Zone != "" {
+			params.Zone = placement.Zone
+		}
+		amount := placement.Amount - launchedInZone(launched, params.Zone)
+		if amount <= 0 {
+			logger.Info().Str("zone", params.Zone).Msg("Instances were already launched by a previous attempt")
+			continue
+		}
 
-	err = rDao.UpdateOperationNameForGCP(ctx, args.ReservationID, *opName)
-	if err != nil {
-		span.SetStatus(codes.Error, "cannot update operation name for GCP")
-		return fmt.Errorf("cannot update operation name for GCP: %w", err)
+		var instances []*string
+		var zoneOpName *string
+		instances, zoneOpName, err = gcpClient.InsertInstances(ctx, params, amount)
+		if err != nil {
+			span.SetStatus(codes.Error, "cannot run instances for gcp client")
+			return fmt.Errorf("cannot run instances for gcp client: %w", err)
+		}
+		if opName == nil {
+			opName = zoneOpName
+		}
+
+		// For each instance that was created in GCP, add it as a DB record
+		for _, instanceId := range instances {
+			if _, ok := created[*instanceId]; ok {
+				continue
+			}
+			created[*instanceId] = struct{}{}
+
+			err = rDao.CreateInstance(ctx, &models.ReservationInstance{
+				ReservationID: args.ReservationID,
+				InstanceID:    *instanceId,
+				Detail: models.ReservationInstanceDetail{
+					Zone: params.Zone,
+				},
+			})
+			if err != nil {
+				span.SetStatus(codes.Error, "cannot create instance reservation")
+				return fmt.Errorf("cannot create instance reservation for id %d: %w", instanceId, err)
+			}
+			logger.Info().Str("instance_id", *instanceId).Msgf("Created new instance via GCP reservation %s", *zoneOpName)
+		}
 	}
 
-	// For each instance that was created in GCP, add it as a DB record
-	for _, instanceId := range instances {
-		err = rDao.CreateInstance(ctx, &models.ReservationInstance{
-			ReservationID: args.ReservationID,
-			InstanceID:    *instanceId,
-		})
+	// the operation name was stored by the previous attempt when no instances were launched
+	if opName != nil {
+		err = rDao.UpdateOperationNameForGCP(ctx, args.ReservationID, *opName)
 		if err != nil {
-			span.SetStatus(codes.Error, "cannot create instance reservation")
-			return fmt.Errorf("cannot create instance reservation for id %d: %w", instanceId, err)
+			span.SetStatus(codes.Error, "cannot update operation name for GCP")
+			return fmt.Errorf("cannot update operation name for GCP: %w", err)
 		}
-		logger.Info().Str("instance_id", *instanceId).Msgf("Created new instance via GCP reservation %s", *opName)
+	}
+
+	return nil
+}
+
+// ensurePubkeyOnGCP adds the pubkey into project ssh-keys metadata and records it as a pubkey
+// resource. Project metadata keys are global, therefore the resource has no region and
+// the handle is the whole metadata line.
+func ensurePubkeyOnGCP(ctx context.Context, gcpClient clients.GCP, pk *models.Pubkey, args *LaunchInstanceGCPTaskArgs) error {
+	keyLine, err := pk.BodyWithUsername(ctx, config.GCP.LoginUsername)
+	if err != nil {
+		return fmt.Errorf("unable to get pubkey body with username: %w", err)
+	}
+
+	err = gcpClient.AddProjectSSHKey(ctx, keyLine)
+	if err != nil {
+		return fmt.Errorf("cannot add pubkey into project metadata: %w", err)
+	}
+
+	pkDao := dao.GetPubkeyDao(ctx)
+	pkr, err := pkDao.UnscopedGetResourceBySourceAndRegion(ctx, pk.ID, args.SourceID, "")
+	if errors.Is(err, dao.ErrNoRows) {
+		pkr = &models.PubkeyResource{
+			PubkeyID: pk.ID,
+			Provider: models.ProviderTypeGCP,
+			SourceID: args.SourceID,
+		}
+		pkr.RandomizeTag()
+	} else if err != nil {
+		return fmt.Errorf("unable to check pubkey resource: %w", err)
+	}
+
+	pkr.Handle = keyLine
+	if pkr.ID == 0 {
+		err = pkDao.UnscopedCreateResource(ctx, pkr)
+	} else {
+		err = pkDao.UnscopedUpdateResource(ctx, pkr)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot save pubkey resource: %w", err)
+	}
+
+	err = pkDao.UnscopedVerifyResource(ctx, pkr.ID)
+	if err != nil {
+		return fmt.Errorf("cannot verify pubkey resource: %w", err)
 	}
 
 	return nil
@@ -180,16 +342,18 @@ func FetchInstancesDescriptionGCP(ctx context.Context, args *LaunchInstanceGCPTa
 		span.SetStatus(codes.Error, "cannot get gcp client")
 		return fmt.Errorf("cannot get gcp client: %w", err)
 	}
-	ids, err := gcpClient.ListInstancesIDsByLabel(ctx, args.Detail.UUID)
+	instances, err := rDao.ListInstances(ctx, args.ReservationID)
 	if err != nil {
-		span.SetStatus(codes.Error, "cannot list instances ids by tag")
-		return fmt.Errorf("cannot list instances ids by tag: %w", err)
+		span.SetStatus(codes.Error, "cannot get instances list")
+		return fmt.Errorf("cannot get instances list: %w", err)
 	}
 
-	for _, id := range ids {
+	for _, instance := range instances {
+		id := instance.InstanceID
+		zone := instance.ZoneOrDefault(args.Zone)
 		var instanceDesc *clients.InstanceDescription
 		err = waitAndRetry(ctx, func() error {
-			instanceDesc, err = gcpClient.GetInstanceDescriptionByID(ctx, *id, args.Zone)
+			instanceDesc, err = gcpClient.GetInstanceDescriptionByID(ctx, id, zone)
 
 			if err != nil {
 				span.SetStatus(codes.Error, "cannot get instance description")
@@ -204,7 +368,7 @@ func FetchInstancesDescriptionGCP(ctx context.Context, args *LaunchInstanceGCPTa
 		}, 1, 500, 500, 1000, 2000, 2000)
 
 		if err != nil {
-			logger.Error().Err(err).Str("instance_id", *id).Msg("Cannot get instance description, skipping")
+			logger.Error().Err(err).Str("instance_id", id).Msg("Cannot get instance description, skipping")
 
 			// try to get the others
 			continue
@@ -6,6 +6,7 @@ import (
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	daoStubs "github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
@@ -88,3 +89,48 @@ func TestDoLaunchInstanceGCP(t *testing.T) {
 		assert.Equal(t, "10.0.0.11", resultInstances[1].Detail.PublicIPv4)
 	})
 }
+
+func TestDoLaunchInstanceGCPProjectKeys(t *testing.T) {
+	ctx := prepareGCPContext(t)
+
+	projectKeys := config.GCP.ProjectKeys
+	config.GCP.ProjectKeys = true
+	defer func() { config.GCP.ProjectKeys = projectKeys }()
+
+	pk := factories.NewPubkeyRSA()
+	err := daoStubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	res := prepareGCPReservation(t, ctx, pk)
+	err = dao.GetReservationDao(ctx).CreateGCP(ctx, res)
+	require.NoError(t, err, "failed to add stubbed reservation")
+
+	args := &jobs.LaunchInstanceGCPTaskArgs{
+		ImageName:     "composer-api-3b6225fc-d55a-4dcc-9d0a-b478ae152a",
+		Zone:          "europe-west8-c",
+		PubkeyID:      pk.ID,
+		SourceID:      res.SourceID,
+		ReservationID: res.ID,
+		ProjectID:     clients.NewAuthentication("example-project-id", models.ProviderTypeGCP),
+		Detail:        res.Detail,
+	}
+
+	err = jobs.DoLaunchInstanceGCP(ctx, args)
+	require.NoError(t, err, "launch instances failed to run")
+	assert.Equal(t, 1, clientStubs.CountStubInstancesGCP(ctx))
+
+	keyLine, err := pk.BodyWithUsername(ctx, config.GCP.LoginUsername)
+	require.NoError(t, err)
+
+	gcpClient, err := clients.GetGCPClient(ctx, args.ProjectID)
+	require.NoError(t, err, "failed to get stubbed GCP client")
+	found, err := gcpClient.HasProjectSSHKey(ctx, keyLine)
+	require.NoError(t, err)
+	assert.True(t, found, "pubkey was not added into project metadata")
+
+	pkr, err := dao.GetPubkeyDao(ctx).UnscopedGetResourceBySourceAndRegion(ctx, pk.ID, res.SourceID, "")
+	require.NoError(t, err, "pubkey resource was not created")
+	assert.Equal(t, models.ProviderTypeGCP, pkr.Provider)
+	assert.Equal(t, keyLine, pkr.Handle)
+	assert.True(t, pkr.VerifiedAt.Valid, "pubkey resource was not verified")
+}
@@ -37,6 +37,18 @@ var JobQueueSize = prometheus.NewGauge(prometheus.GaugeOpts{
 	ConstLabels: prometheus.Labels{"service": "provisioning", "component": "stats"},
 })
 
+var JobQueueDelayedSize = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name:        "provisioning_job_queue_delayed_size",
+	Help:        "background job queue delayed size (total failed jobs waiting for a retry)",
+	ConstLabels: prometheus.Labels{"service": "provisioning", "component": "stats"},
+})
+
+var JobQueueDeadSize = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name:        "provisioning_job_queue_dead_size",
+	Help:        "background job queue dead-letter size (total jobs which failed all attempts)",
+	ConstLabels: prometheus.Labels{"service": "provisioning", "component": "stats"},
+})
+
 var JobQueueInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 	Name:        "provisioning_job_queue_inflight",
 	Help:        "number of in-flight jobs (total jobs which are currently processing)",
@@ -81,6 +93,15 @@ var ReservationCount = prometheus.NewCounterVec(
 	[]string{"type", "result"},
 )
 
+var StuckReservationCount = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name:        "provisioning_stuck_reservation_count",
+		Help:        "count of reservations finished by the background reaper because their job was lost, by provider",
+		ConstLabels: prometheus.Labels{"service": version.PrometheusLabelName, "component": "stats"},
+	},
+	[]string{"provider"},
+)
+
 var DbStatsDuration = prometheus.NewHistogram(
 	prometheus.HistogramOpts{
 		Name:        "provisioning_db_stats_duration",
@@ -150,6 +171,14 @@ func SetJobQueueSize(size uint64) {
 	JobQueueSize.Set(float64(size))
 }
 
+func SetJobQueueDelayedSize(size uint64) {
+	JobQueueDelayedSize.Set(float64(size))
+}
+
+func SetJobQueueDeadSize(size uint64) {
+	JobQueueDeadSize.Set(float64(size))
+}
+
 func SetJobQueueInFlight(workerName string, inflight int64) {
 	JobQueueInFlight.WithLabelValues(workerName).Set(float64(inflight))
 }
@@ -158,6 +187,10 @@ func IncReservationCount(rtype, result string) {
 	ReservationCount.WithLabelValues(rtype, result).Inc()
 }
 
+func IncStuckReservationCount(pt models.ProviderType) {
+	StuckReservationCount.WithLabelValues(pt.String()).Inc()
+}
+
 func ObserveDbStatsDuration(observedFunc func()) {
 	start := time.Now()
 	defer func() {
@@ -15,10 +15,13 @@ func RegisterStatuserMetrics() {
 func RegisterStatsMetrics() {
 	prometheus.MustRegister(
 		JobQueueSize,
+		JobQueueDelayedSize,
+		JobQueueDeadSize,
 		JobQueueInFlight,
 		DbStatsDuration,
 		Reservations24hCount,
 		Reservations28dCount,
+		StuckReservationCount,
 	)
 }
 
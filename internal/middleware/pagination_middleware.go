@@ -6,7 +6,8 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/page"
 )
 
-// Pagination middleware is used to extract the offset and the limit
+// Pagination middleware is used to extract the offset and the limit or the token. Keyset
+// pagination is requested by the cursor parameter, an empty cursor lists the first page.
 func Pagination(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		offset := r.URL.Query().Get("offset")
@@ -16,6 +17,9 @@ func Pagination(next http.Handler) http.Handler {
 		newCtx := page.WithOffset(r.Context(), offset)
 		newCtx = page.WithLimit(newCtx, limit)
 		newCtx = page.WithToken(newCtx, token)
+		if r.URL.Query().Has("cursor") {
+			newCtx = page.WithCursor(newCtx, r.URL.Query().Get("cursor"))
+		}
 
 		next.ServeHTTP(w, r.WithContext(newCtx))
 	})
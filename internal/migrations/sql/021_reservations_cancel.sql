@@ -0,0 +1,2 @@
+ALTER TABLE reservations ADD COLUMN
+  cancelled_at TIMESTAMP;
@@ -0,0 +1,8 @@
+ALTER TABLE reservations ADD COLUMN
+  expires_at TIMESTAMP;
+
+ALTER TABLE reservations ADD COLUMN
+  expired_at TIMESTAMP;
+
+-- Partial index for the background expiry routine which only looks for reservations with pending expiry
+CREATE INDEX reservations_expires_at_idx ON reservations(expires_at) WHERE expires_at IS NOT NULL AND expired_at IS NULL;
@@ -0,0 +1,15 @@
+-- Job queue for WORKER_QUEUE=postgres, jobs are gob-encoded and locked via FOR UPDATE SKIP LOCKED
+CREATE TABLE job_queue
+(
+  id           UUID PRIMARY KEY,
+  type         VARCHAR(255) NOT NULL,
+  payload      BYTEA        NOT NULL,
+  attempt      INTEGER      NOT NULL DEFAULT 0,
+  enqueued_at  TIMESTAMP    NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+  run_at       TIMESTAMP    NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+  locked_until TIMESTAMP,
+  dead         BOOLEAN      NOT NULL DEFAULT FALSE
+);
+
+-- Partial index for the dequeue query which only looks for live jobs
+CREATE INDEX job_queue_run_at_idx ON job_queue(run_at) WHERE NOT dead;
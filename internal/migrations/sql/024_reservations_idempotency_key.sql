@@ -0,0 +1,5 @@
+ALTER TABLE reservations ADD COLUMN
+  idempotency_key VARCHAR(255);
+
+-- Repeated requests with the same Idempotency-Key header return the original reservation
+CREATE UNIQUE INDEX reservations_account_id_idempotency_key_idx ON reservations(account_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Reservation list filtering and sorting
+CREATE INDEX reservations_account_id_created_at_idx ON reservations(account_id, created_at);
+CREATE INDEX reservations_account_id_provider_idx ON reservations(account_id, provider);
+CREATE INDEX aws_reservation_details_source_id_idx ON aws_reservation_details(source_id);
+CREATE INDEX azure_reservation_details_source_id_idx ON azure_reservation_details(source_id);
+CREATE INDEX gcp_reservation_details_source_id_idx ON gcp_reservation_details(source_id);
+-- Instance ID filter uses the index of the reservation_instances.instance_id UNIQUE constraint
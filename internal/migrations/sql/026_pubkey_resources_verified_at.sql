@@ -0,0 +1,3 @@
+-- Time of the last successful check of the resource presence in the cloud, NULL when not yet verified
+ALTER TABLE pubkey_resources ADD COLUMN
+  verified_at TIMESTAMP;
@@ -0,0 +1,26 @@
+-- Reservation which launched the instance of an instance action (start, stop, reboot or terminate) reservation,
+-- NULL for launch reservations. Instance action reservations have no provider details.
+ALTER TABLE reservations ADD COLUMN
+  launch_reservation_id BIGINT NULL REFERENCES reservations(id) ON DELETE CASCADE;
+
+-- Instance actions launch nothing, they are not throttled and not counted
+CREATE OR REPLACE FUNCTION reservations_rate() RETURNS TRIGGER AS
+$reservations_rate$
+DECLARE
+  maximum INTEGER := reservations_rate_limit();
+  last_rec RECORD;
+BEGIN
+  IF NEW.launch_reservation_id IS NOT NULL THEN
+    RETURN NEW;
+  END IF;
+
+  FOR last_rec IN SELECT COUNT(*) FROM reservations WHERE account_id = NEW.account_id AND provider = NEW.provider AND success IS NULL AND launch_reservation_id IS NULL AND created_at >= now() - INTERVAL '1 second'
+    LOOP
+      IF last_rec.count >= maximum THEN
+        RAISE EXCEPTION 'too many pending reservations (%) for this provider (maximum % per second)', last_rec.count, maximum;
+      END IF;
+    END LOOP;
+
+  RETURN NEW;
+END;
+$reservations_rate$ LANGUAGE plpgsql;
@@ -68,3 +68,69 @@ func (pt ProviderType) String() string {
 	}
 	return ""
 }
+
+// InstanceAction is a lifecycle action performed on an already launched instance.
+type InstanceAction string
+
+const (
+	// InstanceActionUnknown is reserved
+	InstanceActionUnknown InstanceAction = ""
+
+	// Start a stopped instance
+	InstanceActionStart InstanceAction = "start"
+
+	// Stop a running instance (deallocate on Azure)
+	InstanceActionStop InstanceAction = "stop"
+
+	// Reboot a running instance (hard reset on GCP)
+	InstanceActionReboot InstanceAction = "reboot"
+
+	// Terminate (delete) an instance
+	InstanceActionTerminate InstanceAction = "terminate"
+)
+
+func InstanceActionFromString(str string) InstanceAction {
+	switch strings.ToLower(str) {
+	case "start":
+		return InstanceActionStart
+	case "stop":
+		return InstanceActionStop
+	case "reboot":
+		return InstanceActionReboot
+	case "terminate":
+		return InstanceActionTerminate
+	default:
+		return InstanceActionUnknown
+	}
+}
+
+func (a InstanceAction) String() string {
+	return string(a)
+}
+
+// InstanceState is a provider-neutral power state of a launched instance.
+type InstanceState string
+
+const (
+	// InstanceStateUnknown is used when the state was not fetched or cannot be mapped
+	InstanceStateUnknown InstanceState = ""
+
+	// Instance is being created or started
+	InstanceStatePending InstanceState = "pending"
+
+	// Instance is running
+	InstanceStateRunning InstanceState = "running"
+
+	// Instance is being stopped (deallocated on Azure)
+	InstanceStateStopping InstanceState = "stopping"
+
+	// Instance is stopped (deallocated on Azure)
+	InstanceStateStopped InstanceState = "stopped"
+
+	// Instance is being terminated or was terminated (deleted)
+	InstanceStateTerminated InstanceState = "terminated"
+)
+
+func (s InstanceState) String() string {
+	return string(s)
+}
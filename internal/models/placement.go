@@ -0,0 +1,115 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PlacementStrategy defines how instances of a reservation are placed into zones.
+type PlacementStrategy string
+
+const (
+	// PlacementStrategySingle launches all instances into a single zone, the default strategy.
+	PlacementStrategySingle PlacementStrategy = "single"
+	// PlacementStrategySpread distributes instances round-robin across multiple zones.
+	PlacementStrategySpread PlacementStrategy = "spread"
+)
+
+var (
+	ErrPlacementStrategyValue = fmt.Errorf("placement strategy must be %s or %s", PlacementStrategySingle, PlacementStrategySpread)
+	ErrPlacementSingleZones   = errors.New("single placement accepts at most one zone")
+	ErrPlacementSpreadZones   = errors.New("spread placement requires at least two zones")
+	ErrPlacementDuplicateZone = errors.New("placement zones must be unique")
+	ErrPlacementEmptyZone     = errors.New("placement zone must not be empty")
+	ErrPlacementUnsupported   = errors.New("placement is not supported by the provider")
+)
+
+// Placement is a provider-neutral configuration of instance placement. Zones are AWS availability
+// zones (us-east-1a), GCP zones (us-east1-b) or Azure availability zones (1, 2 or 3) within the
+// region of the reservation.
+type Placement struct {
+	// Strategy: single (default) or spread.
+	Strategy PlacementStrategy `json:"strategy,omitempty" yaml:"strategy"`
+
+	// Zones to launch the instances into. Single placement accepts at most one zone, the provider
+	// default is used when not set. Spread placement requires at least two zones.
+	Zones []string `json:"zones,omitempty" yaml:"zones"`
+}
+
+// ZoneAmount is amount of instances to launch into a zone, empty zone stands for the provider default.
+type ZoneAmount struct {
+	Zone   string
+	Amount int64
+}
+
+// Validate checks the placement configuration for the provider. Zones are validated against
+// preloaded regional availability by the reservation services.
+func (p *Placement) Validate(provider ProviderType) error {
+	if p == nil {
+		return nil
+	}
+
+	//nolint:exhaustive
+	switch provider {
+	case ProviderTypeAWS, ProviderTypeAzure, ProviderTypeGCP:
+	default:
+		return ErrPlacementUnsupported
+	}
+
+	seen := make(map[string]struct{}, len(p.Zones))
+	for _, zone := range p.Zones {
+		if zone == "" {
+			return ErrPlacementEmptyZone
+		}
+		if _, ok := seen[zone]; ok {
+			return fmt.Errorf("%w: %s", ErrPlacementDuplicateZone, zone)
+		}
+		seen[zone] = struct{}{}
+	}
+
+	switch p.Strategy {
+	case "", PlacementStrategySingle:
+		if len(p.Zones) > 1 {
+			return ErrPlacementSingleZones
+		}
+	case PlacementStrategySpread:
+		if len(p.Zones) < 2 {
+			return ErrPlacementSpreadZones
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrPlacementStrategyValue, p.Strategy)
+	}
+
+	return nil
+}
+
+// Distribute splits amount of instances into zones round-robin, the first zones receive one more
+// instance when the amount is not divisible. Zones with no instances are omitted. Returns a single
+// entry with empty zone when no zones are configured.
+func (p *Placement) Distribute(amount int64) []ZoneAmount {
+	if p == nil || len(p.Zones) == 0 {
+		return []ZoneAmount{{Amount: amount}}
+	}
+
+	zones := p.Zones
+	if p.Strategy != PlacementStrategySpread {
+		zones = zones[:1]
+	}
+
+	result := make([]ZoneAmount, 0, len(zones))
+	for i, zone := range zones {
+		count := amount / int64(len(zones))
+		if int64(i) < amount%int64(len(zones)) {
+			count++
+		}
+		if count > 0 {
+			result = append(result, ZoneAmount{Zone: zone, Amount: count})
+		}
+	}
+	return result
+}
+
+// IsSpread returns true when instances are spread across multiple zones.
+func (p *Placement) IsSpread() bool {
+	return p != nil && p.Strategy == PlacementStrategySpread
+}
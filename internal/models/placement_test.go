@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlacementValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		provider  models.ProviderType
+		placement *models.Placement
+		err       error
+	}{
+		{"nil", models.ProviderTypeAWS, nil, nil},
+		{"single default", models.ProviderTypeAWS, &models.Placement{}, nil},
+		{"single zone", models.ProviderTypeGCP, &models.Placement{Strategy: "single", Zones: []string{"us-east1-b"}}, nil},
+		{"spread", models.ProviderTypeAzure, &models.Placement{Strategy: "spread", Zones: []string{"1", "2", "3"}}, nil},
+		{"single zones", models.ProviderTypeAWS, &models.Placement{Zones: []string{"us-east-1a", "us-east-1b"}}, models.ErrPlacementSingleZones},
+		{"spread one zone", models.ProviderTypeAWS, &models.Placement{Strategy: "spread", Zones: []string{"us-east-1a"}}, models.ErrPlacementSpreadZones},
+		{"duplicate", models.ProviderTypeGCP, &models.Placement{Strategy: "spread", Zones: []string{"us-east1-b", "us-east1-b"}}, models.ErrPlacementDuplicateZone},
+		{"empty zone", models.ProviderTypeAzure, &models.Placement{Zones: []string{""}}, models.ErrPlacementEmptyZone},
+		{"unknown strategy", models.ProviderTypeAWS, &models.Placement{Strategy: "pack"}, models.ErrPlacementStrategyValue},
+		{"noop", models.ProviderTypeNoop, &models.Placement{}, models.ErrPlacementUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.placement.Validate(tt.provider)
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPlacementDistribute(t *testing.T) {
+	var none *models.Placement
+	require.Equal(t, []models.ZoneAmount{{Amount: 3}}, none.Distribute(3))
+
+	single := &models.Placement{Zones: []string{"us-east-1a"}}
+	require.Equal(t, []models.ZoneAmount{{Zone: "us-east-1a", Amount: 3}}, single.Distribute(3))
+
+	spread := &models.Placement{Strategy: models.PlacementStrategySpread, Zones: []string{"1", "2", "3"}}
+	require.Equal(t, []models.ZoneAmount{{Zone: "1", Amount: 3}, {Zone: "2", Amount: 2}, {Zone: "3", Amount: 2}}, spread.Distribute(7))
+	require.Equal(t, []models.ZoneAmount{{Zone: "1", Amount: 1}, {Zone: "2", Amount: 1}}, spread.Distribute(2))
+}
@@ -10,7 +10,24 @@ import (
 	"github.com/rs/zerolog"
 )
 
-var ErrInvalidPubkeyFormat = errors.New("invalid public key format")
+var (
+	ErrInvalidPubkeyFormat    = errors.New("invalid public key format")
+	ErrUnsupportedPubkeyType  = errors.New("unsupported public key type")
+	ErrPubkeyTypeIncompatible = errors.New("public key type is not supported by the provider")
+)
+
+// providerKeyTypes are public key types which can be deployed onto instances of the provider. AWS
+// only imports RSA and ED25519 key-pairs. Certificates are not deployable anywhere because they need
+// a certificate authority configured on the instance instead of an authorized key.
+var providerKeyTypes = map[ProviderType][]string{
+	ProviderTypeAWS:   {"ssh-rsa", "ssh-ed25519"},
+	ProviderTypeAzure: {"ssh-rsa", "ssh-ed25519"},
+	ProviderTypeGCP: {
+		"ssh-rsa", "ssh-ed25519",
+		"ecdsa-sha2-nistp256", "ecdsa-sha2-nistp384", "ecdsa-sha2-nistp521",
+		"sk-ssh-ed25519@openssh.com", "sk-ecdsa-sha2-nistp256@openssh.com",
+	},
+}
 
 // Pubkey represents SSH public key that can be deployed to clients.
 type Pubkey struct {
@@ -29,8 +46,9 @@ type Pubkey struct {
 	// Public key body encoded in base64 (.pub format). Required.
 	Body string `db:"body" validate:"required,sshPubkey"`
 
-	// Key type: "ssh-ed25519" or "ssh-rsa".
-	Type string `db:"type" validate:"omitempty,oneof=test ssh-rsa ssh-ed25519"`
+	// Key type: "ssh-ed25519", "ssh-rsa", "ecdsa-sha2-nistp256/384/521", "sk-ssh-ed25519@openssh.com",
+	// "sk-ecdsa-sha2-nistp256@openssh.com" or a certificate of one of these (e.g. "ssh-rsa-cert-v01@openssh.com").
+	Type string `db:"type" validate:"omitempty,sshKeyType"`
 
 	// SHA256 base64 encoded fingerprint with padding without any prefix. Note OpenSSH
 	// typically prints the fingerprint without padding: ssh-keygen -l -f $HOME/.ssh/key.pub
@@ -44,9 +62,21 @@ type Pubkey struct {
 	FingerprintLegacy string `db:"fingerprint_legacy" validate:"omitempty,len=47"`
 }
 
-// FindAwsFingerprint returns suitable fingerprint for searching AWS key-pairs.
+// CheckProviderCompatibility returns ErrPubkeyTypeIncompatible when the key cannot be deployed
+// onto instances of the provider.
+func (pk *Pubkey) CheckProviderCompatibility(provider ProviderType) error {
+	for _, t := range providerKeyTypes[provider] {
+		if t == pk.Type {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s key cannot be used in %s", ErrPubkeyTypeIncompatible, pk.Type, provider.String())
+}
+
+// FindAwsFingerprint returns suitable fingerprint for searching AWS key-pairs. Certificates use
+// the fingerprint of the certified key.
 func (pk *Pubkey) FindAwsFingerprint(ctx context.Context) string {
-	switch pk.Type {
+	switch ssh.BaseKeyType(pk.Type) {
 	case "ssh-rsa":
 		fp, err := ssh.GenerateAWSFingerprint([]byte(pk.Body))
 		if err != nil {
@@ -62,10 +92,12 @@ func (pk *Pubkey) FindAwsFingerprint(ctx context.Context) string {
 	}
 }
 
-func (pk *Pubkey) BodyWithUsername(ctx context.Context) (string, error) {
+// BodyWithUsername returns the key in the GCP ssh-keys metadata format "username:type body",
+// the key comment is dropped.
+func (pk *Pubkey) BodyWithUsername(ctx context.Context, username string) (string, error) {
 	parts := strings.Split(pk.Body, " ")
 	if len(parts) < 2 {
 		return "", ErrInvalidPubkeyFormat
 	}
-	return fmt.Sprintf("gcp-user:%s %s", parts[0], parts[1]), nil
+	return fmt.Sprintf("%s:%s %s", username, parts[0], parts[1]), nil
 }
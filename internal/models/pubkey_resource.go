@@ -1,6 +1,7 @@
 package models
 
 import (
+	"database/sql"
 	"fmt"
 )
 
@@ -29,6 +30,10 @@ type PubkeyResource struct {
 
 	// Region name. This is provider-dependant. Required for providers which don't have global public keys.
 	Region string `db:"region" json:"region"`
+
+	// Time when the resource was last found in the cloud by the reconciliation, not set when
+	// the resource was not verified yet.
+	VerifiedAt sql.NullTime `db:"verified_at" json:"verified_at"`
 }
 
 // FormattedTag returns Tag concatenated in a safe way for clouds. That means
@@ -42,6 +42,23 @@ type Reservation struct {
 	// Time when reservation was finished or nil when it's still processing.
 	FinishedAt sql.NullTime `db:"finished_at" json:"finished_at"`
 
+	// Time when reservation was cancelled by the user or nil when it was not cancelled.
+	CancelledAt sql.NullTime `db:"cancelled_at" json:"cancelled_at"`
+
+	// Time when instances of the reservation are terminated by the background expiry routine or nil when
+	// the reservation never expires.
+	ExpiresAt sql.NullTime `db:"expires_at" json:"expires_at"`
+
+	// Time when instances were terminated because the reservation expired or nil when not yet expired.
+	ExpiredAt sql.NullTime `db:"expired_at" json:"expired_at"`
+
+	// Client-provided key from the Idempotency-Key header, unique per account. Repeated requests with
+	// the same key return this reservation instead of creating a new one.
+	IdempotencyKey sql.NullString `db:"idempotency_key" json:"-"`
+
+	// Reservation which launched the instance of an instance action reservation or nil for launch reservations.
+	LaunchReservationID sql.NullInt64 `db:"launch_reservation_id" json:"-"`
+
 	// Flag indicating success, error or unknown state (NULL). See Status for the actual error.
 	Success sql.NullBool `db:"success" json:"success"`
 }
@@ -50,6 +67,13 @@ type NoopReservation struct {
 	Reservation
 }
 
+// InstanceActionReservation tracks a lifecycle action (start, stop, reboot or terminate) performed
+// on instances launched by another reservation. It has no provider-specific details, LaunchReservationID
+// refers to the launch reservation.
+type InstanceActionReservation struct {
+	Reservation
+}
+
 type AWSDetail struct {
 	Region string `json:"region"`
 
@@ -70,6 +94,30 @@ type AWSDetail struct {
 
 	// PubkeyName on AWS in given region. Found by the EnsurePubkey job.
 	PubkeyName string `json:"pubkey_name"`
+
+	// Optional custom user data merged with the generated user data
+	UserData string `json:"user_data,omitempty"`
+
+	// Optional subnet ID to launch the instances into, default VPC is used when empty
+	SubnetID string `json:"subnet_id,omitempty"`
+
+	// Optional security group IDs, default security group is used when empty
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty"`
+
+	// Optional public IP address assignment, subnet setting is used when not set
+	AssociatePublicIP *bool `json:"associate_public_ip,omitempty"`
+
+	// Optional root and data volumes, AMI block device mapping is used when not set
+	Volumes *Volumes `json:"volumes,omitempty"`
+
+	// Optional user-defined tags applied to the created resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Optional spot (preemptible) instance configuration, on-demand instances are launched when not set
+	Spot *SpotOptions `json:"spot,omitempty"`
+
+	// Optional zone placement of the instances, all instances are launched into a single zone when not set
+	Placement *Placement `json:"placement,omitempty"`
 }
 
 type AWSReservation struct {
@@ -112,6 +160,30 @@ type GCPDetail struct {
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff"`
+
+	// Optional custom shell script appended to the generated startup script
+	UserData string `json:"user_data,omitempty"`
+
+	// Optional boot and data disks, image defaults are used when not set
+	Volumes *Volumes `json:"volumes,omitempty"`
+
+	// Optional user-defined labels applied to the created resources
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Optional spot (preemptible) instance configuration, on-demand instances are launched when not set
+	Spot *SpotOptions `json:"spot,omitempty"`
+
+	// Optional zone placement of the instances, all instances are launched into a single zone when not set
+	Placement *Placement `json:"placement,omitempty"`
+
+	// Optional network partial URL (global/networks/NAME), default network is used when not set
+	NetworkID string `json:"network_id,omitempty"`
+
+	// Optional subnetwork partial URL (regions/REGION/subnetworks/NAME)
+	SubnetID string `json:"subnet_id,omitempty"`
+
+	// Optional external IP assignment, ephemeral external IP is assigned when not set
+	ExternalIP *bool `json:"external_ip,omitempty"`
 }
 
 type GCPReservation struct {
@@ -144,6 +216,18 @@ type AzureDetail struct {
 	// InstanceSize of Azure VM.
 	InstanceSize string `json:"instance_size"`
 
+	// Optional Template Spec (or its version) resource ID or empty string
+	LaunchTemplateID string `json:"launch_template_id,omitempty"`
+
+	// Optional existing virtual network resource ID, the shared network is created when not set
+	NetworkID string `json:"network_id,omitempty"`
+
+	// Optional existing subnet resource ID, first subnet of the network is used when not set
+	SubnetID string `json:"subnet_id,omitempty"`
+
+	// Optional existing network security group resource ID
+	SecurityGroupID string `json:"security_group_id,omitempty"`
+
 	// Amount of instances to provision of type: Instance type.
 	Amount int64 `json:"amount"`
 
@@ -152,6 +236,21 @@ type AzureDetail struct {
 
 	// ResourceGroup is name of Resource Group to put the created resources into
 	ResourceGroup string `json:"resource_group"`
+
+	// Optional custom user data merged with the generated user data
+	UserData string `json:"user_data,omitempty"`
+
+	// Optional OS and data disks, image defaults are used when not set
+	Volumes *Volumes `json:"volumes,omitempty"`
+
+	// Optional user-defined tags applied to the created resources
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Optional spot (preemptible) instance configuration, on-demand instances are launched when not set
+	Spot *SpotOptions `json:"spot,omitempty"`
+
+	// Optional zone placement of the instances, all instances are launched into a single zone when not set
+	Placement *Placement `json:"placement,omitempty"`
 }
 
 type AzureReservation struct {
@@ -171,10 +270,12 @@ type AzureReservation struct {
 }
 
 type ReservationInstanceDetail struct {
-	PublicDNS   string `json:"public_dns,omitempty"`
-	PublicIPv4  string `json:"public_ipv4,omitempty"`
-	PrivateIPv4 string `json:"private_ipv4,omitempty"`
-	PrivateIPv6 string `json:"private_ipv6,omitempty"`
+	PublicDNS   string        `json:"public_dns,omitempty"`
+	PublicIPv4  string        `json:"public_ipv4,omitempty"`
+	PrivateIPv4 string        `json:"private_ipv4,omitempty"`
+	PrivateIPv6 string        `json:"private_ipv6,omitempty"`
+	State       InstanceState `json:"state,omitempty"`
+	Zone        string        `json:"zone,omitempty"`
 }
 
 type ReservationInstance struct {
@@ -187,3 +288,12 @@ type ReservationInstance struct {
 	// Instance's description, ip and dns
 	Detail ReservationInstanceDetail `db:"detail" json:"detail" yaml:"detail"`
 }
+
+// ZoneOrDefault returns zone the instance was launched into or the given zone (usually the zone of
+// the reservation) when no zone was recorded.
+func (ri *ReservationInstance) ZoneOrDefault(zone string) string {
+	if ri.Detail.Zone != "" {
+		return ri.Detail.Zone
+	}
+	return zone
+}
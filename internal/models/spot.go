@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PricingModel of launched instances.
+type PricingModel string
+
+const (
+	// PricingModelOnDemand are regular instances
+	PricingModelOnDemand PricingModel = "on-demand"
+	// PricingModelSpot are spot (AWS, Azure) or preemptible (GCP) instances
+	PricingModelSpot PricingModel = "spot"
+)
+
+const (
+	// InterruptionActionTerminate terminates (deletes) interrupted instances, the default action.
+	InterruptionActionTerminate = "terminate"
+	// InterruptionActionStop stops (deallocates) interrupted instances keeping their disks. Not
+	// supported by AWS.
+	InterruptionActionStop = "stop"
+)
+
+var (
+	ErrSpotMaxPriceInvalid         = errors.New("spot max price must not be negative")
+	ErrSpotMaxPriceUnsupported     = errors.New("spot max price is not supported by the provider")
+	ErrSpotStopUnsupported         = errors.New("spot interruption action stop is not supported by the provider")
+	ErrSpotInterruptionActionValue = fmt.Errorf("spot interruption action must be %s or %s", InterruptionActionTerminate, InterruptionActionStop)
+	ErrSpotUnsupported             = errors.New("spot instances are not supported by the provider")
+)
+
+// SpotOptions is a provider-neutral configuration of spot (preemptible) instances. Spot instances
+// use spare capacity for a fraction of the on-demand price but can be interrupted at any time.
+type SpotOptions struct {
+	// Maximum price in USD per instance hour, zero caps the price at the on-demand price. Not
+	// supported by GCP, which has fixed spot prices.
+	MaxPrice float64 `json:"max_price,omitempty" yaml:"max_price"`
+
+	// Action taken when an instance is interrupted: terminate (default) or stop. Stop is not
+	// supported by AWS, which requires persistent spot requests for stopped instances.
+	InterruptionAction string `json:"interruption_action,omitempty" yaml:"interruption_action"`
+}
+
+// Validate checks the spot configuration for the provider.
+func (s *SpotOptions) Validate(provider ProviderType) error {
+	if s == nil {
+		return nil
+	}
+
+	//nolint:exhaustive
+	switch provider {
+	case ProviderTypeAWS:
+		if s.StopOnInterruption() {
+			return ErrSpotStopUnsupported
+		}
+	case ProviderTypeAzure:
+	case ProviderTypeGCP:
+		if s.MaxPrice != 0 {
+			return ErrSpotMaxPriceUnsupported
+		}
+	default:
+		return ErrSpotUnsupported
+	}
+
+	if s.MaxPrice < 0 {
+		return fmt.Errorf("%w: %f", ErrSpotMaxPriceInvalid, s.MaxPrice)
+	}
+	if s.InterruptionAction != "" && s.InterruptionAction != InterruptionActionTerminate && s.InterruptionAction != InterruptionActionStop {
+		return fmt.Errorf("%w: %s", ErrSpotInterruptionActionValue, s.InterruptionAction)
+	}
+
+	return nil
+}
+
+// StopOnInterruption returns true when interrupted instances should be stopped rather than terminated.
+func (s *SpotOptions) StopOnInterruption() bool {
+	return s != nil && s.InterruptionAction == InterruptionActionStop
+}
+
+// PricingModel returns spot pricing model for spot options and on-demand when nil.
+func (s *SpotOptions) PricingModel() PricingModel {
+	if s == nil {
+		return PricingModelOnDemand
+	}
+	return PricingModelSpot
+}
@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpotOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider models.ProviderType
+		spot     *models.SpotOptions
+		err      error
+	}{
+		{"nil", models.ProviderTypeAWS, nil, nil},
+		{"AWS max price", models.ProviderTypeAWS, &models.SpotOptions{MaxPrice: 0.05, InterruptionAction: "terminate"}, nil},
+		{"AWS stop", models.ProviderTypeAWS, &models.SpotOptions{InterruptionAction: "stop"}, models.ErrSpotStopUnsupported},
+		{"Azure defaults", models.ProviderTypeAzure, &models.SpotOptions{}, nil},
+		{"GCP stop", models.ProviderTypeGCP, &models.SpotOptions{InterruptionAction: "stop"}, nil},
+		{"GCP max price", models.ProviderTypeGCP, &models.SpotOptions{MaxPrice: 0.05}, models.ErrSpotMaxPriceUnsupported},
+		{"negative price", models.ProviderTypeAzure, &models.SpotOptions{MaxPrice: -1}, models.ErrSpotMaxPriceInvalid},
+		{"unknown action", models.ProviderTypeAWS, &models.SpotOptions{InterruptionAction: "hibernate"}, models.ErrSpotInterruptionActionValue},
+		{"noop", models.ProviderTypeNoop, &models.SpotOptions{}, models.ErrSpotUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.spot.Validate(tt.provider)
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestSpotOptionsPricingModel(t *testing.T) {
+	var none *models.SpotOptions
+	require.Equal(t, models.PricingModelOnDemand, none.PricingModel())
+	require.False(t, none.StopOnInterruption())
+	require.Equal(t, models.PricingModelSpot, (&models.SpotOptions{InterruptionAction: "stop"}).PricingModel())
+	require.True(t, (&models.SpotOptions{InterruptionAction: "stop"}).StopOnInterruption())
+}
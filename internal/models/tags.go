@@ -0,0 +1,80 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTags is the maximum amount of user-defined tags. Providers allow 50 (AWS, Azure) or 64 (GCP)
+// tags per resource, some of them are reserved for tags set by the service.
+const MaxTags = 40
+
+// ReservedTagPrefix is a prefix of tags set by the service (e.g. rh-rid or rh-org).
+const ReservedTagPrefix = "rh-"
+
+var (
+	ErrTooManyTags     = fmt.Errorf("at most %d tags are supported", MaxTags)
+	ErrTagKeyReserved  = errors.New("tag key is reserved")
+	ErrTagKeyInvalid   = errors.New("tag key is not valid for the provider")
+	ErrTagValueInvalid = errors.New("tag value is not valid for the provider")
+	ErrTagsUnsupported = errors.New("tags are not supported by the provider")
+)
+
+var (
+	// letters, numbers, spaces and _ . : / = + - @
+	awsTagRegexp = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+	// lowercase letters, numbers, underscores and dashes, keys must start with a letter
+	gcpLabelKeyRegexp   = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
+	gcpLabelValueRegexp = regexp.MustCompile(`^[a-z0-9_-]{0,63}$`)
+)
+
+// ValidateTags checks user-defined tags (labels for GCP) against key and value rules of the provider.
+func ValidateTags(provider ProviderType, tags map[string]string) error {
+	if len(tags) > MaxTags {
+		return ErrTooManyTags
+	}
+
+	for key, value := range tags {
+		if strings.HasPrefix(strings.ToLower(key), ReservedTagPrefix) {
+			return fmt.Errorf("%w: %s", ErrTagKeyReserved, key)
+		}
+
+		var keyValid, valueValid bool
+		//nolint:exhaustive
+		switch provider {
+		case ProviderTypeAWS:
+			// Name tag is set from the name of the instance(s)
+			if key == "Name" || strings.HasPrefix(strings.ToLower(key), "aws:") {
+				return fmt.Errorf("%w: %s", ErrTagKeyReserved, key)
+			}
+			keyValid = lengthBetween(key, 1, 128) && awsTagRegexp.MatchString(key)
+			valueValid = lengthBetween(value, 0, 256) && awsTagRegexp.MatchString(value)
+		case ProviderTypeAzure:
+			keyValid = lengthBetween(key, 1, 512) && !strings.ContainsAny(key, `<>%&\?/`)
+			valueValid = lengthBetween(value, 0, 256)
+		case ProviderTypeGCP:
+			keyValid = gcpLabelKeyRegexp.MatchString(key)
+			valueValid = gcpLabelValueRegexp.MatchString(value)
+		default:
+			return ErrTagsUnsupported
+		}
+
+		if !keyValid {
+			return fmt.Errorf("%w: %s", ErrTagKeyInvalid, key)
+		}
+		if !valueValid {
+			return fmt.Errorf("%w: %s", ErrTagValueInvalid, value)
+		}
+	}
+
+	return nil
+}
+
+func lengthBetween(str string, min, max int) bool {
+	length := utf8.RuneCountInString(str)
+	return length >= min && length <= max
+}
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string, models.MaxTags+1)
+	for i := 0; i <= models.MaxTags; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+
+	tests := []struct {
+		name     string
+		provider models.ProviderType
+		tags     map[string]string
+		err      error
+	}{
+		{"nil", models.ProviderTypeAWS, nil, nil},
+		{"AWS", models.ProviderTypeAWS, map[string]string{"Cost Center": "rd:42/eu-west", "team": ""}, nil},
+		{"AWS invalid character", models.ProviderTypeAWS, map[string]string{"cost": "a*b"}, models.ErrTagValueInvalid},
+		{"AWS prefix", models.ProviderTypeAWS, map[string]string{"aws:cloudformation": "x"}, models.ErrTagKeyReserved},
+		{"AWS name", models.ProviderTypeAWS, map[string]string{"Name": "x"}, models.ErrTagKeyReserved},
+		{"AWS key too long", models.ProviderTypeAWS, map[string]string{strings.Repeat("k", 129): "x"}, models.ErrTagKeyInvalid},
+		{"Azure", models.ProviderTypeAzure, map[string]string{"CostCenter": "R&D * 42"}, nil},
+		{"Azure invalid key", models.ProviderTypeAzure, map[string]string{"cost/center": "42"}, models.ErrTagKeyInvalid},
+		{"GCP", models.ProviderTypeGCP, map[string]string{"cost-center": "r_d-42"}, nil},
+		{"GCP uppercase", models.ProviderTypeGCP, map[string]string{"CostCenter": "42"}, models.ErrTagKeyInvalid},
+		{"GCP key starts with number", models.ProviderTypeGCP, map[string]string{"1cost": "42"}, models.ErrTagKeyInvalid},
+		{"GCP invalid value", models.ProviderTypeGCP, map[string]string{"cost": "R&D"}, models.ErrTagValueInvalid},
+		{"reserved", models.ProviderTypeAzure, map[string]string{"RH-rid": "1"}, models.ErrTagKeyReserved},
+		{"too many", models.ProviderTypeAzure, tooMany, models.ErrTooManyTags},
+		{"noop", models.ProviderTypeNoop, map[string]string{"cost": "42"}, models.ErrTagsUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := models.ValidateTags(tt.provider, tt.tags)
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -25,7 +25,7 @@ func Transform(ctx context.Context, model interface{}) error {
 	return nil
 }
 
-// generates fingerprint fields or returns an error for unsupported keys: "x509: unsupported public key"
+// generates fingerprint fields or returns ErrUnsupportedPubkeyType for unsupported keys
 func generateFingerprints(ctx context.Context, sl mold.StructLevel) error {
 	pk := sl.Struct().Interface().(Pubkey)
 
@@ -40,9 +40,16 @@ func generateFingerprints(ctx context.Context, sl mold.StructLevel) error {
 	pk.FingerprintLegacy = pkf.MD5
 	sl.Struct().Set(reflect.ValueOf(pk))
 
-	err = validateAWS(ctx, sl)
-	if err != nil {
-		return fmt.Errorf("key error %s: %w", pk.Name, err)
+	if !ssh.IsSupportedKeyType(pk.Type) {
+		return fmt.Errorf("key error %s: %w: %s", pk.Name, ErrUnsupportedPubkeyType, pk.Type)
+	}
+
+	// security keys cannot be PEM encoded and are not supported by AWS
+	if !ssh.IsSecurityKeyType(pk.Type) {
+		err = validateAWS(ctx, sl)
+		if err != nil {
+			return fmt.Errorf("key error %s: %w", pk.Name, err)
+		}
 	}
 
 	return nil
@@ -56,7 +63,7 @@ func validateAWS(ctx context.Context, sl mold.StructLevel) error {
 	_, err := ssh.GenerateAWSFingerprint([]byte(pk.Body))
 	if err != nil {
 		zerolog.Ctx(ctx).Warn().Err(err).Str("pubkey", pk.Body).Msg("AWS fingerprint validation error")
-		return fmt.Errorf("invalid public key type (only ed25519 and rsa keys are supported): %w", err)
+		return fmt.Errorf("invalid public key type (only rsa, ecdsa, ed25519 and security keys are supported): %w", err)
 	}
 	sl.Struct().Set(reflect.ValueOf(pk))
 
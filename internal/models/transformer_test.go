@@ -19,6 +19,9 @@ func TestFingerprintGeneration(t *testing.T) {
 	tests := []test{
 		{"ed25519", factories.NewPubkeyED25519(), "gL/y6MvNmJ8jDXtsL/oMmK8jUuIefN39BBuvYw/Rndk="},
 		{"rsa", factories.NewPubkeyRSA(), "ENShRe/0uDLSw9c+7tc9PxkD/p4blyB/DTgBSIyTAJY="},
+		{"ecdsa", factories.NewPubkeyECDSA(), "i2SD7CQSFn/jesN7jfPEkMTxOQKatfdM3jy8Q92IC5c="},
+		{"sk-ed25519", factories.NewPubkeySKED25519(), "/gWgX26zD+upPbyzQKTEWo4dzqMcHwOp4v/KTVQdYBU="},
+		{"ed25519-cert", factories.NewPubkeyED25519Cert(), "d+u9GGspoOa+iAKxAD2umBCMouHpmMByWlbeGSDIvNY="},
 	}
 
 	for _, td := range tests {
@@ -52,3 +55,16 @@ func TestFingerprintLegacyGeneration(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformUnsupportedType(t *testing.T) {
+	err := models.Transform(context.Background(), factories.NewPubkeyDSS())
+	assert.ErrorIs(t, err, models.ErrUnsupportedPubkeyType)
+}
+
+func TestFindAwsFingerprintCertificate(t *testing.T) {
+	pk := factories.NewPubkeyED25519Cert()
+	err := models.Transform(context.Background(), pk)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, pk.FindAwsFingerprint(context.Background()))
+	assert.Equal(t, pk.Fingerprint, pk.FindAwsFingerprint(context.Background()))
+}
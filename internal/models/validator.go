@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 
+	sshKeys "github.com/RHEnVision/provisioning-backend/internal/ssh"
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog"
 	"golang.org/x/crypto/ssh"
@@ -18,6 +19,12 @@ func init() {
 		_, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fl.Field().String()))
 		return err == nil
 	})
+
+	// "test" type is used for keys which need to have their fingerprints recalculated
+	_ = validate.RegisterValidation("sshKeyType", func(fl validator.FieldLevel) bool {
+		keyType := fl.Field().String()
+		return keyType == "test" || sshKeys.IsSupportedKeyType(keyType)
+	})
 }
 
 func Validate(ctx context.Context, model interface{}) validator.ValidationErrors {
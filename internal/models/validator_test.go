@@ -39,7 +39,7 @@ func TestPubkeyInvalidBody(t *testing.T) {
 func TestBodyWithUsername(t *testing.T) {
 	t.Run("RSA", func(t *testing.T) {
 		pk := factories.NewPubkeyRSA()
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.NoError(t, err)
 		assert.Equal(t, "gcp-user:ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC8w6DONv1qn3IdgxSpkYOClq7oe7davWFqKVHPbLoS6+dF"+
 			"Inru7gdEO5byhTih6+PwRhHv/b1I+Mtt5MDZ8Sv7XFYpX/3P/u5zQiy1PkMSFSz0brRRUfEQxhXLW97FJa7l+bej2HJ"+
@@ -49,7 +49,7 @@ func TestBodyWithUsername(t *testing.T) {
 	})
 	t.Run("DSS", func(t *testing.T) {
 		pk := factories.NewPubkeyDSS()
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.NoError(t, err)
 		assert.Equal(t, "gcp-user:ssh-dss AAAAB3NzaC1kc3MAAACBAKqezP3rkK/NcWvMWqoP3qOggGG4QW1vhQJOfyH/l9CbdRxlrcTV9AD5"+
 			"BYMcJNn3Ill0iu9d7gSQTZJu2cEWiE8yHJhWOerfPDB4R8BGQlMvbO+8rTplm1Eo3WxtYD0q45Urfh/Ej7HgliTsAYB"+
@@ -61,28 +61,28 @@ func TestBodyWithUsername(t *testing.T) {
 	})
 	t.Run("ECDSA", func(t *testing.T) {
 		pk := factories.NewPubkeyECDSA()
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.NoError(t, err)
 		assert.Equal(t, "gcp-user:ecdsa-sha2-nistp256 AAAAE2VjZHNhLXNoYTItbmlzdHAyNTYAAAAIbmlzdHAyNTYAAABBBAaOrIRmMPX84l"+
 			"YJ6y3mzH4gBLLCRdeAJX/lsImAn98u3wghha7pD+bp0O9d1iueMVcRpxfnOpxy3hBAoerDjOw=", pkBody)
 	})
 	t.Run("ED25519", func(t *testing.T) {
 		pk := factories.NewPubkeyED25519()
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.NoError(t, err)
 		assert.Equal(t, "gcp-user:ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEhnn80ZywmjeBFFOGm+cm+5HUwm62qTVnjKlOdYFLHN", pkBody)
 	})
 
 	t.Run("empty RSA", func(t *testing.T) {
 		pk := factories.NewEmptyPubkeyRSA()
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.EqualError(t, err, "invalid public key format")
 		assert.Equal(t, pkBody, "")
 	})
 
 	t.Run("RSA without a username", func(t *testing.T) {
 		pk := factories.NewPubkeyRSAWithoutUsername()
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.NoError(t, err)
 		assert.Equal(t, pkBody, "gcp-user:ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQC8w6DONv1qn3IdgxSpkYOClq7oe7davWFqKVHPbLoS6+dF"+
 			"Inru7gdEO5byhTih6+PwRhHv/b1I+Mtt5MDZ8Sv7XFYpX/3P/u5zQiy1PkMSFSz0brRRUfEQxhXLW97FJa7l+bej2HJ"+
@@ -91,10 +91,57 @@ func TestBodyWithUsername(t *testing.T) {
 			"M6pCff3RBslbFxLdOO7cR17")
 	})
 
+	t.Run("custom username", func(t *testing.T) {
+		pk := factories.NewPubkeyED25519()
+		pkBody, err := pk.BodyWithUsername(context.Background(), "cloud-user")
+		assert.NoError(t, err)
+		assert.Equal(t, "cloud-user:ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIEhnn80ZywmjeBFFOGm+cm+5HUwm62qTVnjKlOdYFLHN", pkBody)
+	})
+
 	t.Run("nil pk", func(t *testing.T) {
 		pk := &models.Pubkey{}
-		pkBody, err := pk.BodyWithUsername(context.Background())
+		pkBody, err := pk.BodyWithUsername(context.Background(), "gcp-user")
 		assert.EqualError(t, err, "invalid public key format")
 		assert.Equal(t, pkBody, "")
 	})
 }
+
+func TestPubkeyProviderCompatibility(t *testing.T) {
+	type test struct {
+		name     string
+		pubkey   *models.Pubkey
+		provider models.ProviderType
+		valid    bool
+	}
+
+	tests := []test{
+		{"rsa on AWS", factories.NewPubkeyRSA(), models.ProviderTypeAWS, true},
+		{"ed25519 on Azure", factories.NewPubkeyED25519(), models.ProviderTypeAzure, true},
+		{"ecdsa on AWS", factories.NewPubkeyECDSA(), models.ProviderTypeAWS, false},
+		{"ecdsa on GCP", factories.NewPubkeyECDSA(), models.ProviderTypeGCP, true},
+		{"security key on Azure", factories.NewPubkeySKED25519(), models.ProviderTypeAzure, false},
+		{"security key on GCP", factories.NewPubkeySKED25519(), models.ProviderTypeGCP, true},
+		{"certificate on GCP", factories.NewPubkeyED25519Cert(), models.ProviderTypeGCP, false},
+	}
+
+	for _, td := range tests {
+		t.Run(td.name, func(t *testing.T) {
+			pk := factories.PubkeyWithTrans(t, context.Background(), td.pubkey)
+			err := pk.CheckProviderCompatibility(td.provider)
+			if td.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrPubkeyTypeIncompatible)
+			}
+		})
+	}
+}
+
+func TestPubkeyValidType(t *testing.T) {
+	pk := factories.PubkeyWithTrans(t, context.Background(), factories.NewPubkeySKED25519())
+	assert.Nil(t, models.Validate(context.Background(), pk))
+
+	pk.Type = "ssh-dss"
+	err := models.Validate(context.Background(), pk)
+	assert.EqualError(t, err, "Key: 'Pubkey.Type' Error:Field validation for 'Type' failed on the 'sshKeyType' tag")
+}
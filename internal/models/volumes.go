@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxVolumeSizeGB is the maximum size of a single disk, it is the lowest limit of all providers (AWS EBS).
+const MaxVolumeSizeGB = 16 * 1024
+
+// MaxDataDisks is the maximum amount of additional data disks per instance.
+const MaxDataDisks = 8
+
+var (
+	ErrVolumeSizeInvalid  = fmt.Errorf("volume size must be between 1 and %d GB", MaxVolumeSizeGB)
+	ErrVolumeTypeInvalid  = errors.New("volume type is not supported by the provider")
+	ErrTooManyDataDisks   = fmt.Errorf("at most %d data disks are supported", MaxDataDisks)
+	ErrVolumesUnsupported = errors.New("volumes are not supported by the provider")
+)
+
+// volumeTypes are supported disk types for each provider, the first one is the default type of data disks.
+// AWS io1 and io2 types are not supported because they require provisioned IOPS.
+var volumeTypes = map[ProviderType][]string{
+	ProviderTypeAWS:   {"gp3", "gp2", "standard", "st1", "sc1"},
+	ProviderTypeAzure: {"Standard_LRS", "StandardSSD_LRS", "Premium_LRS", "StandardSSD_ZRS", "Premium_ZRS"},
+	ProviderTypeGCP:   {"pd-balanced", "pd-standard", "pd-ssd"},
+}
+
+// Volumes is a provider-neutral storage configuration of launched instances. Zero values mean
+// provider or image defaults.
+type Volumes struct {
+	// Size of the root (boot) disk in GB, image default when not set.
+	RootSizeGB int32 `json:"root_size_gb,omitempty" yaml:"root_size_gb"`
+
+	// Provider-specific type of the root disk: gp3, gp2 or standard (AWS), Standard_LRS, StandardSSD_LRS,
+	// Premium_LRS, StandardSSD_ZRS or Premium_ZRS (Azure), pd-balanced, pd-standard or pd-ssd (GCP).
+	RootType string `json:"root_type,omitempty" yaml:"root_type"`
+
+	// Additional empty disks attached to each instance.
+	DataDisks []DataDisk `json:"data_disks,omitempty" yaml:"data_disks"`
+
+	// Encrypt all disks with the provider-managed key. Only applies to AWS, Azure and GCP disks
+	// are always encrypted at rest.
+	Encrypted bool `json:"encrypted,omitempty" yaml:"encrypted"`
+}
+
+// DataDisk is an additional empty disk.
+type DataDisk struct {
+	// Size of the disk in GB.
+	SizeGB int32 `json:"size_gb" yaml:"size_gb"`
+
+	// Provider-specific type of the disk, see Volumes.RootType. AWS also supports st1 and sc1 types.
+	// Default types are gp3 (AWS), Standard_LRS (Azure) and pd-balanced (GCP).
+	Type string `json:"type,omitempty" yaml:"type"`
+}
+
+// Validate checks sizes and types of volumes for the provider.
+func (v *Volumes) Validate(provider ProviderType) error {
+	if v == nil {
+		return nil
+	}
+
+	types, ok := volumeTypes[provider]
+	if !ok {
+		return ErrVolumesUnsupported
+	}
+
+	if v.RootSizeGB < 0 || v.RootSizeGB > MaxVolumeSizeGB {
+		return fmt.Errorf("%w: root size %d", ErrVolumeSizeInvalid, v.RootSizeGB)
+	}
+	// throughput optimized and cold HDD types cannot be used as AWS boot volumes
+	if v.RootType != "" && (!contains(types, v.RootType) || v.RootType == "st1" || v.RootType == "sc1") {
+		return fmt.Errorf("%w: %s", ErrVolumeTypeInvalid, v.RootType)
+	}
+
+	if len(v.DataDisks) > MaxDataDisks {
+		return ErrTooManyDataDisks
+	}
+	for _, disk := range v.DataDisks {
+		if disk.SizeGB < 1 || disk.SizeGB > MaxVolumeSizeGB {
+			return fmt.Errorf("%w: data disk size %d", ErrVolumeSizeInvalid, disk.SizeGB)
+		}
+		if disk.Type != "" && !contains(types, disk.Type) {
+			return fmt.Errorf("%w: %s", ErrVolumeTypeInvalid, disk.Type)
+		}
+	}
+
+	return nil
+}
+
+// DataDiskType returns type of a data disk or the provider default type when not set.
+func DataDiskType(provider ProviderType, disk DataDisk) string {
+	if disk.Type != "" {
+		return disk.Type
+	}
+	if types, ok := volumeTypes[provider]; ok {
+		return types[0]
+	}
+	return ""
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
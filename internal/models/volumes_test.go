@@ -0,0 +1,45 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVolumesValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider models.ProviderType
+		volumes  *models.Volumes
+		err      error
+	}{
+		{"nil", models.ProviderTypeAWS, nil, nil},
+		{"AWS root", models.ProviderTypeAWS, &models.Volumes{RootSizeGB: 200, RootType: "gp3", Encrypted: true}, nil},
+		{"Azure data disks", models.ProviderTypeAzure, &models.Volumes{DataDisks: []models.DataDisk{{SizeGB: 100, Type: "Premium_LRS"}, {SizeGB: 50}}}, nil},
+		{"GCP root", models.ProviderTypeGCP, &models.Volumes{RootSizeGB: 200, RootType: "pd-ssd"}, nil},
+		{"root too large", models.ProviderTypeAWS, &models.Volumes{RootSizeGB: models.MaxVolumeSizeGB + 1}, models.ErrVolumeSizeInvalid},
+		{"negative root", models.ProviderTypeGCP, &models.Volumes{RootSizeGB: -1}, models.ErrVolumeSizeInvalid},
+		{"type of other provider", models.ProviderTypeGCP, &models.Volumes{RootType: "gp3"}, models.ErrVolumeTypeInvalid},
+		{"HDD root on AWS", models.ProviderTypeAWS, &models.Volumes{RootType: "st1"}, models.ErrVolumeTypeInvalid},
+		{"HDD data disk on AWS", models.ProviderTypeAWS, &models.Volumes{DataDisks: []models.DataDisk{{SizeGB: 500, Type: "st1"}}}, nil},
+		{"zero data disk", models.ProviderTypeAzure, &models.Volumes{DataDisks: []models.DataDisk{{SizeGB: 0}}}, models.ErrVolumeSizeInvalid},
+		{"too many data disks", models.ProviderTypeAWS, &models.Volumes{DataDisks: make([]models.DataDisk, models.MaxDataDisks+1)}, models.ErrTooManyDataDisks},
+		{"noop", models.ProviderTypeNoop, &models.Volumes{RootSizeGB: 20}, models.ErrVolumesUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.volumes.Validate(tt.provider)
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDataDiskType(t *testing.T) {
+	require.Equal(t, "gp3", models.DataDiskType(models.ProviderTypeAWS, models.DataDisk{SizeGB: 10}))
+	require.Equal(t, "pd-ssd", models.DataDiskType(models.ProviderTypeGCP, models.DataDisk{SizeGB: 10, Type: "pd-ssd"}))
+}
@@ -2,6 +2,9 @@ package page
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -22,6 +25,7 @@ const (
 	offsetCtxKey ctxKeyType = iota
 	limitCtxKey
 	tokenCtxKey
+	cursorCtxKey
 )
 
 const (
@@ -65,6 +69,11 @@ func WithToken(ctx context.Context, token string) context.Context {
 	return context.WithValue(ctx, tokenCtxKey, token)
 }
 
+// WithCursor returns context copy with keyset pagination cursor. An empty cursor lists the first page.
+func WithCursor(ctx context.Context, cursor string) context.Context {
+	return context.WithValue(ctx, cursorCtxKey, cursor)
+}
+
 func Limit(ctx context.Context) limitOffset {
 	if lim, ok := ctx.Value(limitCtxKey).(int); ok {
 		return limitOffset(lim)
@@ -86,6 +95,20 @@ func Token(ctx context.Context) string {
 	return ""
 }
 
+// CursorMode returns true when keyset pagination was requested via the cursor query parameter.
+func CursorMode(ctx context.Context) bool {
+	_, ok := ctx.Value(cursorCtxKey).(string)
+	return ok
+}
+
+// CurrentCursor returns decoded keyset pagination cursor, zero cursor is returned for the first page.
+func CurrentCursor(ctx context.Context) (Cursor, error) {
+	if cursor, ok := ctx.Value(cursorCtxKey).(string); ok && cursor != "" {
+		return DecodeCursor(cursor)
+	}
+	return Cursor{}, nil
+}
+
 func (o limitOffset) IntPtr() *int {
 	return ptr.To(int(o))
 }
@@ -116,9 +139,10 @@ func NewOffsetMetadata(ctx context.Context, r *http.Request, total int) *Metadat
 		prev = ""
 	} else {
 		prevOffset := math.Max(0, offset-limit)
-		q := url.Values{}
-		q.Add("limit", strconv.Itoa(limit))
-		q.Add("offset", strconv.Itoa(prevOffset))
+		// keep other query parameters like filters
+		q := r.URL.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("offset", strconv.Itoa(prevOffset))
 		prev = fmt.Sprintf("%v?%v", r.URL.Path, q.Encode())
 	}
 
@@ -127,9 +151,10 @@ func NewOffsetMetadata(ctx context.Context, r *http.Request, total int) *Metadat
 		next = ""
 	} else {
 		nextOffset := offset + limit
-		q := url.Values{}
-		q.Add("limit", strconv.Itoa(limit))
-		q.Add("offset", strconv.Itoa(nextOffset))
+		// keep other query parameters like filters
+		q := r.URL.Query()
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("offset", strconv.Itoa(nextOffset))
 		next = fmt.Sprintf("%v?%v", r.URL.Path, q.Encode())
 	}
 
@@ -161,3 +186,54 @@ func NewTokenMetadata(ctx context.Context, r *http.Request, nextToken string) *M
 		},
 	}
 }
+
+// NewCursorMetadata returns keyset pagination metadata, nil cursor means there is no next page.
+// Keyset pagination only walks forward, there is no link to the previous page.
+func NewCursorMetadata(ctx context.Context, r *http.Request, nextCursor *Cursor) *Metadata {
+	limit := Limit(ctx).Int()
+	var next string
+
+	if nextCursor != nil {
+		// keep other query parameters like filters
+		q := r.URL.Query()
+		q.Del("offset")
+		q.Set("limit", strconv.Itoa(limit))
+		q.Set("cursor", nextCursor.Encode())
+		next = fmt.Sprintf("%v?%v", r.URL.Path, q.Encode())
+	}
+
+	return &Metadata{
+		Links: Links{
+			Next: next,
+		},
+	}
+}
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// Cursor is a position in keyset pagination. Clients must treat encoded cursors as opaque strings.
+type Cursor struct {
+	// LastID is the ID of the last record of the previous page.
+	LastID int64 `json:"id"`
+}
+
+// Encode returns opaque URL-safe representation of the cursor.
+func (c Cursor) Encode() string {
+	buf, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// DecodeCursor parses cursor created by Cursor.Encode.
+func DecodeCursor(str string) (Cursor, error) {
+	var cursor Cursor
+	buf, err := base64.RawURLEncoding.DecodeString(str)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: %s", ErrInvalidCursor, err.Error())
+	}
+
+	err = json.Unmarshal(buf, &cursor)
+	if err != nil || cursor.LastID <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cursor, nil
+}
@@ -0,0 +1,97 @@
+package payloads
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/go-chi/render"
+)
+
+// See clients.Network
+type NetworkResponse struct {
+	ID      string `json:"id" yaml:"id"`
+	Name    string `json:"name" yaml:"name"`
+	CIDR    string `json:"cidr" yaml:"cidr"`
+	Default bool   `json:"default" yaml:"default"`
+}
+
+type NetworkListResponse struct {
+	Data []*NetworkResponse `json:"data" yaml:"data"`
+}
+
+// See clients.Subnet
+type SubnetResponse struct {
+	ID        string `json:"id" yaml:"id"`
+	Name      string `json:"name" yaml:"name"`
+	NetworkID string `json:"network_id" yaml:"network_id"`
+	CIDR      string `json:"cidr" yaml:"cidr"`
+	Zone      string `json:"zone" yaml:"zone"`
+}
+
+type SubnetListResponse struct {
+	Data []*SubnetResponse `json:"data" yaml:"data"`
+}
+
+// See clients.SecurityGroup
+type SecurityGroupResponse struct {
+	ID          string `json:"id" yaml:"id"`
+	Name        string `json:"name" yaml:"name"`
+	NetworkID   string `json:"network_id" yaml:"network_id"`
+	Description string `json:"description" yaml:"description"`
+}
+
+type SecurityGroupListResponse struct {
+	Data []*SecurityGroupResponse `json:"data" yaml:"data"`
+}
+
+func (s *NetworkListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func (s *SubnetListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func (s *SecurityGroupListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+func NewNetworkListResponse(networks []*clients.Network) render.Renderer {
+	list := make([]*NetworkResponse, len(networks))
+	for i, network := range networks {
+		list[i] = &NetworkResponse{
+			ID:      network.ID,
+			Name:    network.Name,
+			CIDR:    network.CIDR,
+			Default: network.Default,
+		}
+	}
+	return &NetworkListResponse{Data: list}
+}
+
+func NewSubnetListResponse(subnets []*clients.Subnet) render.Renderer {
+	list := make([]*SubnetResponse, len(subnets))
+	for i, subnet := range subnets {
+		list[i] = &SubnetResponse{
+			ID:        subnet.ID,
+			Name:      subnet.Name,
+			NetworkID: subnet.NetworkID,
+			CIDR:      subnet.CIDR,
+			Zone:      subnet.Zone,
+		}
+	}
+	return &SubnetListResponse{Data: list}
+}
+
+func NewSecurityGroupListResponse(groups []*clients.SecurityGroup) render.Renderer {
+	list := make([]*SecurityGroupResponse, len(groups))
+	for i, group := range groups {
+		list[i] = &SecurityGroupResponse{
+			ID:          group.ID,
+			Name:        group.Name,
+			NetworkID:   group.NetworkID,
+			Description: group.Description,
+		}
+	}
+	return &SecurityGroupListResponse{Data: list}
+}
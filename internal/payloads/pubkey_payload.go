@@ -2,6 +2,7 @@ package payloads
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
@@ -15,6 +16,17 @@ type PubkeyRequest struct {
 	Body string `json:"body" yaml:"body" description:"Add a public part of a SSH key pair."`
 }
 
+// PubkeyUpdateRequest renames an existing pubkey, the body cannot be changed.
+type PubkeyUpdateRequest struct {
+	Name string `json:"name" yaml:"name" description:"Enter the new name of the pubkey. Key-pairs uploaded to AWS are renamed as well."`
+}
+
+// PubkeyGenerateRequest generates a new SSH key pair on the server.
+type PubkeyGenerateRequest struct {
+	Name string `json:"name" yaml:"name" description:"Enter the name of the newly generated pubkey."`
+	Type string `json:"type,omitempty" yaml:"type,omitempty" description:"Type of the generated key pair: ssh-ed25519 (default) or ssh-rsa."`
+}
+
 // See models.Pubkey
 type PubkeyResponse struct {
 	ID                int64  `json:"id" yaml:"id"`
@@ -24,7 +36,38 @@ type PubkeyResponse struct {
 	Type              string `json:"type,omitempty" yaml:"type,omitempty"`
 	Fingerprint       string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
 	FingerprintLegacy string `json:"fingerprint_legacy,omitempty" yaml:"fingerprint_legacy,omitempty"`
+
+	// Cloud uploads of the pubkey, only returned for a single pubkey.
+	Resources []*PubkeyResourceResponse `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+// PubkeyResourceResponse is an upload of a pubkey into a cloud provider account and region.
+// See models.PubkeyResource
+type PubkeyResourceResponse struct {
+	Provider string `json:"provider" yaml:"provider" description:"Cloud provider type."`
+	SourceID string `json:"source_id" yaml:"source_id"`
+	Region   string `json:"region" yaml:"region"`
+	Handle   string `json:"handle" yaml:"handle" description:"Cloud identifier of the uploaded key, empty when the key was uploaded by the user."`
+
+	// Status is "verified" when the key was found in the cloud during the last check or "unverified"
+	// when it was not checked yet. Keys which are no longer present are removed.
+	Status     string     `json:"status" yaml:"status" description:"Upload status: verified or unverified."`
+	VerifiedAt *time.Time `json:"verified_at" nullable:"true" yaml:"verified_at" description:"Time of the last successful check."`
+}
+
+// PubkeyGenerateResponse contains the stored public key and the private key which is not stored
+// and is returned only once.
+type PubkeyGenerateResponse struct {
+	ID                int64  `json:"id" yaml:"id"`
+	AccountID         int64  `json:"-" yaml:"-"`
+	Name              string `json:"name" yaml:"name"`
+	Body              string `json:"body" yaml:"body"`
+	Type              string `json:"type,omitempty" yaml:"type,omitempty"`
+	Fingerprint       string `json:"fingerprint,omitempty" yaml:"fingerprint,omitempty"`
+	FingerprintLegacy string `json:"fingerprint_legacy,omitempty" yaml:"fingerprint_legacy,omitempty"`
+	PrivateKey        string `json:"private_key" yaml:"private_key" description:"Private part of the generated SSH key pair in OpenSSH format. It is not stored and cannot be retrieved again."`
 }
+
 type PubkeyListResponse struct {
 	Data     []*PubkeyResponse `json:"data" yaml:"data"`
 	Metadata page.Metadata     `json:"metadata" yaml:"metadata"`
@@ -34,10 +77,22 @@ func (p *PubkeyRequest) Bind(_ *http.Request) error {
 	return nil
 }
 
+func (p *PubkeyUpdateRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
+func (p *PubkeyGenerateRequest) Bind(_ *http.Request) error {
+	return nil
+}
+
 func (p *PubkeyResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
 
+func (p *PubkeyGenerateResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 func (p *PubkeyListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
@@ -61,6 +116,43 @@ func NewPubkeyResponse(pubkey *models.Pubkey) *PubkeyResponse {
 	}
 }
 
+func NewPubkeyResponseWithResources(pubkey *models.Pubkey, resources []*models.PubkeyResource) *PubkeyResponse {
+	response := NewPubkeyResponse(pubkey)
+	response.Resources = make([]*PubkeyResourceResponse, len(resources))
+	for i, res := range resources {
+		response.Resources[i] = NewPubkeyResourceResponse(res)
+	}
+	return response
+}
+
+func NewPubkeyResourceResponse(res *models.PubkeyResource) *PubkeyResourceResponse {
+	response := &PubkeyResourceResponse{
+		Provider: res.Provider.String(),
+		SourceID: res.SourceID,
+		Region:   res.Region,
+		Handle:   res.Handle,
+		Status:   "unverified",
+	}
+	if res.VerifiedAt.Valid {
+		response.Status = "verified"
+		response.VerifiedAt = &res.VerifiedAt.Time
+	}
+	return response
+}
+
+func NewPubkeyGenerateResponse(pubkey *models.Pubkey, privateKey string) *PubkeyGenerateResponse {
+	return &PubkeyGenerateResponse{
+		ID:                pubkey.ID,
+		AccountID:         pubkey.AccountID,
+		Name:              pubkey.Name,
+		Body:              pubkey.Body,
+		Type:              pubkey.Type,
+		Fingerprint:       pubkey.Fingerprint,
+		FingerprintLegacy: pubkey.FingerprintLegacy,
+		PrivateKey:        privateKey,
+	}
+}
+
 func NewPubkeyListResponse(pubkeys []*models.Pubkey, meta *page.Metadata) render.Renderer {
 	list := make([]*PubkeyResponse, len(pubkeys))
 	for i, pubkey := range pubkeys {
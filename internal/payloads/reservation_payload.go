@@ -39,6 +39,15 @@ type GenericReservationResponse struct {
 	// Time when reservation was finished or nil when it's still processing.
 	FinishedAt *time.Time `json:"finished_at" nullable:"true" yaml:"finished_at"`
 
+	// Time when reservation was cancelled or nil when it was not cancelled.
+	CancelledAt *time.Time `json:"cancelled_at" nullable:"true" yaml:"cancelled_at"`
+
+	// Time when instances of the reservation are terminated or nil when the reservation never expires.
+	ExpiresAt *time.Time `json:"expires_at" nullable:"true" yaml:"expires_at"`
+
+	// Time when instances were terminated because the reservation expired or nil when not yet expired.
+	ExpiredAt *time.Time `json:"expired_at" nullable:"true" yaml:"expired_at"`
+
 	// Flag indicating success, error or unknown state (NULL). See Status for the actual error.
 	Success *bool `json:"success" nullable:"true" yaml:"success"`
 }
@@ -51,6 +60,10 @@ type InstanceResponse struct {
 	Detail models.ReservationInstanceDetail `json:"detail" yaml:"detail"`
 }
 
+type InstanceListResponse struct {
+	Data []*InstanceResponse `json:"data" yaml:"data"`
+}
+
 type AWSReservationResponse struct {
 	ID int64 `json:"reservation_id" yaml:"reservation_id"`
 
@@ -84,6 +97,30 @@ type AWSReservationResponse struct {
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Subnet ID the instance(s) are launched into, empty for the default VPC.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id"`
+
+	// Security group IDs assigned to the instance(s).
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty" yaml:"security_group_ids"`
+
+	// Public IP address assignment, missing when the subnet setting is used.
+	AssociatePublicIP *bool `json:"associate_public_ip,omitempty" nullable:"true" yaml:"associate_public_ip"`
+
+	// Storage configuration, missing for image defaults.
+	Volumes *models.Volumes `json:"volumes,omitempty" nullable:"true" yaml:"volumes"`
+
+	// User-defined tags, missing when not set.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
+	// Pricing model of the instances: on-demand or spot.
+	PricingModel models.PricingModel `json:"pricing_model" yaml:"pricing_model"`
+
+	// Spot configuration, missing for on-demand instances.
+	Spot *models.SpotOptions `json:"spot,omitempty" nullable:"true" yaml:"spot"`
+
+	// Zone placement of the instances, missing when not set.
+	Placement *models.Placement `json:"placement,omitempty" nullable:"true" yaml:"placement"`
+
 	// Instances array, only present for finished reservations
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -101,6 +138,18 @@ type AzureReservationResponse struct {
 	// Azure Instance size.
 	InstanceSize string `json:"instance_size" yaml:"instance_size"`
 
+	// Template Spec resource ID, empty when no template was used.
+	LaunchTemplateID string `json:"launch_template_id,omitempty" yaml:"launch_template_id"`
+
+	// Virtual network resource ID, empty for the shared network.
+	NetworkID string `json:"network_id,omitempty" yaml:"network_id"`
+
+	// Subnet resource ID, empty for the shared network or the first subnet of the network.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id"`
+
+	// Network security group resource ID, empty for the shared one or for subnet rules.
+	SecurityGroupID string `json:"security_group_id,omitempty" yaml:"security_group_id"`
+
 	// Amount of instances to provision of type: Instance type.
 	Amount int64 `json:"amount" yaml:"amount"`
 
@@ -113,6 +162,21 @@ type AzureReservationResponse struct {
 	// Immediately PowerOff the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Storage configuration, missing for image defaults.
+	Volumes *models.Volumes `json:"volumes,omitempty" nullable:"true" yaml:"volumes"`
+
+	// User-defined tags, missing when not set.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags"`
+
+	// Pricing model of the instances: on-demand or spot.
+	PricingModel models.PricingModel `json:"pricing_model" yaml:"pricing_model"`
+
+	// Spot configuration, missing for on-demand instances.
+	Spot *models.SpotOptions `json:"spot,omitempty" nullable:"true" yaml:"spot"`
+
+	// Zone placement of the instances, missing when not set.
+	Placement *models.Placement `json:"placement,omitempty" nullable:"true" yaml:"placement"`
+
 	// Instances IDs, only present for finished reservations.
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -150,6 +214,30 @@ type GCPReservationResponse struct {
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
 
+	// Storage configuration, missing for image defaults.
+	Volumes *models.Volumes `json:"volumes,omitempty" nullable:"true" yaml:"volumes"`
+
+	// User-defined labels, missing when not set.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels"`
+
+	// Pricing model of the instances: on-demand or spot.
+	PricingModel models.PricingModel `json:"pricing_model" yaml:"pricing_model"`
+
+	// Spot configuration, missing for on-demand instances.
+	Spot *models.SpotOptions `json:"spot,omitempty" nullable:"true" yaml:"spot"`
+
+	// Zone placement of the instances, missing when not set.
+	Placement *models.Placement `json:"placement,omitempty" nullable:"true" yaml:"placement"`
+
+	// Network ID, missing for the default network.
+	NetworkID string `json:"network_id,omitempty" yaml:"network_id"`
+
+	// Subnetwork ID, missing when not set.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id"`
+
+	// External IP assignment, missing when the default (external IP) is used.
+	ExternalIP *bool `json:"external_ip,omitempty" nullable:"true" yaml:"external_ip"`
+
 	// Instances IDs, only present for finished reservations.
 	Instances []InstanceResponse `json:"instances,omitempty" yaml:"instances"`
 }
@@ -185,6 +273,43 @@ type AWSReservationRequest struct {
 
 	// Immediately power off the system after initialization
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Optional time when the instance(s) are terminated. Mutually exclusive with ttl.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" nullable:"true" yaml:"expires_at"`
+
+	// Optional time-to-live of the instance(s) in duration format (e.g. "8h" or "90m"). The instance(s)
+	// are terminated when it elapses. Mutually exclusive with expires_at.
+	TTL string `json:"ttl,omitempty" yaml:"ttl"`
+
+	// Optional custom user data (max. 12 kB) merged with the generated cloud-init configuration. Either
+	// a cloud-config YAML document or a shell script starting with #!.
+	UserData string `json:"user_data,omitempty" yaml:"user_data"`
+
+	// Optional subnet ID ("subnet-06e6b4eb3f8b6a0fb") to launch the instance(s) into. Required for regions
+	// without a default VPC unless the launch template provides one.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id"`
+
+	// Optional list of security group IDs ("sg-0d2e7b9f3c1a8e4b5") from the VPC of the subnet.
+	SecurityGroupIDs []string `json:"security_group_ids,omitempty" yaml:"security_group_ids"`
+
+	// Optional public IP address assignment, the subnet setting is used when not set.
+	AssociatePublicIP *bool `json:"associate_public_ip,omitempty" nullable:"true" yaml:"associate_public_ip"`
+
+	// Optional storage configuration: root disk size and type, additional data disks and encryption.
+	Volumes *models.Volumes `json:"volumes,omitempty" nullable:"true" yaml:"volumes"`
+
+	// Optional tags (max. 40) applied to instances, volumes and network interfaces. Keys must not start
+	// with "rh-" or "aws:" and Name is set from the name attribute.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags" description:"Key-value string tags applied to all launched resources. Keys must not start with rh-."`
+
+	// Optional spot (preemptible on GCP) configuration with a max price, on-demand instances are launched
+	// when not set. Spot instances are much cheaper but can be interrupted at any time.
+	Spot *models.SpotOptions `json:"spot,omitempty" nullable:"true" yaml:"spot" description:"Launch spot (preemptible) instances, these can be interrupted by the provider. On-demand instances are launched when not set."`
+
+	// Optional zone placement: single (default) or spread strategy with a list of availability zones
+	// ("us-east-1a") of the region. Spread distributes the amount of instances round-robin across the
+	// zones, it cannot be combined with subnet_id because subnets belong to a single zone.
+	Placement *models.Placement `json:"placement,omitempty" nullable:"true" yaml:"placement" description:"Launch the instances into availability zones of the region, spread strategy distributes them round-robin across the zones."`
 }
 
 type AzureReservationRequest struct {
@@ -204,6 +329,23 @@ type AzureReservationRequest struct {
 	// Azure Instance type.
 	InstanceSize string `json:"instance_size" yaml:"instance_size"`
 
+	// Optional Template Spec or Template Spec version resource ID, the latest version of a Template Spec is deployed.
+	// The template must declare vmName parameter used to name the virtual machine. Well-known parameters location,
+	// vmSize, imageId, adminUsername, authenticationType, adminPasswordOrKey, sshPublicKey, customData, userData
+	// and tags are set when declared. Volumes, spot and networking configuration is defined by the template.
+	LaunchTemplateID string `json:"launch_template_id,omitempty" yaml:"launch_template_id"`
+
+	// Optional resource ID of an existing virtual network. Its first subnet is used unless subnet_id is set.
+	// The shared redhat-vnet network is created in the resource group when neither network_id nor subnet_id is set.
+	NetworkID string `json:"network_id,omitempty" yaml:"network_id" description:"Resource ID of an existing virtual network, the shared network is created in the resource group when not set."`
+
+	// Optional resource ID of an existing subnet, it must belong to network_id when both are set.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id" description:"Resource ID of an existing subnet, it must belong to network_id when both are set."`
+
+	// Optional resource ID of an existing network security group. Existing subnets without a security group
+	// use the subnet rules, the shared network uses the shared redhat-nsg group.
+	SecurityGroupID string `json:"security_group_id,omitempty" yaml:"security_group_id" description:"Resource ID of an existing network security group."`
+
 	// Amount of instances to provision of size: InstanceSize.
 	Amount int64 `json:"amount" yaml:"amount"`
 
@@ -212,6 +354,33 @@ type AzureReservationRequest struct {
 
 	// Immediately power off the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Optional time when the instance(s) are terminated. Mutually exclusive with ttl.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" nullable:"true" yaml:"expires_at"`
+
+	// Optional time-to-live of the instance(s) in duration format (e.g. "8h" or "90m"). The instance(s)
+	// are terminated when it elapses. Mutually exclusive with expires_at.
+	TTL string `json:"ttl,omitempty" yaml:"ttl"`
+
+	// Optional custom user data (max. 12 kB) merged with the generated cloud-init configuration. Either
+	// a cloud-config YAML document or a shell script starting with #!.
+	UserData string `json:"user_data,omitempty" yaml:"user_data"`
+
+	// Optional storage configuration: root disk size and type, additional data disks and encryption.
+	Volumes *models.Volumes `json:"volumes,omitempty" nullable:"true" yaml:"volumes"`
+
+	// Optional tags (max. 40) applied to instances, disks, network interfaces and public IP addresses.
+	// Keys must not start with "rh-".
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags" description:"Key-value string tags applied to all launched resources. Keys must not start with rh-."`
+
+	// Optional spot (preemptible on GCP) configuration with a max price, on-demand instances are launched
+	// when not set. Spot instances are much cheaper but can be interrupted at any time.
+	Spot *models.SpotOptions `json:"spot,omitempty" nullable:"true" yaml:"spot" description:"Launch spot (preemptible) instances, these can be interrupted by the provider. On-demand instances are launched when not set."`
+
+	// Optional zone placement: single (default) or spread strategy with a list of availability zones
+	// ("1", "2" or "3") of the location. Spread distributes the amount of instances round-robin across
+	// the zones. Not supported with launch templates.
+	Placement *models.Placement `json:"placement,omitempty" nullable:"true" yaml:"placement" description:"Launch the instances into availability zones of the location, spread strategy distributes them round-robin across the zones."`
 }
 
 type GCPReservationRequest struct {
@@ -241,6 +410,45 @@ type GCPReservationRequest struct {
 
 	// Immediately power off the system after initialization.
 	PowerOff bool `json:"poweroff" yaml:"poweroff"`
+
+	// Optional time when the instance(s) are terminated. Mutually exclusive with ttl.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" nullable:"true" yaml:"expires_at"`
+
+	// Optional time-to-live of the instance(s) in duration format (e.g. "8h" or "90m"). The instance(s)
+	// are terminated when it elapses. Mutually exclusive with expires_at.
+	TTL string `json:"ttl,omitempty" yaml:"ttl"`
+
+	// Optional custom sh or bash script (max. 12 kB) starting with #! which is appended to the generated
+	// startup script.
+	UserData string `json:"user_data,omitempty" yaml:"user_data"`
+
+	// Optional storage configuration: boot disk size and type, additional data disks. Only applies when
+	// an image is launched, launch template disks are not changed.
+	Volumes *models.Volumes `json:"volumes,omitempty" nullable:"true" yaml:"volumes"`
+
+	// Optional labels (max. 40) applied to instances and disks. Keys and values may only contain lowercase
+	// letters, numbers, underscores and dashes, keys must start with a letter and not with "rh-".
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels" description:"Key-value string labels applied to all launched resources. Keys must not start with rh-."`
+
+	// Optional spot (preemptible on GCP) configuration with a max price, on-demand instances are launched
+	// when not set. Spot instances are much cheaper but can be interrupted at any time.
+	Spot *models.SpotOptions `json:"spot,omitempty" nullable:"true" yaml:"spot" description:"Launch spot (preemptible) instances, these can be interrupted by the provider. On-demand instances are launched when not set."`
+
+	// Optional zone placement: single (default) or spread strategy with a list of zones ("us-east1-b")
+	// in the region of the zone. Spread distributes the amount of instances round-robin across the zones.
+	Placement *models.Placement `json:"placement,omitempty" nullable:"true" yaml:"placement" description:"Launch the instances into zones of the region, spread strategy distributes them round-robin across the zones."`
+
+	// Optional network ID (global/networks/NAME) from the networks list, the default network is used when
+	// neither network_id nor subnet_id are set.
+	NetworkID string `json:"network_id,omitempty" yaml:"network_id" description:"Network ID in the form of global/networks/NAME, the default network is used when not set."`
+
+	// Optional subnetwork ID (regions/REGION/subnetworks/NAME) from the subnets list. It must be in the region
+	// of the zone, the network is inferred from the subnetwork when network_id is not set.
+	SubnetID string `json:"subnet_id,omitempty" yaml:"subnet_id" description:"Subnetwork ID in the form of regions/REGION/subnetworks/NAME in the region of the zone."`
+
+	// Optional external IP assignment, set to false to launch instances with internal IP addresses only.
+	// Ephemeral external IP addresses are assigned when not set.
+	ExternalIP *bool `json:"external_ip,omitempty" nullable:"true" yaml:"external_ip" description:"Assign ephemeral external IP addresses, true when not set."`
 }
 
 type GenericReservationListResponse struct {
@@ -276,6 +484,10 @@ func (p *NoopReservationResponse) Render(_ http.ResponseWriter, _ *http.Request)
 	return nil
 }
 
+func (p *InstanceListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 func (p *GenericReservationListResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
@@ -295,17 +507,25 @@ func NewAWSReservationResponse(reservation *models.AWSReservation, instances []*
 	}
 
 	response := AWSReservationResponse{
-		PubkeyID:         reservation.PubkeyID,
-		ImageID:          reservation.ImageID,
-		SourceID:         reservation.SourceID,
-		Region:           reservation.Detail.Region,
-		Amount:           reservation.Detail.Amount,
-		InstanceType:     reservation.Detail.InstanceType,
-		ID:               reservation.ID,
-		Name:             reservation.Detail.Name,
-		PowerOff:         reservation.Detail.PowerOff,
-		Instances:        instancesResponse,
-		LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+		PubkeyID:          reservation.PubkeyID,
+		ImageID:           reservation.ImageID,
+		SourceID:          reservation.SourceID,
+		Region:            reservation.Detail.Region,
+		Amount:            reservation.Detail.Amount,
+		InstanceType:      reservation.Detail.InstanceType,
+		ID:                reservation.ID,
+		Name:              reservation.Detail.Name,
+		PowerOff:          reservation.Detail.PowerOff,
+		Instances:         instancesResponse,
+		LaunchTemplateID:  reservation.Detail.LaunchTemplateID,
+		SubnetID:          reservation.Detail.SubnetID,
+		SecurityGroupIDs:  reservation.Detail.SecurityGroupIDs,
+		AssociatePublicIP: reservation.Detail.AssociatePublicIP,
+		Volumes:           reservation.Detail.Volumes,
+		Tags:              reservation.Detail.Tags,
+		PricingModel:      reservation.Detail.Spot.PricingModel(),
+		Spot:              reservation.Detail.Spot,
+		Placement:         reservation.Detail.Placement,
 	}
 	if reservation.AWSReservationID != nil {
 		response.AWSReservationID = *reservation.AWSReservationID
@@ -320,16 +540,25 @@ func NewAzureReservationResponse(reservation *models.AzureReservation, instances
 	}
 
 	response := AzureReservationResponse{
-		PubkeyID:     reservation.PubkeyID,
-		ImageID:      reservation.ImageID,
-		SourceID:     reservation.SourceID,
-		Location:     reservation.Detail.Location,
-		Amount:       reservation.Detail.Amount,
-		InstanceSize: reservation.Detail.InstanceSize,
-		ID:           reservation.ID,
-		Name:         reservation.Detail.Name,
-		PowerOff:     reservation.Detail.PowerOff,
-		Instances:    instanceIds,
+		PubkeyID:         reservation.PubkeyID,
+		ImageID:          reservation.ImageID,
+		SourceID:         reservation.SourceID,
+		Location:         reservation.Detail.Location,
+		Amount:           reservation.Detail.Amount,
+		InstanceSize:     reservation.Detail.InstanceSize,
+		ID:               reservation.ID,
+		Name:             reservation.Detail.Name,
+		PowerOff:         reservation.Detail.PowerOff,
+		Volumes:          reservation.Detail.Volumes,
+		Tags:             reservation.Detail.Tags,
+		LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+		NetworkID:        reservation.Detail.NetworkID,
+		SubnetID:         reservation.Detail.SubnetID,
+		SecurityGroupID:  reservation.Detail.SecurityGroupID,
+		PricingModel:     reservation.Detail.Spot.PricingModel(),
+		Spot:             reservation.Detail.Spot,
+		Placement:        reservation.Detail.Placement,
+		Instances:        instanceIds,
 	}
 	return &response
 }
@@ -356,6 +585,14 @@ func NewGCPReservationResponse(reservation *models.GCPReservation, instances []*
 		PowerOff:         reservation.Detail.PowerOff,
 		Instances:        instanceIds,
 		LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+		Volumes:          reservation.Detail.Volumes,
+		Labels:           reservation.Detail.Labels,
+		PricingModel:     reservation.Detail.Spot.PricingModel(),
+		Spot:             reservation.Detail.Spot,
+		NetworkID:        reservation.Detail.NetworkID,
+		SubnetID:         reservation.Detail.SubnetID,
+		ExternalIP:       reservation.Detail.ExternalIP,
+		Placement:        reservation.Detail.Placement,
 	}
 	return &response
 }
@@ -374,25 +611,48 @@ func NewReservationListResponse(reservations []*models.Reservation, meta *page.M
 	return &GenericReservationListResponse{Data: list, Metadata: *meta}
 }
 
+func NewInstanceListResponse(instances []*models.ReservationInstance) render.Renderer {
+	list := make([]*InstanceResponse, len(instances))
+	for i, instance := range instances {
+		list[i] = &InstanceResponse{InstanceID: instance.InstanceID, Detail: instance.Detail}
+	}
+	return &InstanceListResponse{Data: list}
+}
+
 func reservationResponseMapper(reservation *models.Reservation) *GenericReservationResponse {
 	var finishedAt *time.Time
 	if reservation.FinishedAt.Valid {
 		finishedAt = &reservation.FinishedAt.Time
 	}
+	var cancelledAt *time.Time
+	if reservation.CancelledAt.Valid {
+		cancelledAt = &reservation.CancelledAt.Time
+	}
+	var expiresAt *time.Time
+	if reservation.ExpiresAt.Valid {
+		expiresAt = &reservation.ExpiresAt.Time
+	}
+	var expiredAt *time.Time
+	if reservation.ExpiredAt.Valid {
+		expiredAt = &reservation.ExpiredAt.Time
+	}
 	var success *bool
 	if reservation.Success.Valid {
 		success = &reservation.Success.Bool
 	}
 	return &GenericReservationResponse{
-		ID:         reservation.ID,
-		Provider:   int(reservation.Provider),
-		CreatedAt:  reservation.CreatedAt,
-		FinishedAt: finishedAt,
-		Status:     reservation.Status,
-		Success:    success,
-		Steps:      reservation.Steps,
-		Step:       reservation.Step,
-		StepTitles: reservation.StepTitles,
-		Error:      reservation.Error,
+		ID:          reservation.ID,
+		Provider:    int(reservation.Provider),
+		CreatedAt:   reservation.CreatedAt,
+		FinishedAt:  finishedAt,
+		CancelledAt: cancelledAt,
+		ExpiresAt:   expiresAt,
+		ExpiredAt:   expiredAt,
+		Status:      reservation.Status,
+		Success:     success,
+		Steps:       reservation.Steps,
+		Step:        reservation.Step,
+		StepTitles:  reservation.StepTitles,
+		Error:       reservation.Error,
 	}
 }
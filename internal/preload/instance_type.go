@@ -3,6 +3,8 @@ package preload
 import (
 	"fmt"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/middleware"
 )
@@ -87,3 +89,12 @@ func (p *instanceType) ValidateRegion(region string) bool {
 	}
 	return false
 }
+
+// IsTypeAvailable checks if an instance type is available in a preloaded region and zone.
+func (p *instanceType) IsTypeAvailable(region, zone string, name clients.InstanceTypeName) bool {
+	names, err := p.typeInfo.RegionalAvailability.NamesForZone(region, zone)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(names, name)
+}
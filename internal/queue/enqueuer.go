@@ -2,8 +2,26 @@ package queue
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/jackc/pgx/v5"
 )
 
 var GetEnqueuer func(ctx context.Context) worker.JobEnqueuer
+
+// EnqueueTx enqueues the job within the transaction when the job queue supports it and returns true.
+// Otherwise, it returns false and the job must be enqueued via GetEnqueuer after the transaction is
+// committed.
+func EnqueueTx(ctx context.Context, tx pgx.Tx, job *worker.Job) (bool, error) {
+	txEnqueuer, ok := GetEnqueuer(ctx).(worker.TxEnqueuer)
+	if !ok {
+		return false, nil
+	}
+
+	err := txEnqueuer.EnqueueTx(ctx, tx, job)
+	if err != nil {
+		return false, fmt.Errorf("unable to enqueue job within transaction: %w", err)
+	}
+	return true, nil
+}
@@ -2,12 +2,15 @@ package jq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
 	"github.com/RHEnVision/provisioning-backend/internal/jobs"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
@@ -16,20 +19,35 @@ var (
 	workers  worker.JobWorker
 )
 
+var ErrDeadLetterUnsupported = errors.New("job queue does not support dead-letter list")
+
 func getEnqueuer(_ context.Context) worker.JobEnqueuer {
 	return enqueuer
 }
 
-func init() {
-	queue.GetEnqueuer = getEnqueuer
-}
-
 func RegisterJobs(logger *zerolog.Logger) {
 	logger.Debug().Msg("Registering job queue handlers and interfaces")
 	workers.RegisterHandler(jobs.TypeNoop, jobs.HandleNoop, jobs.NoopJobArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceAws, jobs.HandleLaunchInstanceAWS, jobs.LaunchInstanceAWSTaskArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceAzure, jobs.HandleLaunchInstanceAzure, jobs.LaunchInstanceAzureTaskArgs{})
 	workers.RegisterHandler(jobs.TypeLaunchInstanceGcp, jobs.HandleLaunchInstanceGCP, jobs.LaunchInstanceGCPTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionAws, jobs.HandleInstanceActionAWS, jobs.InstanceActionAWSTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionAzure, jobs.HandleInstanceActionAzure, jobs.InstanceActionAzureTaskArgs{})
+	workers.RegisterHandler(jobs.TypeInstanceActionGcp, jobs.HandleInstanceActionGCP, jobs.InstanceActionGCPTaskArgs{})
+
+	// Jobs are retried when their handler returns an error (e.g. a timeout) or when a worker crashes.
+	// Noop jobs are never retried, launch jobs only launch instances missing after previous attempts
+	// and instance actions are idempotent.
+	retry := worker.RetryPolicy{
+		MaxAttempts: config.Worker.MaxAttempts,
+		Backoff:     config.Worker.RetryBackoff,
+	}
+	workers.SetRetryPolicy(jobs.TypeLaunchInstanceAws, retry)
+	workers.SetRetryPolicy(jobs.TypeLaunchInstanceAzure, retry)
+	workers.SetRetryPolicy(jobs.TypeLaunchInstanceGcp, retry)
+	workers.SetRetryPolicy(jobs.TypeInstanceActionAws, retry)
+	workers.SetRetryPolicy(jobs.TypeInstanceActionAzure, retry)
+	workers.SetRetryPolicy(jobs.TypeInstanceActionGcp, retry)
 }
 
 func Initialize(_ context.Context, logger *zerolog.Logger) error {
@@ -50,10 +68,21 @@ func Initialize(_ context.Context, logger *zerolog.Logger) error {
 		}
 		enqueuer = wk
 		workers = wk
+	case "postgres":
+		wk, err := worker.NewPostgresWorker(db.Pool, config.Worker.PollInterval, config.Worker.Concurrency)
+		if err != nil {
+			return fmt.Errorf("cannot initialize postgres worker queue: %w", err)
+		}
+		enqueuer = wk
+		workers = wk
 	default:
 		panic("unknown WORKER_QUEUE setting, expected values: memory, redis, postgres")
 	}
 
+	// set during initialization rather than in init function, so the package can be linked into
+	// tests without replacing the enqueuer stub
+	queue.GetEnqueuer = getEnqueuer
+
 	return nil
 }
 
@@ -69,6 +98,34 @@ func StopDequeueLoop(ctx context.Context) {
 	workers.Stop(ctx)
 }
 
+// DeadLetters returns jobs from the dead-letter list or an error when the queue does not support it.
+func DeadLetters(ctx context.Context) ([]*worker.Job, error) {
+	dlq, ok := workers.(worker.DeadLetterQueue)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrDeadLetterUnsupported, config.Worker.Queue)
+	}
+
+	jobs, err := dlq.DeadLetters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list dead-letter jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// Redrive moves a job (or all jobs for uuid.Nil) from the dead-letter list back into the queue.
+func Redrive(ctx context.Context, id uuid.UUID) (int, error) {
+	dlq, ok := workers.(worker.DeadLetterQueue)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrDeadLetterUnsupported, config.Worker.Queue)
+	}
+
+	count, err := dlq.Redrive(ctx, id)
+	if err != nil {
+		return count, fmt.Errorf("cannot redrive dead-letter jobs: %w", err)
+	}
+	return count, nil
+}
+
 func Stats(ctx context.Context) worker.Stats {
 	stats, err := workers.Stats(ctx)
 	if err != nil {
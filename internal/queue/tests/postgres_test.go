@@ -0,0 +1,134 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/db"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errRollback = errors.New("rollback")
+
+// startPostgresWorker starts a separate Postgres worker, the shared job queue is Redis
+func startPostgresWorker(t *testing.T, ctx context.Context) *worker.PostgresWorker {
+	wk, err := worker.NewPostgresWorker(db.Pool, 100*time.Millisecond, 1)
+	require.NoError(t, err)
+	wk.RegisterHandler(jobs.TypeNoop, jobs.HandleNoop, jobs.NoopJobArgs{})
+	wk.DequeueLoop(ctx)
+	return wk
+}
+
+func createNoopReservation(t *testing.T) *models.NoopReservation {
+	reservationDao, ctx := getReservationDao(t)
+	res := &models.NoopReservation{
+		Reservation: models.Reservation{
+			AccountID:  1,
+			Steps:      1,
+			StepTitles: []string{"Test step"},
+			Provider:   models.ProviderTypeNoop,
+			Status:     "Created",
+		},
+	}
+	err := reservationDao.CreateNoop(ctx, res)
+	require.NoError(t, err)
+	require.NotZero(t, res.ID)
+	return res
+}
+
+func TestPostgresNoopTransaction(t *testing.T) {
+	_, ctx := getReservationDao(t)
+	defer reset()
+	wk := startPostgresWorker(t, ctx)
+	defer wk.Stop(ctx)
+
+	t.Run("rollback", func(t *testing.T) {
+		res := createNoopReservation(t)
+		job := worker.Job{
+			AccountID: 1,
+			Type:      jobs.TypeNoop,
+			Args: jobs.NoopJobArgs{
+				ReservationID: res.ID,
+			},
+		}
+
+		err := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+			require.NoError(t, wk.EnqueueTx(ctx, tx, &job))
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+
+		stats, err := wk.Stats(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, stats.EnqueuedJobs)
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		res := createNoopReservation(t)
+		job := worker.Job{
+			AccountID: 1,
+			Type:      jobs.TypeNoop,
+			Args: jobs.NoopJobArgs{
+				ReservationID: res.ID,
+			},
+		}
+
+		err := dao.WithTransaction(ctx, func(tx pgx.Tx) error {
+			return wk.EnqueueTx(ctx, tx, &job)
+		})
+		require.NoError(t, err)
+
+		updatedRes := waitForReservation(t, res.ID)
+		require.True(t, updatedRes.Success.Valid)
+		require.True(t, updatedRes.Success.Bool)
+		require.Equal(t, "No operation finished", updatedRes.Status)
+	})
+}
+
+func TestPostgresNoopDeadLetter(t *testing.T) {
+	_, ctx := getReservationDao(t)
+	defer reset()
+	wk := startPostgresWorker(t, ctx)
+	defer wk.Stop(ctx)
+
+	res := createNoopReservation(t)
+	job := worker.Job{
+		AccountID: 1,
+		Type:      jobs.TypeNoop,
+		Args: jobs.NoopJobArgs{
+			ReservationID: res.ID,
+			Sleep:         1300 * time.Millisecond,
+		},
+	}
+
+	err := wk.Enqueue(ctx, &job)
+	require.NoError(t, err)
+	updatedRes := waitForReservation(t, res.ID)
+	require.Equal(t, "Timeout", updatedRes.Status)
+
+	var dead []*worker.Job
+	for i := 0; i < 20 && len(dead) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		dead, err = wk.DeadLetters(ctx)
+		require.NoError(t, err)
+	}
+	require.Len(t, dead, 1)
+	require.Equal(t, job.ID, dead[0].ID)
+	require.Equal(t, 1, dead[0].Attempt)
+
+	count, err := wk.Redrive(ctx, uuid.Nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,167 @@
+//go:build integration
+// +build integration
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const typeRetryTest worker.JobType = "retry_test"
+
+type RetryTestArgs struct {
+	// Number of attempts which fail
+	Failures int
+}
+
+var errRetryTest = errors.New("retry test failure")
+
+// retryTestHandler records attempts of processed jobs
+type retryTestHandler struct {
+	mu       sync.Mutex
+	attempts []int
+	last     []bool
+}
+
+func (h *retryTestHandler) handle(ctx context.Context, job *worker.Job) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.attempts = append(h.attempts, job.Attempt)
+	h.last = append(h.last, worker.IsLastAttempt(ctx))
+	if job.Attempt < job.Args.(RetryTestArgs).Failures {
+		return errRetryTest
+	}
+	return nil
+}
+
+func (h *retryTestHandler) calls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.attempts)
+}
+
+// startRedisRetryWorker starts a separate Redis worker with its own queue and retry policy of two attempts
+func startRedisRetryWorker(t *testing.T, ctx context.Context, handler *retryTestHandler) (*worker.RedisWorker, string) {
+	t.Helper()
+
+	queueName := "provisioning-test-queue-" + uuid.NewString()
+	wk, err := worker.NewRedisWorker(config.RedisHostAndPort(),
+		config.Application.Cache.Redis.User, config.Application.Cache.Redis.Password,
+		config.Application.Cache.Redis.DB, queueName, 100*time.Millisecond, 1)
+	require.NoError(t, err)
+	wk.RegisterHandler(typeRetryTest, handler.handle, RetryTestArgs{})
+	wk.SetRetryPolicy(typeRetryTest, worker.RetryPolicy{MaxAttempts: 2, Backoff: 100 * time.Millisecond})
+	wk.DequeueLoop(ctx)
+
+	t.Cleanup(func() {
+		wk.Stop(ctx)
+		rdb := redisClient()
+		defer rdb.Close()
+		rdb.Del(ctx, queueName, queueName+":processing", queueName+":leases", queueName+":delayed", queueName+":dead")
+	})
+	return wk, queueName
+}
+
+func redisClient() *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     config.RedisHostAndPort(),
+		Username: config.Application.Cache.Redis.User,
+		Password: config.Application.Cache.Redis.Password,
+		DB:       config.Application.Cache.Redis.DB,
+	})
+}
+
+func TestRedisRetry(t *testing.T) {
+	ctx := context.Background()
+	handler := &retryTestHandler{}
+	wk, _ := startRedisRetryWorker(t, ctx, handler)
+
+	job := worker.Job{
+		Type: typeRetryTest,
+		Args: RetryTestArgs{Failures: 1},
+	}
+	err := wk.Enqueue(ctx, &job)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return handler.calls() == 2 }, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, []int{0, 1}, handler.attempts)
+	assert.Equal(t, []bool{false, true}, handler.last)
+
+	stats, err := wk.Stats(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, stats.DelayedJobs)
+	assert.Zero(t, stats.DeadJobs)
+}
+
+func TestRedisDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	handler := &retryTestHandler{}
+	wk, _ := startRedisRetryWorker(t, ctx, handler)
+
+	job := worker.Job{
+		Type: typeRetryTest,
+		Args: RetryTestArgs{Failures: 2},
+	}
+	err := wk.Enqueue(ctx, &job)
+	require.NoError(t, err)
+
+	var dead []*worker.Job
+	require.Eventually(t, func() bool {
+		dead, err = wk.DeadLetters(ctx)
+		return err == nil && len(dead) == 1
+	}, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, job.ID, dead[0].ID)
+	assert.Equal(t, 2, dead[0].Attempt)
+	assert.Equal(t, 2, handler.calls())
+
+	// redriven job starts with the first attempt again
+	count, err := wk.Redrive(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	require.Eventually(t, func() bool { return handler.calls() == 3 }, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, 0, handler.attempts[2])
+}
+
+func TestRedisExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	handler := &retryTestHandler{}
+	_, queueName := startRedisRetryWorker(t, ctx, handler)
+
+	// job left in the processing list by a crashed worker
+	job := &worker.Job{
+		ID:   uuid.New(),
+		Type: typeRetryTest,
+		Args: RetryTestArgs{},
+	}
+	var payload bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&payload).Encode(&job))
+
+	rdb := redisClient()
+	defer rdb.Close()
+	require.NoError(t, rdb.LPush(ctx, queueName+":processing", payload.String()).Err())
+	require.NoError(t, rdb.HSet(ctx, queueName+":leases", job.ID.String(), time.Now().Add(-time.Second).UnixMilli()).Err())
+
+	// the lost attempt counts as failed
+	require.Eventually(t, func() bool { return handler.calls() == 1 }, 5*time.Second, 100*time.Millisecond)
+	assert.Equal(t, []int{1}, handler.attempts)
+
+	require.Eventually(t, func() bool {
+		processing, err := rdb.LLen(ctx, queueName+":processing").Result()
+		return err == nil && processing == 0
+	}, 5*time.Second, 100*time.Millisecond)
+}
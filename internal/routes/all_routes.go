@@ -70,6 +70,9 @@ func MountAPI(r *chi.Mux) {
 				r.Get("/status", s.SourcesStatus)
 
 				r.With(middleware.Pagination).Get("/launch_templates", s.ListLaunchTemplates)
+				r.Get("/networks", s.ListNetworks)
+				r.Get("/subnets", s.ListSubnets)
+				r.Get("/security_groups", s.ListSecurityGroups)
 				r.Get("/upload_info", s.GetSourceUploadInfo)
 				r.Route("/validate_permissions", func(r chi.Router) {
 					r.Get("/", s.ValidatePermissions)
@@ -81,8 +84,10 @@ func MountAPI(r *chi.Mux) {
 			r.With(middleware.EnforcePermissions("pubkey", "write")).Post("/", s.CreatePubkey)
 			r.With(middleware.EnforcePermissions("pubkey", "read")).With(middleware.Pagination).Get("/", s.ListPubkeys)
 			r.Post("/", s.CreatePubkey)
+			r.With(middleware.EnforcePermissions("pubkey", "write")).Post("/generate", s.GeneratePubkey)
 			r.Route("/{ID}", func(r chi.Router) {
 				r.With(middleware.EnforcePermissions("pubkey", "read")).Get("/", s.GetPubkey)
+				r.With(middleware.EnforcePermissions("pubkey", "write")).Patch("/", s.UpdatePubkey)
 				r.With(middleware.EnforcePermissions("pubkey", "write")).Delete("/", s.DeletePubkey)
 			})
 		})
@@ -98,6 +103,11 @@ func MountAPI(r *chi.Mux) {
 			})
 			// Generic reservation detail request (no details provided)
 			r.With(middleware.EnforcePermissions("reservation", "read")).Get("/{ID}", s.GetReservationDetail)
+			r.With(middleware.EnforcePermissions("reservation", "write")).Delete("/{ID}", s.CancelReservation)
+			// Instances of a reservation (additional permission checks are in the service function)
+			r.With(middleware.EnforcePermissions("reservation", "read")).Get("/{ID}/instances", s.ListReservationInstances)
+			// Instance lifecycle actions (additional permission checks are in the service function)
+			r.With(middleware.EnforcePermissions("reservation", "write")).Post("/{ID}/instances/{INSTANCE_ID}/{ACTION}", s.InstanceAction)
 		})
 
 		// Endpoint used by sources background checker (no permissions needed)
@@ -17,8 +17,10 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog"
 )
 
@@ -34,6 +36,42 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expiresAt, err := parseExpiry(payload.ExpiresAt, payload.TTL)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid reservation expiry", err))
+		return
+	}
+
+	if err = userdata.ValidateCustom(models.ProviderTypeAWS, payload.UserData); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid user data", err))
+		return
+	}
+
+	if err = payload.Volumes.Validate(models.ProviderTypeAWS); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid volumes", err))
+		return
+	}
+
+	if err = models.ValidateTags(models.ProviderTypeAWS, payload.Tags); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid tags", err))
+		return
+	}
+
+	if err = payload.Spot.Validate(models.ProviderTypeAWS); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid spot configuration", err))
+		return
+	}
+
+	if err = validateAWSNetworking(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid network configuration", err))
+		return
+	}
+
+	if err = payload.Placement.Validate(models.ProviderTypeAWS); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid placement", err))
+		return
+	}
+
 	rDao := dao.GetReservationDao(r.Context())
 	pkDao := dao.GetPubkeyDao(r.Context())
 
@@ -67,12 +105,25 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if err = validateAWSPlacement(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid placement", err))
+		return
+	}
+
 	detail := &models.AWSDetail{
-		Region:           payload.Region,
-		LaunchTemplateID: payload.LaunchTemplateID,
-		InstanceType:     payload.InstanceType,
-		Amount:           payload.Amount,
-		PowerOff:         payload.PowerOff,
+		Region:            payload.Region,
+		LaunchTemplateID:  payload.LaunchTemplateID,
+		InstanceType:      payload.InstanceType,
+		Amount:            payload.Amount,
+		PowerOff:          payload.PowerOff,
+		UserData:          payload.UserData,
+		Volumes:           payload.Volumes,
+		Tags:              payload.Tags,
+		Spot:              payload.Spot,
+		SubnetID:          payload.SubnetID,
+		SecurityGroupIDs:  payload.SecurityGroupIDs,
+		AssociatePublicIP: payload.AssociatePublicIP,
+		Placement:         payload.Placement,
 	}
 	reservation := &models.AWSReservation{
 		PubkeyID: payload.PubkeyID,
@@ -85,6 +136,8 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	reservation.Provider = models.ProviderTypeAWS
 	reservation.Steps = 3
 	reservation.StepTitles = []string{"Ensure public key", "Launch instance(s)", "Fetch instance(s) description"}
+	reservation.ExpiresAt = expiresAt
+	reservation.IdempotencyKey = idempotencyKey(r)
 	newName := config.Application.InstancePrefix + payload.Name
 	reservation.Detail.Name = newName
 
@@ -98,6 +151,11 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
+	if err = pk.CheckProviderCompatibility(models.ProviderTypeAWS); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "pubkey type is not supported by the provider", err))
+		return
+	}
+
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
@@ -139,36 +197,49 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// The last step: create reservation in the database and submit new job
-	err = rDao.CreateAWS(r.Context(), reservation)
-	if err != nil {
+	// The last step: create reservation in the database and submit new job, the job is enqueued
+	// in the same transaction when the job queue supports it
+	var launchJob worker.Job
+	var enqueued bool
+	var enqueueErr error
+	enqueueLaunchJob := func(tx pgx.Tx) error {
+		launchJob = worker.Job{
+			Type:      jobs.TypeLaunchInstanceAws,
+			Identity:  id,
+			TraceID:   logging.TraceId(r.Context()),
+			EdgeID:    logging.EdgeRequestId(r.Context()),
+			AccountID: accountId,
+			Args: jobs.LaunchInstanceAWSTaskArgs{
+				ReservationID:    reservation.ID,
+				Region:           reservation.Detail.Region,
+				PubkeyID:         pk.ID,
+				SourceID:         reservation.SourceID,
+				Detail:           reservation.Detail,
+				AMI:              ami,
+				LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+				ARN:              authentication,
+			},
+		}
+
+		enqueued, enqueueErr = queue.EnqueueTx(r.Context(), tx, &launchJob)
+		return enqueueErr
+	}
+	err = rDao.CreateAWS(r.Context(), reservation, enqueueLaunchJob)
+	if enqueueErr != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", enqueueErr))
+		return
+	} else if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
 		return
 	}
 	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
 
-	launchJob := worker.Job{
-		Type:      jobs.TypeLaunchInstanceAws,
-		Identity:  id,
-		TraceID:   logging.TraceId(r.Context()),
-		EdgeID:    logging.EdgeRequestId(r.Context()),
-		AccountID: accountId,
-		Args: jobs.LaunchInstanceAWSTaskArgs{
-			ReservationID:    reservation.ID,
-			Region:           reservation.Detail.Region,
-			PubkeyID:         pk.ID,
-			SourceID:         reservation.SourceID,
-			Detail:           reservation.Detail,
-			AMI:              ami,
-			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
-			ARN:              authentication,
-		},
-	}
-
-	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
-	if err != nil {
-		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
-		return
+	if !enqueued {
+		err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
+		if err != nil {
+			renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+			return
+		}
 	}
 	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
 
@@ -178,3 +249,34 @@ func CreateAWSReservation(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render AWS reservation", err))
 	}
 }
+
+// validateAWSNetworking checks format of optional subnet and security group IDs
+func validateAWSNetworking(payload *payloads.AWSReservationRequest) error {
+	if payload.SubnetID != "" && !strings.HasPrefix(payload.SubnetID, "subnet-") {
+		return fmt.Errorf("%w: %s", ErrInvalidSubnetID, payload.SubnetID)
+	}
+	for _, sg := range payload.SecurityGroupIDs {
+		if !strings.HasPrefix(sg, "sg-") {
+			return fmt.Errorf("%w: %s", ErrInvalidSecurityGroupID, sg)
+		}
+	}
+	return nil
+}
+
+// validateAWSPlacement checks placement zones are availability zones of the region. Availability
+// is only preloaded per region, zones are checked by name.
+func validateAWSPlacement(payload *payloads.AWSReservationRequest) error {
+	if payload.Placement == nil || len(payload.Placement.Zones) == 0 {
+		return nil
+	}
+	if payload.SubnetID != "" {
+		return ErrPlacementSubnetConflict
+	}
+	for _, zone := range payload.Placement.Zones {
+		suffix, found := strings.CutPrefix(zone, payload.Region)
+		if !found || len(suffix) != 1 || suffix[0] < 'a' || suffix[0] > 'z' {
+			return fmt.Errorf("%w: %s is not a zone of region %s", ErrUnsupportedZone, zone, payload.Region)
+		}
+	}
+	return nil
+}
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	Clientstubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
@@ -82,4 +84,239 @@ func TestCreateAWSReservationHandler(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Unsupported region")
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
+	t.Run("successful reservation with ttl", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"ttl":           "8h",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		id := int64(stubs.AWSReservationStubCount(ctx))
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, id)
+		require.NoError(t, err, "failed to get reservation")
+		require.True(t, reservation.ExpiresAt.Valid, "Reservation expiry was not set")
+		assert.WithinDuration(t, time.Now().Add(8*time.Hour), reservation.ExpiresAt.Time, time.Minute)
+	})
+
+	t.Run("failed reservation with both expiry and ttl", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"expires_at":    time.Now().Add(time.Hour),
+			"ttl":           "8h",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid reservation expiry")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with expiry in the past", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"expires_at":    time.Now().Add(-time.Hour),
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid reservation expiry")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("successful reservation with subnet and security groups", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":           "1",
+			"image_id":            "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":              1,
+			"instance_type":       "t1.micro",
+			"pubkey_id":           pk.ID,
+			"subnet_id":           "subnet-06e6b4eb3f8b6a0fb",
+			"security_group_ids":  []string{"sg-0d2e7b9f3c1a8e4b5"},
+			"associate_public_ip": false,
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		id := int64(stubs.AWSReservationStubCount(ctx))
+		reservation, err := dao.GetReservationDao(ctx).GetAWSById(ctx, id)
+		require.NoError(t, err, "failed to get reservation")
+		assert.Equal(t, "subnet-06e6b4eb3f8b6a0fb", reservation.Detail.SubnetID)
+		assert.Equal(t, []string{"sg-0d2e7b9f3c1a8e4b5"}, reservation.Detail.SecurityGroupIDs)
+		require.NotNil(t, reservation.Detail.AssociatePublicIP)
+		assert.False(t, *reservation.Detail.AssociatePublicIP)
+	})
+
+	t.Run("failed reservation with invalid security group", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":          "1",
+			"image_id":           "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":             1,
+			"instance_type":      "t1.micro",
+			"pubkey_id":          pk.ID,
+			"security_group_ids": []string{"default"},
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid network configuration")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with invalid volumes", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"volumes": map[string]interface{}{
+				"root_size_gb": 200,
+				"root_type":    "pd-ssd",
+			},
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid volumes")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with reserved tag", func(t *testing.T) {
+		var err error
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+			"tags": map[string]string{
+				"rh-rid": "42",
+			},
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAWSReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid tags")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestCreateAWSReservationIncompatiblePubkey(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = Clientstubs.WithSourcesClient(ctx)
+	ctx = Clientstubs.WithImageBuilderClient(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	pk := factories.NewPubkeyECDSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to generate pubkey")
+
+	values := map[string]interface{}{
+		"source_id":     "1",
+		"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+		"amount":        1,
+		"instance_type": "t1.micro",
+		"pubkey_id":     pk.ID,
+	}
+	json_data, err := json.Marshal(values)
+	require.NoError(t, err, "unable to marshal values to json")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/aws", bytes.NewBuffer(json_data))
+	require.NoError(t, err, "failed to create request")
+	req.Header.Add("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.CreateAWSReservation)
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	assert.Contains(t, rr.Body.String(), "pubkey type is not supported by the provider")
+	assert.Equal(t, 0, stubs.AWSReservationStubCount(ctx), "Reservation must not be created")
 }
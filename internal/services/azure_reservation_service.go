@@ -16,9 +16,11 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog"
 )
 
@@ -31,6 +33,47 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expiresAt, err := parseExpiry(payload.ExpiresAt, payload.TTL)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid reservation expiry", err))
+		return
+	}
+
+	if err = userdata.ValidateCustom(models.ProviderTypeAzure, payload.UserData); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid user data", err))
+		return
+	}
+
+	if err = payload.Volumes.Validate(models.ProviderTypeAzure); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid volumes", err))
+		return
+	}
+
+	if err = models.ValidateTags(models.ProviderTypeAzure, payload.Tags); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid tags", err))
+		return
+	}
+
+	if err = payload.Spot.Validate(models.ProviderTypeAzure); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid spot configuration", err))
+		return
+	}
+
+	if err = payload.Placement.Validate(models.ProviderTypeAzure); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid placement", err))
+		return
+	}
+
+	if err = validateAzureTemplate(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid launch template", err))
+		return
+	}
+
+	if err = validateAzureNetworking(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid network configuration", err))
+		return
+	}
+
 	pkDao := dao.GetPubkeyDao(r.Context())
 	rDao := dao.GetReservationDao(r.Context())
 
@@ -53,6 +96,11 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
+	if err = pk.CheckProviderCompatibility(models.ProviderTypeAzure); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "pubkey type is not supported by the provider", err))
+		return
+	}
+
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
@@ -115,14 +163,28 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = validateAzurePlacement(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid placement", err))
+		return
+	}
+
 	name := config.Application.InstancePrefix + payload.Name
 	detail := &models.AzureDetail{
-		Location:      payload.Location,
-		ResourceGroup: payload.ResourceGroup,
-		InstanceSize:  payload.InstanceSize,
-		Amount:        payload.Amount,
-		PowerOff:      payload.PowerOff,
-		Name:          name,
+		Location:         payload.Location,
+		ResourceGroup:    payload.ResourceGroup,
+		InstanceSize:     payload.InstanceSize,
+		Amount:           payload.Amount,
+		PowerOff:         payload.PowerOff,
+		UserData:         payload.UserData,
+		Volumes:          payload.Volumes,
+		Tags:             payload.Tags,
+		Spot:             payload.Spot,
+		LaunchTemplateID: payload.LaunchTemplateID,
+		NetworkID:        payload.NetworkID,
+		SubnetID:         payload.SubnetID,
+		SecurityGroupID:  payload.SecurityGroupID,
+		Placement:        payload.Placement,
+		Name:             name,
 	}
 	reservation := &models.AzureReservation{
 		PubkeyID: payload.PubkeyID,
@@ -132,36 +194,51 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	reservation.Steps = int32(len(jobs.LaunchInstanceAzureSteps))
 	reservation.StepTitles = jobs.LaunchInstanceAzureSteps
+	reservation.ExpiresAt = expiresAt
+	reservation.IdempotencyKey = idempotencyKey(r)
 
-	// The last step: create reservation in the database and submit new job
-	err = rDao.CreateAzure(r.Context(), reservation)
-	if err != nil {
+	// The last step: create reservation in the database and submit new job, the job is enqueued
+	// in the same transaction when the job queue supports it
+	var launchJob worker.Job
+	var enqueued bool
+	var enqueueErr error
+	enqueueLaunchJob := func(tx pgx.Tx) error {
+		launchJob = worker.Job{
+			Type:      jobs.TypeLaunchInstanceAzure,
+			Identity:  identity.Identity(r.Context()),
+			TraceID:   logging.TraceId(r.Context()),
+			EdgeID:    logging.EdgeRequestId(r.Context()),
+			AccountID: identity.AccountId(r.Context()),
+			Args: jobs.LaunchInstanceAzureTaskArgs{
+				ReservationID:     reservation.ID,
+				ResourceGroupName: reservation.Detail.ResourceGroup,
+				Location:          reservation.Detail.Location,
+				PubkeyID:          pk.ID,
+				SourceID:          reservation.SourceID,
+				AzureImageID:      azureImageName,
+				Subscription:      authentication,
+			},
+		}
+
+		enqueued, enqueueErr = queue.EnqueueTx(r.Context(), tx, &launchJob)
+		return enqueueErr
+	}
+	err = rDao.CreateAzure(r.Context(), reservation, enqueueLaunchJob)
+	if enqueueErr != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", enqueueErr))
+		return
+	} else if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "create Azure reservation", err))
 		return
 	}
 	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
 
-	launchJob := worker.Job{
-		Type:      jobs.TypeLaunchInstanceAzure,
-		Identity:  identity.Identity(r.Context()),
-		TraceID:   logging.TraceId(r.Context()),
-		EdgeID:    logging.EdgeRequestId(r.Context()),
-		AccountID: identity.AccountId(r.Context()),
-		Args: jobs.LaunchInstanceAzureTaskArgs{
-			ReservationID:     reservation.ID,
-			ResourceGroupName: reservation.Detail.ResourceGroup,
-			Location:          reservation.Detail.Location,
-			PubkeyID:          pk.ID,
-			SourceID:          reservation.SourceID,
-			AzureImageID:      azureImageName,
-			Subscription:      authentication,
-		},
-	}
-
-	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
-	if err != nil {
-		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
-		return
+	if !enqueued {
+		err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
+		if err != nil {
+			renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+			return
+		}
 	}
 	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
 
@@ -171,3 +248,60 @@ func CreateAzureReservation(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render Azure reservation", err))
 	}
 }
+
+// validateAzureTemplate checks the launch template is a Template Spec resource ID and that options
+// defined by the template are not set.
+func validateAzureTemplate(payload *payloads.AzureReservationRequest) error {
+	if payload.LaunchTemplateID == "" {
+		return nil
+	}
+	if !azureResourceOfType(payload.LaunchTemplateID, "microsoft.resources/templatespecs") {
+		return fmt.Errorf("%w: %s", ErrInvalidTemplateSpecID, payload.LaunchTemplateID)
+	}
+	if payload.Volumes != nil || payload.Spot != nil || payload.Placement != nil {
+		return ErrTemplateSpecConflict
+	}
+	return nil
+}
+
+// validateAzureNetworking checks types of network resource IDs and that the subnet belongs to the network.
+// Networking of template deployments is defined by the template.
+func validateAzureNetworking(payload *payloads.AzureReservationRequest) error {
+	if payload.LaunchTemplateID != "" && (payload.NetworkID != "" || payload.SubnetID != "" || payload.SecurityGroupID != "") {
+		return ErrTemplateSpecConflict
+	}
+	if payload.NetworkID != "" && !azureResourceOfType(payload.NetworkID, "microsoft.network/virtualnetworks") {
+		return fmt.Errorf("%w: %s", ErrInvalidNetworkID, payload.NetworkID)
+	}
+	if payload.SubnetID != "" {
+		if !azureResourceOfType(payload.SubnetID, "microsoft.network/virtualnetworks") || !strings.Contains(strings.ToLower(payload.SubnetID), "/subnets/") {
+			return fmt.Errorf("%w: %s", ErrInvalidSubnetResourceID, payload.SubnetID)
+		}
+		if payload.NetworkID != "" && !strings.HasPrefix(strings.ToLower(payload.SubnetID), strings.ToLower(payload.NetworkID)+"/subnets/") {
+			return fmt.Errorf("%w: subnet does not belong to the network", ErrInvalidSubnetResourceID)
+		}
+	}
+	if payload.SecurityGroupID != "" && !azureResourceOfType(payload.SecurityGroupID, "microsoft.network/networksecuritygroups") {
+		return fmt.Errorf("%w: %s", ErrInvalidSGResourceID, payload.SecurityGroupID)
+	}
+	return nil
+}
+
+// validateAzurePlacement checks the instance size is available in placement zones of the location.
+func validateAzurePlacement(payload *payloads.AzureReservationRequest) error {
+	if payload.Placement == nil {
+		return nil
+	}
+	region, _, _ := strings.Cut(payload.Location, "_")
+	for _, zone := range payload.Placement.Zones {
+		if !preload.AzureInstanceType.IsTypeAvailable(region, zone, clients.InstanceTypeName(payload.InstanceSize)) {
+			return fmt.Errorf("%w: %s in location %s", ErrUnsupportedZone, zone, region)
+		}
+	}
+	return nil
+}
+
+// azureResourceOfType returns true when the full resource ID contains the lowercase provider type.
+func azureResourceOfType(id, resourceType string) bool {
+	return strings.HasPrefix(id, "/subscriptions/") && strings.Contains(strings.ToLower(id), "/providers/"+resourceType+"/")
+}
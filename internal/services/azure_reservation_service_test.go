@@ -137,4 +137,65 @@ func TestCreateAzureReservationHandler(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Unsupported location")
 		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
 	})
+
+	t.Run("failed reservation with invalid launch template", func(t *testing.T) {
+		ctx := stubs.WithReservationDao(sharedCtx)
+		ctx = stub.WithEnqueuer(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":          source.ID,
+			"image_id":           "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+			"resource_group":     "testGroup",
+			"amount":             1,
+			"instance_size":      "Basic_A0",
+			"pubkey_id":          pk.ID,
+			"launch_template_id": "lt-8732678436272377",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/azure", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAzureReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid launch template")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+
+	t.Run("failed reservation with subnet of other network", func(t *testing.T) {
+		ctx := stubs.WithReservationDao(sharedCtx)
+		ctx = stub.WithEnqueuer(ctx)
+
+		var err error
+		values := map[string]interface{}{
+			"source_id":      source.ID,
+			"image_id":       "92ea98f8-7697-472e-80b1-7454fa0e7fa7",
+			"resource_group": "testGroup",
+			"amount":         1,
+			"instance_size":  "Basic_A0",
+			"pubkey_id":      pk.ID,
+			"network_id":     "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/hub-vnet",
+			"subnet_id":      "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/hub/providers/Microsoft.Network/virtualNetworks/spoke-vnet/subnets/workloads",
+		}
+		if json_data, err = json.Marshal(values); err != nil {
+			t.Fatalf("unable to marshal values to json: %v", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/reservations/azure", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateAzureReservation)
+		handler.ServeHTTP(rr, req)
+
+		assert.Contains(t, rr.Body.String(), "Invalid network configuration")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
 }
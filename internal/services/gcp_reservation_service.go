@@ -9,6 +9,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
 
 	"github.com/RHEnVision/provisioning-backend/internal/preload"
+	"github.com/RHEnVision/provisioning-backend/internal/userdata"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/queue"
 	"github.com/RHEnVision/provisioning-backend/pkg/worker"
 	"github.com/go-chi/render"
+	"github.com/jackc/pgx/v5"
 )
 
 func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
@@ -35,6 +37,32 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expiresAt, err := parseExpiry(payload.ExpiresAt, payload.TTL)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid reservation expiry", err))
+		return
+	}
+
+	if err = userdata.ValidateCustom(models.ProviderTypeGCP, payload.UserData); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid user data", err))
+		return
+	}
+
+	if err = payload.Volumes.Validate(models.ProviderTypeGCP); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid volumes", err))
+		return
+	}
+
+	if err = models.ValidateTags(models.ProviderTypeGCP, payload.Labels); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid tags", err))
+		return
+	}
+
+	if err = payload.Spot.Validate(models.ProviderTypeGCP); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid spot configuration", err))
+		return
+	}
+
 	rDao := dao.GetReservationDao(r.Context())
 	pkDao := dao.GetPubkeyDao(r.Context())
 
@@ -44,6 +72,21 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err = validateGCPNetworking(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid network configuration", err))
+		return
+	}
+
+	if err = payload.Placement.Validate(models.ProviderTypeGCP); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid placement", err))
+		return
+	}
+
+	if err = validateGCPPlacement(payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "Invalid placement", err))
+		return
+	}
+
 	namePattern := "inst-####"
 	// Verify name pattern is lower cased and add #####
 	if payload.NamePattern != "" {
@@ -63,6 +106,14 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		MachineType:      payload.MachineType,
 		Amount:           payload.Amount,
 		PowerOff:         payload.PowerOff,
+		UserData:         payload.UserData,
+		Volumes:          payload.Volumes,
+		Labels:           payload.Labels,
+		Spot:             payload.Spot,
+		NetworkID:        payload.NetworkID,
+		SubnetID:         payload.SubnetID,
+		ExternalIP:       payload.ExternalIP,
+		Placement:        payload.Placement,
 		UUID:             resUUID,
 		LaunchTemplateID: payload.LaunchTemplateID,
 	}
@@ -78,6 +129,8 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	reservation.Provider = models.ProviderTypeGCP
 	reservation.Steps = 2
 	reservation.StepTitles = jobs.LaunchInstanceGCPSteps
+	reservation.ExpiresAt = expiresAt
+	reservation.IdempotencyKey = idempotencyKey(r)
 
 	logger.Debug().Msgf("Validating existence of pubkey %d for this account", reservation.PubkeyID)
 	pk, err := pkDao.GetById(r.Context(), reservation.PubkeyID)
@@ -88,6 +141,11 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Debug().Msgf("Found pubkey %d named '%s'", pk.ID, pk.Name)
 
+	if err = pk.CheckProviderCompatibility(models.ProviderTypeGCP); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "pubkey type is not supported by the provider", err))
+		return
+	}
+
 	// Get Sources client
 	sourcesClient, err := clients.GetSourcesClient(r.Context())
 	if err != nil {
@@ -131,35 +189,49 @@ func CreateGCPReservation(w http.ResponseWriter, r *http.Request) {
 		name = payload.ImageID
 	}
 
-	// The last step: create reservation in the database and submit new job
-	err = rDao.CreateGCP(r.Context(), reservation)
-	if err != nil {
+	// The last step: create reservation in the database and submit new job, the job is enqueued
+	// in the same transaction when the job queue supports it
+	var launchJob worker.Job
+	var enqueued bool
+	var enqueueErr error
+	enqueueLaunchJob := func(tx pgx.Tx) error {
+		launchJob = worker.Job{
+			Type:      jobs.TypeLaunchInstanceGcp,
+			AccountID: accountId,
+			TraceID:   logging.TraceId(r.Context()),
+			EdgeID:    logging.EdgeRequestId(r.Context()),
+			Identity:  id,
+			Args: jobs.LaunchInstanceGCPTaskArgs{
+				ReservationID:    reservation.ID,
+				Zone:             reservation.Detail.Zone,
+				PubkeyID:         reservation.PubkeyID,
+				SourceID:         payload.SourceID,
+				Detail:           reservation.Detail,
+				ImageName:        name,
+				ProjectID:        authentication,
+				LaunchTemplateID: reservation.Detail.LaunchTemplateID,
+			},
+		}
+
+		enqueued, enqueueErr = queue.EnqueueTx(r.Context(), tx, &launchJob)
+		return enqueueErr
+	}
+	err = rDao.CreateGCP(r.Context(), reservation, enqueueLaunchJob)
+	if enqueueErr != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", enqueueErr))
+		return
+	} else if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "create reservation", err))
 		return
 	}
 	logger.Debug().Msgf("Created a new reservation %d", reservation.ID)
 
-	launchJob := worker.Job{
-		Type:      jobs.TypeLaunchInstanceGcp,
-		AccountID: accountId,
-		TraceID:   logging.TraceId(r.Context()),
-		EdgeID:    logging.EdgeRequestId(r.Context()),
-		Identity:  id,
-		Args: jobs.LaunchInstanceGCPTaskArgs{
-			ReservationID:    reservation.ID,
-			Zone:             reservation.Detail.Zone,
-			PubkeyID:         reservation.PubkeyID,
-			Detail:           reservation.Detail,
-			ImageName:        name,
-			ProjectID:        authentication,
-			LaunchTemplateID: reservation.Detail.LaunchTemplateID,
-		},
-	}
-
-	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
-	if err != nil {
-		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
-		return
+	if !enqueued {
+		err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &launchJob)
+		if err != nil {
+			renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+			return
+		}
 	}
 	logger.Debug().Msgf("Enqueued reservation job %s", launchJob.ID)
 
@@ -179,3 +251,44 @@ func isValidNamePattern(namePattern string) bool {
 	pattern := regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
 	return pattern.MatchString(namePattern)
 }
+
+var (
+	gcpNetworkIDRegexp    = regexp.MustCompile(`^global/networks/[a-z]([-a-z0-9]*[a-z0-9])?$`)
+	gcpSubnetworkIDRegexp = regexp.MustCompile(`^regions/([a-z0-9-]+)/subnetworks/[a-z]([-a-z0-9]*[a-z0-9])?$`)
+)
+
+// validateGCPNetworking checks format of optional network and subnetwork IDs and that the subnetwork
+// is in the region of the zone
+func validateGCPNetworking(payload *payloads.GCPReservationRequest) error {
+	if payload.NetworkID != "" && !gcpNetworkIDRegexp.MatchString(payload.NetworkID) {
+		return fmt.Errorf("%w: %s must be in the form of global/networks/NAME", ErrInvalidNetworkID, payload.NetworkID)
+	}
+	if payload.SubnetID != "" {
+		match := gcpSubnetworkIDRegexp.FindStringSubmatch(payload.SubnetID)
+		if match == nil {
+			return fmt.Errorf("%w: %s must be in the form of regions/REGION/subnetworks/NAME", ErrInvalidSubnetResourceID, payload.SubnetID)
+		}
+		if !strings.HasPrefix(payload.Zone, match[1]+"-") {
+			return fmt.Errorf("%w: subnetwork region %s does not match zone %s", ErrInvalidSubnetResourceID, match[1], payload.Zone)
+		}
+	}
+	return nil
+}
+
+// validateGCPPlacement checks placement zones are preloaded zones in the region of the zone and
+// that the machine type is available in them
+func validateGCPPlacement(payload *payloads.GCPReservationRequest) error {
+	if payload.Placement == nil {
+		return nil
+	}
+	region := payload.Zone[:strings.LastIndex(payload.Zone, "-")]
+	for _, zone := range payload.Placement.Zones {
+		if !strings.HasPrefix(zone, region+"-") || !preload.GCPInstanceType.ValidateRegion(zone) {
+			return fmt.Errorf("%w: %s is not a zone of region %s", ErrUnsupportedZone, zone, region)
+		}
+		if payload.MachineType != "" && !preload.GCPInstanceType.IsTypeAvailable(zone, "", clients.InstanceTypeName(payload.MachineType)) {
+			return fmt.Errorf("%w: %s is not available in %s", ErrUnsupportedZone, payload.MachineType, zone)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/queue"
+	"github.com/RHEnVision/provisioning-backend/pkg/worker"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrUnknownInstanceAction = errors.New("unknown instance action, expected values: start, stop, reboot, terminate")
+	ErrInstanceNotFound      = errors.New("instance not found in reservation")
+)
+
+// InstanceAction performs a lifecycle action (start, stop, reboot or terminate) on an instance launched
+// by a reservation. The action is executed in the background, a new reservation tracking its progress
+// is returned.
+func InstanceAction(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+	accountId := identity.AccountId(r.Context())
+	principal := identity.Identity(r.Context())
+
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+	// Azure instance IDs are full resource IDs with slashes which must be URL encoded
+	instanceId, err := url.PathUnescape(chi.URLParam(r, "INSTANCE_ID"))
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse INSTANCE_ID parameter", err))
+		return
+	}
+
+	action := models.InstanceActionFromString(chi.URLParam(r, "ACTION"))
+	if action == models.InstanceActionUnknown {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "unknown instance action", ErrUnknownInstanceAction))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation")
+		return
+	}
+
+	if CheckPermissionAndRender(w, r, "write", "reservation", reservation.Provider.String()) != nil {
+		return
+	}
+
+	instances, err := rDao.ListInstances(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "list reservation instances")
+		return
+	}
+	var found *models.ReservationInstance
+	for _, instance := range instances {
+		if instance.InstanceID == instanceId {
+			found = instance
+			break
+		}
+	}
+	if found == nil {
+		renderError(w, r, payloads.NewNotFoundError(r.Context(), fmt.Sprintf("instance %s", instanceId), ErrInstanceNotFound))
+		return
+	}
+
+	sourceID, location, err := dao.ReservationSourceAndLocation(r.Context(), reservation)
+	if errors.Is(err, dao.ErrUnsupportedProvider) {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", err))
+		return
+	} else if err != nil {
+		renderNotFoundOrDAOError(w, r, err, fmt.Sprintf("get reservation detail with id %d", id))
+		return
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(r.Context(), sourceID)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	if typeErr := authentication.MustBe(reservation.Provider); typeErr != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), typeErr))
+		return
+	}
+
+	actionReservation := &models.InstanceActionReservation{
+		Reservation: models.Reservation{
+			Provider:            reservation.Provider,
+			AccountID:           accountId,
+			Status:              "Created",
+			Steps:               1,
+			StepTitles:          jobs.InstanceActionSteps(action),
+			LaunchReservationID: sql.NullInt64{Int64: reservation.ID, Valid: true},
+		},
+	}
+	err = rDao.CreateInstanceAction(r.Context(), actionReservation)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "create instance action reservation", err))
+		return
+	}
+	logger.Debug().Msgf("Created a new reservation %d for action '%s' on instance %s", actionReservation.ID, action, instanceId)
+
+	actionJob := worker.Job{
+		Identity:  principal,
+		TraceID:   logging.TraceId(r.Context()),
+		EdgeID:    logging.EdgeRequestId(r.Context()),
+		AccountID: accountId,
+	}
+	//nolint:exhaustive
+	switch reservation.Provider {
+	case models.ProviderTypeAWS:
+		actionJob.Type = jobs.TypeInstanceActionAws
+		actionJob.Args = jobs.InstanceActionAWSTaskArgs{
+			ReservationID:       actionReservation.ID,
+			LaunchReservationID: reservation.ID,
+			Action:              action,
+			Region:              location,
+			InstanceIDs:         []string{instanceId},
+			ARN:                 authentication,
+		}
+	case models.ProviderTypeAzure:
+		actionJob.Type = jobs.TypeInstanceActionAzure
+		actionJob.Args = jobs.InstanceActionAzureTaskArgs{
+			ReservationID:       actionReservation.ID,
+			LaunchReservationID: reservation.ID,
+			Action:              action,
+			InstanceIDs:         []string{instanceId},
+			Subscription:        authentication,
+		}
+	case models.ProviderTypeGCP:
+		actionJob.Type = jobs.TypeInstanceActionGcp
+		actionJob.Args = jobs.InstanceActionGCPTaskArgs{
+			ReservationID:       actionReservation.ID,
+			LaunchReservationID: reservation.ID,
+			Action:              action,
+			Zone:                found.ZoneOrDefault(location),
+			InstanceIDs:         []string{instanceId},
+			ProjectID:           authentication,
+		}
+	}
+
+	err = queue.GetEnqueuer(r.Context()).Enqueue(r.Context(), &actionJob)
+	if err != nil {
+		renderError(w, r, payloads.NewEnqueueTaskError(r.Context(), "job enqueue error", err))
+		return
+	}
+	logger.Debug().Msgf("Enqueued instance action job %s", actionJob.ID)
+
+	if err := render.Render(w, r, payloads.NewReservationResponse(&actionReservation.Reservation)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
+	}
+}
@@ -0,0 +1,148 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/identity"
+	"github.com/RHEnVision/provisioning-backend/internal/jobs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	queueStub "github.com/RHEnVision/provisioning-backend/internal/queue/stub"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
+	tidentity "github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareInstanceActionContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAWS)
+	require.NoError(t, err, "failed to add stubbed source")
+
+	reservation := &models.AWSReservation{
+		PubkeyID: pk.ID,
+		SourceID: source.ID,
+		ImageID:  "ami-random",
+		Detail: &models.AWSDetail{
+			Region:       "us-east-1",
+			InstanceType: "t1.micro",
+			Amount:       1,
+		},
+	}
+	reservation.AccountID = identity.AccountId(ctx)
+	reservation.Status = "Finished"
+	reservation.Provider = models.ProviderTypeAWS
+	reservation.Steps = 3
+	err = stubs.AddAWSReservation(ctx, reservation)
+	require.NoError(t, err, "failed to create stub reservation")
+
+	err = dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{
+		ReservationID: reservation.ID,
+		InstanceID:    "i-0a4caa2cf5b097ce1",
+	})
+	require.NoError(t, err, "failed to create stub instance")
+
+	return ctx
+}
+
+func instanceActionRequest(t *testing.T, ctx context.Context, instanceID, action string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rctx := chi.NewRouteContext()
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	rctx.URLParams.Add("ID", "1")
+	rctx.URLParams.Add("INSTANCE_ID", instanceID)
+	rctx.URLParams.Add("ACTION", action)
+	req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/v1/reservations/1/instances/"+instanceID+"/"+action, nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.InstanceAction)
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestInstanceActionHandler(t *testing.T) {
+	t.Run("stop instance", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+
+		rr := instanceActionRequest(t, ctx, "i-0a4caa2cf5b097ce1", "stop")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+
+		assert.Equal(t, int(models.ProviderTypeAWS), response.Provider)
+		assert.Equal(t, []string{"Stop instance(s)"}, response.StepTitles)
+		assert.Equal(t, 1, stubs.InstanceActionReservationStubCount(ctx), "Reservation has not been created through DAO")
+
+		// instance action reservations have no provider details
+		reservations, err := dao.GetReservationDao(ctx).List(ctx, &dao.ReservationFilter{Provider: models.ProviderTypeAWS}, 10, 0)
+		require.NoError(t, err, "failed to list reservations")
+		require.Len(t, reservations, 1)
+		assert.Equal(t, int64(1), reservations[0].ID)
+	})
+
+	t.Run("encoded instance ID", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+		ctx = queueStub.WithEnqueuer(ctx)
+
+		vmID := "/subscriptions/4b9d213f-712f-4d17-a483-8a10bbe9df3a/resourceGroups/redhat-deployed/providers/Microsoft.Compute/virtualMachines/redhat-vm-1"
+		err := dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{
+			ReservationID: 1,
+			InstanceID:    vmID,
+		})
+		require.NoError(t, err, "failed to create stub instance")
+
+		rr := instanceActionRequest(t, ctx, url.PathEscape(vmID), "terminate")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		enqueued := queueStub.EnqueuedJobs(ctx)
+		require.Len(t, enqueued, 1)
+		args, ok := enqueued[0].Args.(jobs.InstanceActionAWSTaskArgs)
+		require.True(t, ok, "unexpected job arguments")
+		assert.Equal(t, []string{vmID}, args.InstanceIDs)
+		assert.Equal(t, int64(1), args.LaunchReservationID)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+
+		rr := instanceActionRequest(t, ctx, "i-0a4caa2cf5b097ce1", "hibernate")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+		assert.Equal(t, 0, stubs.InstanceActionReservationStubCount(ctx))
+	})
+
+	t.Run("unknown instance", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+
+		rr := instanceActionRequest(t, ctx, "i-missing", "terminate")
+		require.Equal(t, http.StatusNotFound, rr.Code, "Wrong status code")
+		assert.Equal(t, 0, stubs.InstanceActionReservationStubCount(ctx))
+	})
+}
@@ -31,7 +31,7 @@ func ListLaunchTemplates(w http.ResponseWriter, r *http.Request) {
 	case models.ProviderTypeAWS:
 		ListLaunchTemplateAWS(w, r)
 	case models.ProviderTypeAzure:
-		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "azure reservation is not implemented", ErrProviderTypeNotImplemented))
+		ListLaunchTemplateAzure(w, r)
 	case models.ProviderTypeGCP:
 		ListLaunchTemplateGCP(w, r)
 	default:
@@ -111,3 +111,37 @@ func ListLaunchTemplateGCP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func ListLaunchTemplateAzure(w http.ResponseWriter, r *http.Request) {
+	sourceId := chi.URLParam(r, "ID")
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(r.Context(), sourceId)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	azureClient, err := clients.GetAzureClient(r.Context(), authentication)
+	if err != nil {
+		renderError(w, r, payloads.NewAzureError(r.Context(), "unable to get Azure client", err))
+		return
+	}
+
+	templates, nextToken, err := azureClient.ListLaunchTemplates(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewAzureError(r.Context(), "unable to list Azure template specs", err))
+		return
+	}
+
+	meta := page.NewTokenMetadata(r.Context(), r, nextToken)
+
+	if err := render.Render(w, r, payloads.NewListLaunchTemplateResponse(templates, meta)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render launch templates list", err))
+		return
+	}
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// ListNetworks returns virtual networks (VPCs) available for a source in a region, or in the whole
+// subscription (Azure) or project (GCP).
+func ListNetworks(w http.ResponseWriter, r *http.Request) {
+	authentication := sourceAuthentication(w, r)
+	if authentication == nil {
+		return
+	}
+
+	//nolint:exhaustive
+	switch authentication.ProviderType {
+	case models.ProviderTypeAWS:
+		ec2Client := sourceEC2Client(w, r, authentication)
+		if ec2Client == nil {
+			return
+		}
+
+		networks, err := ec2Client.ListVPCs(r.Context())
+		if err != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to list AWS VPCs", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewNetworkListResponse(networks)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render networks list", err))
+		}
+	case models.ProviderTypeAzure:
+		azureClient := sourceAzureClient(w, r, authentication)
+		if azureClient == nil {
+			return
+		}
+
+		networks, err := azureClient.ListVirtualNetworks(r.Context())
+		if err != nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to list Azure virtual networks", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewNetworkListResponse(networks)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render networks list", err))
+		}
+	case models.ProviderTypeGCP:
+		gcpClient := sourceGCPClient(w, r, authentication)
+		if gcpClient == nil {
+			return
+		}
+
+		networks, err := gcpClient.ListNetworks(r.Context())
+		if err != nil {
+			renderError(w, r, payloads.NewGCPError(r.Context(), "unable to list GCP networks", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewNetworkListResponse(networks)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render networks list", err))
+		}
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
+	}
+}
+
+// ListSubnets returns subnets available for a source in a region, optionally filtered by network. Region
+// is optional for GCP, subnetworks of all regions are returned when not set.
+func ListSubnets(w http.ResponseWriter, r *http.Request) {
+	authentication := sourceAuthentication(w, r)
+	if authentication == nil {
+		return
+	}
+	networkID := r.URL.Query().Get("network_id")
+
+	//nolint:exhaustive
+	switch authentication.ProviderType {
+	case models.ProviderTypeAWS:
+		ec2Client := sourceEC2Client(w, r, authentication)
+		if ec2Client == nil {
+			return
+		}
+
+		subnets, err := ec2Client.ListSubnets(r.Context(), networkID)
+		if err != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to list AWS subnets", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewSubnetListResponse(subnets)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render subnets list", err))
+		}
+	case models.ProviderTypeAzure:
+		azureClient := sourceAzureClient(w, r, authentication)
+		if azureClient == nil {
+			return
+		}
+
+		subnets, err := azureClient.ListSubnets(r.Context(), networkID)
+		if err != nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to list Azure subnets", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewSubnetListResponse(subnets)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render subnets list", err))
+		}
+	case models.ProviderTypeGCP:
+		gcpClient := sourceGCPClient(w, r, authentication)
+		if gcpClient == nil {
+			return
+		}
+
+		subnets, err := gcpClient.ListSubnetworks(r.Context(), r.URL.Query().Get("region"), networkID)
+		if err != nil {
+			renderError(w, r, payloads.NewGCPError(r.Context(), "unable to list GCP subnetworks", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewSubnetListResponse(subnets)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render subnets list", err))
+		}
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
+	}
+}
+
+// ListSecurityGroups returns security groups available for a source in a region, optionally filtered by network.
+// Azure network security groups are not bound to networks, the filter does not apply.
+func ListSecurityGroups(w http.ResponseWriter, r *http.Request) {
+	authentication := sourceAuthentication(w, r)
+	if authentication == nil {
+		return
+	}
+	networkID := r.URL.Query().Get("network_id")
+
+	//nolint:exhaustive
+	switch authentication.ProviderType {
+	case models.ProviderTypeAWS:
+		ec2Client := sourceEC2Client(w, r, authentication)
+		if ec2Client == nil {
+			return
+		}
+
+		groups, err := ec2Client.ListSecurityGroups(r.Context(), networkID)
+		if err != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to list AWS security groups", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewSecurityGroupListResponse(groups)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render security groups list", err))
+		}
+	case models.ProviderTypeAzure:
+		azureClient := sourceAzureClient(w, r, authentication)
+		if azureClient == nil {
+			return
+		}
+
+		groups, err := azureClient.ListSecurityGroups(r.Context())
+		if err != nil {
+			renderError(w, r, payloads.NewAzureError(r.Context(), "unable to list Azure network security groups", err))
+			return
+		}
+
+		if err := render.Render(w, r, payloads.NewSecurityGroupListResponse(groups)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render security groups list", err))
+		}
+	default:
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
+	}
+}
+
+// sourceAuthentication fetches authentication of the source from the URL or renders an error and returns nil
+func sourceAuthentication(w http.ResponseWriter, r *http.Request) *clients.Authentication {
+	sourceId := chi.URLParam(r, "ID")
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return nil
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(r.Context(), sourceId)
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return nil
+	}
+
+	return authentication
+}
+
+// sourceEC2Client returns EC2 client for the region from the query or renders an error and returns nil
+func sourceEC2Client(w http.ResponseWriter, r *http.Request, authentication *clients.Authentication) clients.EC2 {
+	region := r.URL.Query().Get("region")
+	if region == "" {
+		renderError(w, r, payloads.NewMissingRequestParameterError(r.Context(), "region parameter is missing"))
+		return nil
+	}
+
+	ec2Client, err := clients.GetEC2Client(r.Context(), authentication, region)
+	if err != nil {
+		renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS EC2 client", err))
+		return nil
+	}
+
+	return ec2Client
+}
+
+// sourceAzureClient returns Azure client or renders an error and returns nil
+func sourceAzureClient(w http.ResponseWriter, r *http.Request, authentication *clients.Authentication) clients.Azure {
+	azureClient, err := clients.GetAzureClient(r.Context(), authentication)
+	if err != nil {
+		renderError(w, r, payloads.NewAzureError(r.Context(), "unable to get Azure client", err))
+		return nil
+	}
+
+	return azureClient
+}
+
+// sourceGCPClient returns GCP client or renders an error and returns nil
+func sourceGCPClient(w http.ResponseWriter, r *http.Request, authentication *clients.Authentication) clients.GCP {
+	gcpClient, err := clients.GetGCPClient(r.Context(), authentication)
+	if err != nil {
+		renderError(w, r, payloads.NewGCPError(r.Context(), "unable to get GCP client", err))
+		return nil
+	}
+
+	return gcpClient
+}
@@ -0,0 +1,205 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func prepareNetworksContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("ID", "1")
+	return context.WithValue(ctx, chi.RouteCtxKey, rctx)
+}
+
+func prepareAzureNetworksContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithAzureClient(ctx)
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeAzure)
+	require.NoError(t, err, "failed to generate Azure source")
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("ID", source.ID)
+	return context.WithValue(ctx, chi.RouteCtxKey, rctx)
+}
+
+func prepareGCPNetworksContext(t *testing.T) context.Context {
+	t.Helper()
+
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithGCPCCustomerClient(ctx)
+	source, err := clientStubs.AddSource(ctx, models.ProviderTypeGCP)
+	require.NoError(t, err, "failed to generate GCP source")
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("ID", source.ID)
+	return context.WithValue(ctx, chi.RouteCtxKey, rctx)
+}
+
+func TestListNetworksHandler(t *testing.T) {
+	t.Run("AWS VPCs", func(t *testing.T) {
+		ctx := prepareNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/networks?region=us-east-1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListNetworks)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.NetworkListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Len(t, result.Data, 2)
+		assert.Equal(t, "vpc-0a4caa2cf5b097ce1", result.Data[0].ID)
+	})
+
+	t.Run("Azure virtual networks", func(t *testing.T) {
+		ctx := prepareAzureNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/2/networks", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListNetworks)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.NetworkListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "hub-vnet", result.Data[0].Name)
+	})
+
+	t.Run("missing region", func(t *testing.T) {
+		ctx := prepareNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/networks", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListNetworks)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
+
+func TestListSubnetsHandler(t *testing.T) {
+	t.Run("all subnets", func(t *testing.T) {
+		ctx := prepareNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/subnets?region=us-east-1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListSubnets)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.SubnetListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Len(t, result.Data, 2)
+	})
+
+	t.Run("filtered by network", func(t *testing.T) {
+		ctx := prepareNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/subnets?region=us-east-1&network_id=vpc-0a4caa2cf5b097ce1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListSubnets)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.SubnetListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "subnet-06e6b4eb3f8b6a0fb", result.Data[0].ID)
+		assert.Equal(t, "us-east-1a", result.Data[0].Zone)
+	})
+
+	t.Run("GCP subnetworks in a region", func(t *testing.T) {
+		ctx := prepareGCPNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/2/subnets?region=us-east4", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListSubnets)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.SubnetListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "regions/us-east4/subnetworks/workloads", result.Data[0].ID)
+		assert.Equal(t, "global/networks/shared-vpc", result.Data[0].NetworkID)
+	})
+
+	t.Run("Azure subnets", func(t *testing.T) {
+		ctx := prepareAzureNetworksContext(t)
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/2/subnets", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListSubnets)
+		handler.ServeHTTP(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+		var result payloads.SubnetListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "workloads", result.Data[0].Name)
+	})
+}
+
+func TestListSecurityGroupsHandler(t *testing.T) {
+	ctx := prepareNetworksContext(t)
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/sources/1/security_groups?region=us-east-1&network_id=vpc-0b7e3d1f9c2a6b8d4", nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.ListSecurityGroups)
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+	var result payloads.SecurityGroupListResponse
+	err = json.NewDecoder(rr.Body).Decode(&result)
+	require.NoError(t, err, "failed to decode response body")
+	require.Len(t, result.Data, 1)
+	assert.Equal(t, "sg-0e3f8c0a4d2b9f5c6", result.Data[0].ID)
+}
@@ -12,11 +12,15 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/page"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/ssh"
 	"github.com/go-chi/render"
 	"github.com/rs/zerolog"
 )
 
-var ErrMissingNameOrBody = errors.New("name or body missing")
+var (
+	ErrMissingNameOrBody = errors.New("name or body missing")
+	ErrMissingName       = errors.New("name missing")
+)
 
 func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 	payload := &payloads.PubkeyRequest{}
@@ -47,13 +51,67 @@ func CreatePubkey(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GeneratePubkey generates a new SSH key pair, stores the public part and returns the private
+// part which is not stored anywhere.
+func GeneratePubkey(w http.ResponseWriter, r *http.Request) {
+	payload := &payloads.PubkeyGenerateRequest{}
+	if err := render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "generate pubkey", err))
+		return
+	}
+
+	if payload.Name == "" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), ErrMissingName.Error(), ErrMissingName))
+		return
+	}
+
+	keyPair, err := ssh.GenerateKeyPair(ssh.KeyType(payload.Type), "")
+	if err != nil {
+		if errors.Is(err, ssh.ErrUnsupportedKeyType) {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "generate pubkey", err))
+		} else {
+			renderError(w, r, payloads.NewResponseError(r.Context(), http.StatusInternalServerError, "unable to generate key pair", err))
+		}
+		return
+	}
+
+	pkDao := dao.GetPubkeyDao(r.Context())
+
+	pk := &models.Pubkey{
+		Name: payload.Name,
+		Body: keyPair.PublicKey,
+	}
+	err = pkDao.Create(r.Context(), pk)
+	if err != nil {
+		if db.IsPostgresError(err, db.UniqueConstraintErrorCode) != nil {
+			renderError(w, r, payloads.PubkeyDuplicateError(r.Context(), "pubkey with such name already exists for this account", err))
+		} else {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "create pubkey", err))
+		}
+		return
+	}
+
+	// the private key must not be stored by caches or proxies
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	render.Status(r, http.StatusCreated)
+	if err := render.Render(w, r, payloads.NewPubkeyGenerateResponse(pk, keyPair.PrivateKey)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+	}
+}
+
 func ListPubkeys(w http.ResponseWriter, r *http.Request) {
 	pubkeyDao := dao.GetPubkeyDao(r.Context())
 
 	offset := page.Offset(r.Context()).Int64()
 	limit := page.Limit(r.Context()).Int64()
 
-	pubkeys, err := pubkeyDao.List(r.Context(), limit, offset)
+	if page.CursorMode(r.Context()) {
+		listPubkeysByCursor(w, r, limit)
+		return
+	}
+
+	pubkeys, err := pubkeyDao.List(r.Context(), nil, limit, offset)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkeys", err))
 		return
@@ -73,6 +131,39 @@ func ListPubkeys(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// listPubkeysByCursor renders pubkeys using keyset pagination, total count is not returned.
+func listPubkeysByCursor(w http.ResponseWriter, r *http.Request, limit int64) {
+	pubkeyDao := dao.GetPubkeyDao(r.Context())
+
+	cursor, err := page.CurrentCursor(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid query parameter", err))
+		return
+	}
+
+	// one extra record to find out if there is a next page
+	pubkeys, err := pubkeyDao.List(r.Context(), &dao.PubkeyFilter{AfterID: cursor.LastID}, limit+1, 0)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkeys", err))
+		return
+	}
+
+	var next *page.Cursor
+	if int64(len(pubkeys)) > limit {
+		pubkeys = pubkeys[:limit]
+		if limit > 0 {
+			next = &page.Cursor{LastID: pubkeys[limit-1].ID}
+		}
+	}
+
+	meta := page.NewCursorMetadata(r.Context(), r, next)
+
+	if err := render.Render(w, r, payloads.NewPubkeyListResponse(pubkeys, meta)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkeys list", err))
+		return
+	}
+}
+
 func GetPubkey(w http.ResponseWriter, r *http.Request) {
 	id, err := ParseInt64(r, "ID")
 	if err != nil {
@@ -89,6 +180,130 @@ func GetPubkey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(r.Context(), pubkey.ID)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list pubkey resources", err))
+		return
+	}
+
+	if err := render.Render(w, r, payloads.NewPubkeyResponseWithResources(pubkey, resources)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+	}
+}
+
+func UpdatePubkey(w http.ResponseWriter, r *http.Request) {
+	logger := zerolog.Ctx(r.Context())
+
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	payload := &payloads.PubkeyUpdateRequest{}
+	if err = render.Bind(r, payload); err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "update pubkey", err))
+		return
+	}
+
+	if payload.Name == "" {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), ErrMissingName.Error(), ErrMissingName))
+		return
+	}
+
+	pubkeyDao := dao.GetPubkeyDao(r.Context())
+
+	pubkey, err := pubkeyDao.GetById(r.Context(), id)
+	if err != nil {
+		message := fmt.Sprintf("get pubkey with id %d", id)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	if pubkey.Name == payload.Name {
+		if err := render.Render(w, r, payloads.NewPubkeyResponse(pubkey)); err != nil {
+			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
+		}
+		return
+	}
+
+	resources, err := pubkeyDao.UnscopedListResourcesByPubkeyId(r.Context(), pubkey.ID)
+	if err != nil {
+		message := fmt.Sprintf("list resources by pubkey id %d", pubkey.ID)
+		renderNotFoundOrDAOError(w, r, err, message)
+		return
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewClientError(r.Context(), err))
+		return
+	}
+
+	// AWS does not support renaming of key-pairs, the key is imported under the new name and the old one
+	// is deleted. Key-pairs are renamed before the pubkey, so a failure leaves the name unchanged and
+	// the request can be repeated. Launches find key-pairs by fingerprint, names do not need to match.
+	renamed := *pubkey
+	renamed.Name = payload.Name
+	for _, res := range resources {
+		if res.Provider != models.ProviderTypeAWS || res.Handle == "" {
+			continue
+		}
+
+		authentication, errAuth := sourcesClient.GetAuthentication(r.Context(), res.SourceID)
+		if errors.Is(errAuth, httpClients.ErrAuthenticationForSourcesNotFound) {
+			logger.Warn().Msgf("Skipping source %s authorization which is no longer available", res.SourceID)
+			continue
+		} else if errAuth != nil {
+			logger.Warn().Err(errAuth).Msg("Skipping source authorization because sources returned an error")
+			continue
+		}
+
+		ec2Client, errEc2 := clients.GetEC2Client(r.Context(), authentication, res.Region)
+		if errEc2 != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS client", errEc2))
+			return
+		}
+
+		handle, errImport := ec2Client.ImportPubkey(r.Context(), &renamed, res.FormattedTag())
+		if errors.Is(errImport, httpClients.ErrDuplicatePubkey) {
+			logger.Warn().Msgf("Skipping rename of pubkey resource %d, key-pair named '%s' already exists in region %s", res.ID, renamed.Name, res.Region)
+			continue
+		} else if errImport != nil {
+			renderError(w, r, payloads.NewAWSError(r.Context(), "unable to import AWS public key", errImport))
+			return
+		}
+
+		// the resource points to the new key-pair before the old one is deleted, so it is never orphaned
+		oldHandle := res.Handle
+		res.Handle = handle
+		err = pubkeyDao.UnscopedUpdateResource(r.Context(), res)
+		if err != nil {
+			if errDelete := ec2Client.DeleteSSHKey(r.Context(), handle); errDelete != nil {
+				logger.Warn().Err(errDelete).Msgf("Unable to delete imported key-pair %s", handle)
+			}
+			renderError(w, r, payloads.NewDAOError(r.Context(), "update pubkey resource", err))
+			return
+		}
+
+		logger.Info().Msgf("Renamed pubkey resource ID %v from handle %s to %s", res.ID, oldHandle, handle)
+		errDelete := ec2Client.DeleteSSHKey(r.Context(), oldHandle)
+		if errDelete != nil {
+			logger.Warn().Err(errDelete).Msgf("Unable to delete renamed key-pair %s, leaving it in place", oldHandle)
+		}
+	}
+
+	pubkey.Name = payload.Name
+	err = pubkeyDao.Update(r.Context(), pubkey)
+	if err != nil {
+		if db.IsPostgresError(err, db.UniqueConstraintErrorCode) != nil {
+			renderError(w, r, payloads.PubkeyDuplicateError(r.Context(), "pubkey with such name already exists for this account", err))
+		} else {
+			renderError(w, r, payloads.NewDAOError(r.Context(), "update pubkey", err))
+		}
+		return
+	}
+
 	if err := render.Render(w, r, payloads.NewPubkeyResponse(pubkey)); err != nil {
 		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render pubkey", err))
 	}
@@ -125,32 +340,61 @@ func DeletePubkey(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, res := range resources {
-		if res.Provider == models.ProviderTypeAWS {
-			if res.Handle != "" {
-				logger.Info().Msgf("Deleting pubkey resource ID %v with handle %s", res.ID, res.Handle)
-				authentication, errAuth := sourcesClient.GetAuthentication(r.Context(), res.SourceID)
-				if errAuth == nil {
-					ec2Client, errEc2 := clients.GetEC2Client(r.Context(), authentication, res.Region)
-					if errEc2 != nil {
-						renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS client", errEc2))
-						return
-					}
-
-					errDelete := ec2Client.DeleteSSHKey(r.Context(), res.Handle)
-					if errDelete != nil {
-						renderError(w, r, payloads.NewAWSError(r.Context(), "unable to delete AWS public key", errDelete))
-						return
-					}
-				} else if errors.Is(errAuth, httpClients.ErrAuthenticationForSourcesNotFound) {
-					logger.Warn().Msgf("Skipping source %s authorization which is no longer available", res.SourceID)
-				} else {
-					logger.Warn().Err(errAuth).Msg("Skipping source authorization because sources returned an error")
-				}
-			} else {
-				logger.Warn().Msgf("Skipping pubkey resource %d with empty handle", res.ID)
+		if res.Handle == "" {
+			logger.Warn().Msgf("Skipping pubkey resource %d with empty handle", res.ID)
+			continue
+		}
+
+		logger.Info().Msgf("Deleting pubkey resource ID %v with handle %s", res.ID, res.Handle)
+		authentication, errAuth := sourcesClient.GetAuthentication(r.Context(), res.SourceID)
+		if errors.Is(errAuth, httpClients.ErrAuthenticationForSourcesNotFound) {
+			logger.Warn().Msgf("Skipping source %s authorization which is no longer available", res.SourceID)
+			continue
+		} else if errAuth != nil {
+			logger.Warn().Err(errAuth).Msg("Skipping source authorization because sources returned an error")
+			continue
+		}
+
+		switch res.Provider {
+		case models.ProviderTypeAWS:
+			ec2Client, errEc2 := clients.GetEC2Client(r.Context(), authentication, res.Region)
+			if errEc2 != nil {
+				renderError(w, r, payloads.NewAWSError(r.Context(), "unable to get AWS client", errEc2))
+				return
 			}
-		} else {
+
+			errDelete := ec2Client.DeleteSSHKey(r.Context(), res.Handle)
+			if errDelete != nil {
+				renderError(w, r, payloads.NewAWSError(r.Context(), "unable to delete AWS public key", errDelete))
+				return
+			}
+		case models.ProviderTypeAzure:
+			azureClient, errAzure := clients.GetAzureClient(r.Context(), authentication)
+			if errAzure != nil {
+				renderError(w, r, payloads.NewAzureError(r.Context(), "unable to get Azure client", errAzure))
+				return
+			}
+
+			errDelete := azureClient.DeleteSSHKey(r.Context(), res.Handle)
+			if errDelete != nil {
+				renderError(w, r, payloads.NewAzureError(r.Context(), "unable to delete Azure SSH public key", errDelete))
+				return
+			}
+		case models.ProviderTypeGCP:
+			gcpClient, errGCP := clients.GetGCPClient(r.Context(), authentication)
+			if errGCP != nil {
+				renderError(w, r, payloads.NewGCPError(r.Context(), "unable to get GCP client", errGCP))
+				return
+			}
+
+			errDelete := gcpClient.RemoveProjectSSHKey(r.Context(), res.Handle)
+			if errDelete != nil {
+				renderError(w, r, payloads.NewGCPError(r.Context(), "unable to remove GCP project SSH key", errDelete))
+				return
+			}
+		default:
 			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "delete not implemented for this provider", ErrProviderTypeNotImplemented))
+			return
 		}
 	}
 
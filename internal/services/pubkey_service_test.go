@@ -8,12 +8,17 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/RHEnVision/provisioning-backend/internal/services"
 	_ "github.com/RHEnVision/provisioning-backend/internal/testing/initialization"
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/require"
 
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/middleware"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/factories"
 	"github.com/RHEnVision/provisioning-backend/internal/testing/identity"
@@ -52,6 +57,47 @@ func TestListPubkeysHandler(t *testing.T) {
 	assert.Equal(t, 2, len(result.Data), "expected two pubkeys in response json")
 }
 
+func TestListPubkeysByCursorHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	for i := 0; i < 3; i++ {
+		err := stubs.AddPubkey(ctx, &models.Pubkey{
+			Name: factories.SeqNameWithPrefix("pubkey"),
+			Body: factories.GenerateRSAPubKey(t),
+		})
+		require.NoError(t, err, "failed to add stubbed key")
+	}
+
+	list := func(t *testing.T, url string) payloads.PubkeyListResponse {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := middleware.Pagination(http.HandlerFunc(services.ListPubkeys))
+		handler.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var result payloads.PubkeyListResponse
+		err = json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		return result
+	}
+
+	first := list(t, "/api/provisioning/pubkeys?limit=2&cursor=")
+	require.Equal(t, 2, len(first.Data), "expected two pubkeys on the first page")
+	require.NotEmpty(t, first.Metadata.Links.Next, "expected link to the next page")
+	assert.Zero(t, first.Metadata.Total, "total is not returned for cursor pagination")
+
+	second := list(t, first.Metadata.Links.Next)
+	require.Equal(t, 1, len(second.Data), "expected one pubkey on the second page")
+	assert.Greater(t, second.Data[0].ID, first.Data[1].ID)
+	assert.Empty(t, second.Metadata.Links.Next, "expected last page")
+}
+
 func TestCreatePubkeyHandler(t *testing.T) {
 	var err error
 	var json_data []byte
@@ -81,3 +127,104 @@ func TestCreatePubkeyHandler(t *testing.T) {
 	stubCount := stubs.PubkeyStubCount(ctx)
 	assert.Equal(t, 1, stubCount, "Pubkey has not been Created through DAO")
 }
+
+func TestUpdatePubkeyHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithEC2Client(ctx)
+
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	ec2Client, err := clients.GetEC2Client(ctx, nil, "us-east-1")
+	require.NoError(t, err, "failed to get stubbed ec2 client")
+	pkr := &models.PubkeyResource{
+		PubkeyID: pk.ID,
+		Provider: models.ProviderTypeAWS,
+		SourceID: "1",
+		Region:   "us-east-1",
+	}
+	pkr.RandomizeTag()
+	pkr.Handle, err = ec2Client.ImportPubkey(ctx, pk, pkr.FormattedTag())
+	require.NoError(t, err, "failed to import stubbed key")
+	oldHandle := pkr.Handle
+	err = stubs.AddPubkeyResource(ctx, pkr)
+	require.NoError(t, err, "failed to add stubbed key resource")
+
+	json_data, err := json.Marshal(map[string]interface{}{"name": "renamed key"})
+	require.NoError(t, err, "unable to marshal values to json")
+
+	rctx := chi.NewRouteContext()
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	rctx.URLParams.Add("ID", "1")
+	req, err := http.NewRequestWithContext(ctx, "PATCH", "/api/provisioning/pubkeys/1", bytes.NewBuffer(json_data))
+	require.NoError(t, err, "failed to create request")
+	req.Header.Add("Content-Type", "application/json")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.UpdatePubkey)
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code, "Handler returned wrong status code")
+
+	var result payloads.PubkeyResponse
+	err = json.NewDecoder(rr.Body).Decode(&result)
+	require.NoError(t, err, "failed to decode response body")
+	assert.Equal(t, "renamed key", result.Name)
+
+	resources, err := dao.GetPubkeyDao(ctx).UnscopedListResourcesByPubkeyId(ctx, pk.ID)
+	require.NoError(t, err, "failed to list key resources")
+	require.Len(t, resources, 1)
+	assert.NotEqual(t, oldHandle, resources[0].Handle, "key-pair was not re-imported")
+
+	name, err := ec2Client.GetPubkeyName(ctx, pk.FindAwsFingerprint(ctx))
+	require.NoError(t, err, "key-pair not found on AWS")
+	assert.Equal(t, "renamed key", name)
+}
+
+func TestGeneratePubkeyHandler(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = identity.WithTenant(t, ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+
+	generate := func(t *testing.T, values map[string]interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+
+		json_data, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+		req, err := http.NewRequestWithContext(ctx, "POST", "/api/provisioning/pubkeys/generate", bytes.NewBuffer(json_data))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.GeneratePubkey)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("ed25519", func(t *testing.T) {
+		rr := generate(t, map[string]interface{}{"name": "generated key"})
+		require.Equal(t, http.StatusCreated, rr.Code, "Handler returned wrong status code")
+		assert.Equal(t, "no-store", rr.Header().Get("Cache-Control"))
+		assert.Equal(t, "no-cache", rr.Header().Get("Pragma"))
+
+		var result payloads.PubkeyGenerateResponse
+		err := json.NewDecoder(rr.Body).Decode(&result)
+		require.NoError(t, err, "failed to decode response body")
+		assert.Equal(t, "ssh-ed25519", result.Type)
+		assert.NotEmpty(t, result.Fingerprint)
+		assert.Contains(t, result.PrivateKey, "BEGIN OPENSSH PRIVATE KEY")
+
+		stored, err := dao.GetPubkeyDao(ctx).GetById(ctx, result.ID)
+		require.NoError(t, err, "generated pubkey was not stored")
+		assert.Equal(t, result.Body, stored.Body)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		rr := generate(t, map[string]interface{}{"name": "generated dss key", "type": "ssh-dss"})
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Handler returned wrong status code")
+	})
+}
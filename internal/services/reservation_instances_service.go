@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/RHEnVision/provisioning-backend/internal/cache"
+	"github.com/RHEnVision/provisioning-backend/internal/clients"
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
+)
+
+var ErrInvalidRefreshParam = errors.New("refresh parameter must be a boolean")
+
+// ListReservationInstances returns instances launched by a reservation. Addresses are stored when
+// instances are launched, with the refresh parameter set the current power state and addresses are
+// fetched from the cloud provider and stored. Fetched descriptions are cached for a short time.
+func ListReservationInstances(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	refresh := false
+	if str := r.URL.Query().Get("refresh"); str != "" {
+		refresh, err = strconv.ParseBool(str)
+		if err != nil {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid query parameter", ErrInvalidRefreshParam))
+			return
+		}
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation")
+		return
+	}
+
+	if CheckPermissionAndRender(w, r, "read", "reservation", reservation.Provider.String()) != nil {
+		return
+	}
+
+	instances, err := rDao.ListInstances(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "list reservation instances")
+		return
+	}
+
+	if refresh && len(instances) > 0 {
+		descriptions, err := refreshInstanceDescriptions(r.Context(), reservation, instances)
+		if errors.Is(err, ErrProviderTypeNotImplemented) {
+			renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", err))
+			return
+		} else if err != nil {
+			renderError(w, r, payloads.NewClientError(r.Context(), err))
+			return
+		}
+
+		for _, instance := range instances {
+			for _, description := range descriptions {
+				if description.ID == instance.InstanceID {
+					instance.Detail = models.ReservationInstanceDetail{
+						PublicDNS:   description.DNS,
+						PublicIPv4:  description.IPv4,
+						PrivateIPv4: description.PrivateIPv4,
+						PrivateIPv6: description.PrivateIPv6,
+						State:       description.State,
+						Zone:        instance.Detail.Zone,
+					}
+				}
+			}
+		}
+	}
+
+	if err := render.Render(w, r, payloads.NewInstanceListResponse(instances)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation instances", err))
+	}
+}
+
+// refreshInstanceDescriptions returns current descriptions of reservation instances from cache or from
+// the cloud provider. Fetched descriptions are stored in the database and cached.
+func refreshInstanceDescriptions(ctx context.Context, reservation *models.Reservation, instances []*models.ReservationInstance) (clients.InstanceDescriptionList, error) {
+	logger := zerolog.Ctx(ctx)
+	cacheKey := strconv.FormatInt(reservation.ID, 10)
+
+	var result clients.InstanceDescriptionList
+	err := cache.Find(ctx, cacheKey, &result)
+	if err == nil {
+		return result, nil
+	} else if !errors.Is(err, cache.ErrNotFound) {
+		return nil, fmt.Errorf("cache find error: %w", err)
+	}
+
+	sourceID, location, err := dao.ReservationSourceAndLocation(ctx, reservation)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcesClient, err := clients.GetSourcesClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get sources client: %w", err)
+	}
+
+	authentication, err := sourcesClient.GetAuthentication(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get authentication: %w", err)
+	}
+
+	if typeErr := authentication.MustBe(reservation.Provider); typeErr != nil {
+		return nil, fmt.Errorf("unexpected source type: %w", typeErr)
+	}
+
+	result, err = describeInstances(ctx, reservation.Provider, authentication, location, instances)
+	if err != nil {
+		return nil, err
+	}
+
+	rDao := dao.GetReservationDao(ctx)
+	for _, description := range result {
+		err = rDao.UpdateReservationInstance(ctx, reservation.ID, description)
+		if err != nil {
+			return nil, fmt.Errorf("cannot update instance description: %w", err)
+		}
+	}
+
+	err = cache.SetExpires(ctx, cacheKey, &result, config.Application.Cache.InstanceExpiration)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to cache instance descriptions")
+	}
+
+	return result, nil
+}
+
+// describeInstances fetches instance descriptions from the cloud provider.
+func describeInstances(ctx context.Context, provider models.ProviderType, authentication *clients.Authentication, location string, instances []*models.ReservationInstance) (clients.InstanceDescriptionList, error) {
+	logger := zerolog.Ctx(ctx)
+
+	instanceIds := make([]string, len(instances))
+	for i, instance := range instances {
+		instanceIds[i] = instance.InstanceID
+	}
+
+	//nolint:exhaustive
+	switch provider {
+	case models.ProviderTypeAWS:
+		ec2Client, err := clients.GetEC2Client(ctx, authentication, location)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get AWS client: %w", err)
+		}
+
+		descriptions, err := ec2Client.DescribeInstanceDetails(ctx, instanceIds)
+		if errors.Is(err, clients.ErrNotFound) {
+			// the whole call fails when an instance was deleted, describe them one by one
+			descriptions = make(clients.InstanceDescriptionList, 0, len(instanceIds))
+			for _, id := range instanceIds {
+				instanceDescriptions, err := ec2Client.DescribeInstanceDetails(ctx, []string{id})
+				if errors.Is(err, clients.ErrNotFound) {
+					// deleted instances are not found, keep the stored description
+					logger.Warn().Err(err).Str("instance_id", id).Msg("Unable to describe AWS instance")
+					continue
+				} else if err != nil {
+					return nil, fmt.Errorf("unable to describe instance: %w", err)
+				}
+				descriptions = append(descriptions, instanceDescriptions...)
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to describe instances: %w", err)
+		}
+		return descriptions, nil
+	case models.ProviderTypeAzure:
+		azureClient, err := clients.GetAzureClient(ctx, authentication)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get Azure client: %w", err)
+		}
+
+		result := make(clients.InstanceDescriptionList, 0, len(instanceIds))
+		for _, id := range instanceIds {
+			description, err := azureClient.DescribeVM(ctx, id)
+			if err != nil {
+				// keep the stored description
+				logger.Warn().Err(err).Str("instance_id", id).Msg("Unable to describe Azure virtual machine")
+				continue
+			}
+			result = append(result, description)
+		}
+		return result, nil
+	case models.ProviderTypeGCP:
+		gcpClient, err := clients.GetGCPClient(ctx, authentication)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get GCP client: %w", err)
+		}
+
+		result := make(clients.InstanceDescriptionList, 0, len(instanceIds))
+		for _, instance := range instances {
+			description, err := gcpClient.GetInstanceDescriptionByID(ctx, instance.InstanceID, instance.ZoneOrDefault(location))
+			if err != nil {
+				// deleted instances are not found, keep the stored description
+				logger.Warn().Err(err).Str("instance_id", instance.InstanceID).Msg("Unable to describe GCP instance")
+				continue
+			}
+			result = append(result, description)
+		}
+		return result, nil
+	default:
+		return nil, ErrProviderTypeNotImplemented
+	}
+}
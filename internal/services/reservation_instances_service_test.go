@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
+	"github.com/RHEnVision/provisioning-backend/internal/dao"
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"github.com/RHEnVision/provisioning-backend/internal/payloads"
+	"github.com/RHEnVision/provisioning-backend/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listReservationInstancesRequest(t *testing.T, ctx context.Context, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rctx := chi.NewRouteContext()
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+	rctx.URLParams.Add("ID", "1")
+	req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/v1/reservations/1/instances?"+query, nil)
+	require.NoError(t, err, "failed to create request")
+
+	rr := httptest.NewRecorder()
+	handler := http.HandlerFunc(services.ListReservationInstances)
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestListReservationInstancesHandler(t *testing.T) {
+	t.Run("stored", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+
+		rr := listReservationInstancesRequest(t, ctx, "")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var result payloads.InstanceListResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "i-0a4caa2cf5b097ce1", result.Data[0].InstanceID)
+		assert.Empty(t, result.Data[0].Detail.PublicIPv4)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+		ctx = clientStubs.WithEC2Client(ctx)
+
+		rr := listReservationInstancesRequest(t, ctx, "refresh=true")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var result payloads.InstanceListResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		require.Len(t, result.Data, 1)
+		assert.Equal(t, "54.11.88.17", result.Data[0].Detail.PublicIPv4)
+		assert.Equal(t, models.InstanceStateRunning, result.Data[0].Detail.State)
+
+		// refreshed description is stored
+		rr = listReservationInstancesRequest(t, ctx, "")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		assert.Equal(t, "54.11.88.17", result.Data[0].Detail.PublicIPv4)
+	})
+
+	t.Run("refresh deleted instance", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+		ctx = clientStubs.WithEC2Client(ctx)
+
+		err := dao.GetReservationDao(ctx).CreateInstance(ctx, &models.ReservationInstance{
+			ReservationID: 1,
+			InstanceID:    "i-deleted",
+		})
+		require.NoError(t, err, "failed to create stub instance")
+		clientStubs.StubDeleteInstanceEC2(ctx, "i-deleted")
+
+		rr := listReservationInstancesRequest(t, ctx, "refresh=true")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var result payloads.InstanceListResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&result), "failed to decode response body")
+		require.Len(t, result.Data, 2)
+		assert.Equal(t, "54.11.88.17", result.Data[0].Detail.PublicIPv4)
+		assert.Empty(t, result.Data[1].Detail.PublicIPv4)
+	})
+
+	t.Run("invalid refresh", func(t *testing.T) {
+		ctx := prepareInstanceActionContext(t)
+
+		rr := listReservationInstancesRequest(t, ctx, "refresh=maybe")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+}
@@ -1,9 +1,12 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/dao"
@@ -12,6 +15,7 @@ import (
 	"github.com/RHEnVision/provisioning-backend/internal/payloads"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/rs/zerolog"
 )
 
 var (
@@ -23,8 +27,31 @@ var (
 	ErrBothTypeAndTemplateMissing = errors.New("instance type or launch template not set")
 	ErrUnsupportedRegion          = errors.New("unknown region/location/zone")
 	ErrInvalidNamePattern         = errors.New("name pattern is not RFC-1035 compatible")
+	ErrReservationFinished        = errors.New("reservation is already finished")
+	ErrExpiryConflict             = errors.New("expires_at and ttl are mutually exclusive")
+	ErrExpiryInPast               = errors.New("reservation expiry must be in the future")
+	ErrExpiryTooLong              = errors.New("reservation expiry exceeds maximum time-to-live")
+	ErrIdempotencyKeyTooLong      = errors.New("idempotency key is too long")
+	ErrIdempotencyKeyMismatch     = errors.New("idempotency key was used for a different provider type")
+	ErrInvalidReservationFilter   = errors.New("invalid reservation filter")
+	ErrInvalidNetworkID           = errors.New("invalid network ID")
+	ErrInvalidSubnetID            = errors.New("subnet ID must start with subnet-")
+	ErrInvalidSecurityGroupID     = errors.New("security group ID must start with sg-")
+	ErrInvalidSubnetResourceID    = errors.New("invalid subnet resource ID")
+	ErrInvalidSGResourceID        = errors.New("invalid security group resource ID")
+	ErrInvalidTemplateSpecID      = errors.New("launch template must be a template spec resource ID")
+	ErrTemplateSpecConflict       = errors.New("volumes, spot, placement and networking are defined by the launch template")
+	ErrUnsupportedZone            = errors.New("unknown zone or instance type not available in the zone")
+	ErrPlacementSubnetConflict    = errors.New("placement zones cannot be combined with subnet")
 )
 
+// IdempotencyKeyHeader is an optional request header. A repeated reservation request with the same
+// key returns the original reservation instead of creating a new one.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maximum length of idempotency key (size of the database column)
+const maxIdempotencyKeyLength = 255
+
 // CreateReservation dispatches requests to type provider specific handlers
 func CreateReservation(w http.ResponseWriter, r *http.Request) {
 	if !config.LaunchEnabled(r.Context()) {
@@ -38,6 +65,16 @@ func CreateReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Repeated request returns the original reservation
+	key := idempotencyKey(r)
+	if len(key.String) > maxIdempotencyKeyLength {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid idempotency key", ErrIdempotencyKeyTooLong))
+		return
+	}
+	if key.Valid && renderIdempotentReservation(w, r, key.String, pType) {
+		return
+	}
+
 	switch pType {
 	case models.ProviderTypeNoop:
 		CreateNoopReservation(w, r)
@@ -58,19 +95,111 @@ func CreateReservation(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// idempotencyKey returns value of the idempotency key header, it is validated in CreateReservation.
+func idempotencyKey(r *http.Request) sql.NullString {
+	key := r.Header.Get(IdempotencyKeyHeader)
+	return sql.NullString{String: key, Valid: key != ""}
+}
+
+// renderIdempotentReservation renders a reservation created with the same idempotency key and returns
+// true. When there is no such reservation, nothing is rendered and false is returned.
+func renderIdempotentReservation(w http.ResponseWriter, r *http.Request, key string, pType models.ProviderType) bool {
+	reservation, err := dao.GetReservationDao(r.Context()).GetByIdempotencyKey(r.Context(), key)
+	if errors.Is(err, dao.ErrNoRows) {
+		return false
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "get reservation by idempotency key", err))
+		return true
+	}
+
+	if reservation.Provider != pType {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid idempotency key", ErrIdempotencyKeyMismatch))
+		return true
+	}
+
+	zerolog.Ctx(r.Context()).Info().Msgf("Repeated request with idempotency key, returning reservation %d", reservation.ID)
+	renderReservationDetail(w, r, reservation, pType)
+	return true
+}
+
+// parseReservationFilter parses reservation list query parameters: provider, status, source_id,
+// instance_id, created_after, created_before (RFC 3339) and sort (attribute with optional "-" prefix
+// for descending order).
+func parseReservationFilter(r *http.Request) (*dao.ReservationFilter, error) {
+	query := r.URL.Query()
+	filter := &dao.ReservationFilter{
+		SourceID:   query.Get("source_id"),
+		InstanceID: query.Get("instance_id"),
+	}
+
+	if provider := query.Get("provider"); provider != "" {
+		filter.Provider = models.ProviderTypeFromString(provider)
+		if filter.Provider == models.ProviderTypeUnknown {
+			return nil, fmt.Errorf("%w: unknown provider '%s'", ErrInvalidReservationFilter, provider)
+		}
+	}
+
+	switch status := dao.ReservationStatus(query.Get("status")); status {
+	case dao.ReservationStatusAny, dao.ReservationStatusPending, dao.ReservationStatusSuccess, dao.ReservationStatusFailure:
+		filter.Status = status
+	default:
+		return nil, fmt.Errorf("%w: unknown status '%s'", ErrInvalidReservationFilter, status)
+	}
+
+	for param, value := range map[string]*time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	} {
+		if str := query.Get(param); str != "" {
+			t, err := time.Parse(time.RFC3339, str)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s is not RFC 3339 time: %s", ErrInvalidReservationFilter, param, err.Error())
+			}
+			*value = t.UTC()
+		}
+	}
+
+	sort := query.Get("sort")
+	if strings.HasPrefix(sort, "-") {
+		filter.SortDesc = true
+		sort = sort[1:]
+	}
+	switch sortBy := dao.ReservationSortBy(sort); sortBy {
+	case "", dao.ReservationSortByID:
+		filter.SortBy = dao.ReservationSortByID
+	case dao.ReservationSortByCreatedAt, dao.ReservationSortByFinishedAt:
+		filter.SortBy = sortBy
+	default:
+		return nil, fmt.Errorf("%w: unknown sort attribute '%s'", ErrInvalidReservationFilter, sort)
+	}
+
+	return filter, nil
+}
+
 func ListReservations(w http.ResponseWriter, r *http.Request) {
 	rDao := dao.GetReservationDao(r.Context())
 
 	offset := page.Offset(r.Context()).Int64()
 	limit := page.Limit(r.Context()).Int64()
 
-	reservations, err := rDao.List(r.Context(), limit, offset)
+	filter, err := parseReservationFilter(r)
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid query parameter", err))
+		return
+	}
+
+	if page.CursorMode(r.Context()) {
+		listReservationsByCursor(w, r, filter, limit)
+		return
+	}
+
+	reservations, err := rDao.List(r.Context(), filter, limit, offset)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservations", err))
 		return
 	}
 
-	totalRes, err := rDao.Count(r.Context())
+	totalRes, err := rDao.Count(r.Context(), filter)
 	if err != nil {
 		renderError(w, r, payloads.NewDAOError(r.Context(), "count reservations", err))
 		return
@@ -84,6 +213,46 @@ func ListReservations(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// listReservationsByCursor renders reservations using keyset pagination which is stable when
+// reservations are created concurrently. Total count is not returned.
+func listReservationsByCursor(w http.ResponseWriter, r *http.Request, filter *dao.ReservationFilter, limit int64) {
+	rDao := dao.GetReservationDao(r.Context())
+
+	cursor, err := page.CurrentCursor(r.Context())
+	if err != nil {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid query parameter", err))
+		return
+	}
+	if filter.SortBy != dao.ReservationSortByID {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "invalid query parameter",
+			fmt.Errorf("%w: cursor pagination requires sorting by id", ErrInvalidReservationFilter)))
+		return
+	}
+	filter.AfterID = cursor.LastID
+
+	// one extra record to find out if there is a next page
+	reservations, err := rDao.List(r.Context(), filter, limit+1, 0)
+	if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "list reservations", err))
+		return
+	}
+
+	var next *page.Cursor
+	if int64(len(reservations)) > limit {
+		reservations = reservations[:limit]
+		if limit > 0 {
+			next = &page.Cursor{LastID: reservations[limit-1].ID}
+		}
+	}
+
+	meta := page.NewCursorMetadata(r.Context(), r, next)
+
+	if err := render.Render(w, r, payloads.NewReservationListResponse(reservations, meta)); err != nil {
+		renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservations list", err))
+		return
+	}
+}
+
 func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 	provider := chi.URLParam(r, "TYPE")
 	providerType := models.ProviderTypeFromString(provider)
@@ -111,6 +280,14 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	renderReservationDetail(w, r, reservation, providerType)
+}
+
+// renderReservationDetail renders generic reservation or provider-specific reservation with instances
+// when providerType is set.
+func renderReservationDetail(w http.ResponseWriter, r *http.Request, reservation *models.Reservation, providerType models.ProviderType) {
+	rDao := dao.GetReservationDao(r.Context())
+
 	switch providerType {
 	// Generic reservation request will have provider == "" and thus render this
 	case models.ProviderTypeUnknown, models.ProviderTypeNoop:
@@ -118,16 +295,16 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
 		}
 	case models.ProviderTypeAWS:
-		reservationAws, err := rDao.GetAWSById(r.Context(), id)
+		reservationAws, err := rDao.GetAWSById(r.Context(), reservation.ID)
 		if err != nil {
-			message := fmt.Sprintf("get AWS reservation with id %d", id)
+			message := fmt.Sprintf("get AWS reservation with id %d", reservation.ID)
 			renderNotFoundOrDAOError(w, r, err, message)
 			return
 		}
 
-		instances, err := rDao.ListInstances(r.Context(), id)
+		instances, err := rDao.ListInstances(r.Context(), reservation.ID)
 		if err != nil {
-			message := fmt.Sprintf("get reservation with id %d", id)
+			message := fmt.Sprintf("get reservation with id %d", reservation.ID)
 			renderNotFoundOrDAOError(w, r, err, message)
 			return
 		}
@@ -136,16 +313,16 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
 		}
 	case models.ProviderTypeAzure:
-		reservationAzure, err := rDao.GetAzureById(r.Context(), id)
+		reservationAzure, err := rDao.GetAzureById(r.Context(), reservation.ID)
 		if err != nil {
-			message := fmt.Sprintf("get Azure reservation with id %d", id)
+			message := fmt.Sprintf("get Azure reservation with id %d", reservation.ID)
 			renderNotFoundOrDAOError(w, r, err, message)
 			return
 		}
 
-		instances, err := rDao.ListInstances(r.Context(), id)
+		instances, err := rDao.ListInstances(r.Context(), reservation.ID)
 		if err != nil {
-			message := fmt.Sprintf("get reservation instances with id %d", id)
+			message := fmt.Sprintf("get reservation instances with id %d", reservation.ID)
 			renderNotFoundOrDAOError(w, r, err, message)
 			return
 		}
@@ -154,16 +331,16 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 			renderError(w, r, payloads.NewRenderError(r.Context(), "unable to render reservation", err))
 		}
 	case models.ProviderTypeGCP:
-		reservationGCP, err := rDao.GetGCPById(r.Context(), id)
+		reservationGCP, err := rDao.GetGCPById(r.Context(), reservation.ID)
 		if err != nil {
-			message := fmt.Sprintf("get GCP reservation with id %d", id)
+			message := fmt.Sprintf("get GCP reservation with id %d", reservation.ID)
 			renderNotFoundOrDAOError(w, r, err, message)
 			return
 		}
 
-		instances, err := rDao.ListInstances(r.Context(), id)
+		instances, err := rDao.ListInstances(r.Context(), reservation.ID)
 		if err != nil {
-			message := fmt.Sprintf("get reservation with id %d", id)
+			message := fmt.Sprintf("get reservation with id %d", reservation.ID)
 			renderNotFoundOrDAOError(w, r, err, message)
 			return
 		}
@@ -175,3 +352,77 @@ func GetReservationDetail(w http.ResponseWriter, r *http.Request) {
 		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "provider is not supported", ErrProviderTypeNotImplemented))
 	}
 }
+
+// CancelReservation marks an unfinished reservation as cancelled. The launch job stops at the next
+// step and terminates instances which were already created.
+func CancelReservation(w http.ResponseWriter, r *http.Request) {
+	id, err := ParseInt64(r, "ID")
+	if err != nil {
+		renderError(w, r, payloads.NewURLParsingError(r.Context(), "unable to parse ID parameter", err))
+		return
+	}
+
+	rDao := dao.GetReservationDao(r.Context())
+	reservation, err := rDao.GetById(r.Context(), id)
+	if err != nil {
+		renderNotFoundOrDAOError(w, r, err, "get reservation")
+		return
+	}
+
+	if CheckPermissionAndRender(w, r, "write", "reservation", reservation.Provider.String()) != nil {
+		return
+	}
+
+	if reservation.CancelledAt.Valid {
+		writeNoContent(w, r)
+		return
+	}
+
+	if reservation.FinishedAt.Valid {
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "reservation cannot be cancelled", ErrReservationFinished))
+		return
+	}
+
+	err = rDao.Cancel(r.Context(), id)
+	if errors.Is(err, dao.ErrAffectedMismatch) {
+		// finished in the meantime
+		renderError(w, r, payloads.NewInvalidRequestError(r.Context(), "reservation cannot be cancelled", ErrReservationFinished))
+		return
+	} else if err != nil {
+		renderError(w, r, payloads.NewDAOError(r.Context(), "cancel reservation", err))
+		return
+	}
+
+	writeNoContent(w, r)
+}
+
+// parseExpiry returns expiry of a reservation from either an absolute time or a time-to-live
+// duration string. Returns invalid (NULL) time when neither is set. Zero maximum TTL means no limit.
+func parseExpiry(expiresAt *time.Time, ttl string) (sql.NullTime, error) {
+	if expiresAt != nil && ttl != "" {
+		return sql.NullTime{}, ErrExpiryConflict
+	}
+
+	now := time.Now()
+	var expiry time.Time
+	switch {
+	case expiresAt != nil:
+		expiry = *expiresAt
+	case ttl != "":
+		duration, err := time.ParseDuration(ttl)
+		if err != nil {
+			return sql.NullTime{}, fmt.Errorf("error parsing ttl '%s': %w", ttl, err)
+		}
+		expiry = now.Add(duration)
+	default:
+		return sql.NullTime{}, nil
+	}
+
+	if !expiry.After(now) {
+		return sql.NullTime{}, ErrExpiryInPast
+	}
+	if config.Reservation.MaxTTL > 0 && expiry.After(now.Add(config.Reservation.MaxTTL)) {
+		return sql.NullTime{}, ErrExpiryTooLong
+	}
+	return sql.NullTime{Time: expiry.UTC(), Valid: true}, nil
+}
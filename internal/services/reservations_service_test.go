@@ -1,14 +1,20 @@
 package services_test
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/clients"
 	"github.com/RHEnVision/provisioning-backend/internal/clients/http/rbac"
+	clientStubs "github.com/RHEnVision/provisioning-backend/internal/clients/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/dao/stubs"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/models"
@@ -75,3 +81,195 @@ func TestGetReservationDetail(t *testing.T) {
 		assert.Equal(t, int(models.ProviderTypeAWS), response.Provider, "expected provider to be AWS in parsed json")
 	})
 }
+
+func TestCancelReservation(t *testing.T) {
+	prepareContext := func(t *testing.T) (context.Context, *models.AWSReservation) {
+		t.Helper()
+
+		ctx := stubs.WithAccountDaoOne(context.Background())
+		ctx = tidentity.WithTenant(t, ctx)
+		ctx = stubs.WithPubkeyDao(ctx)
+		ctx = stubs.WithReservationDao(ctx)
+		ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+
+		pk := factories.NewPubkeyRSA()
+		err := stubs.AddPubkey(ctx, pk)
+		require.NoError(t, err, "failed to add stubbed key")
+
+		reservation := &models.AWSReservation{
+			PubkeyID: pk.ID,
+			SourceID: "1",
+			ImageID:  "ami-random",
+			Detail: &models.AWSDetail{
+				Region:       "us-east-1",
+				InstanceType: "t1.micro",
+				Amount:       1,
+			},
+		}
+		reservation.AccountID = identity.AccountId(ctx)
+		reservation.Status = "Created"
+		reservation.Provider = models.ProviderTypeAWS
+		reservation.Steps = 3
+		err = stubs.AddAWSReservation(ctx, reservation)
+		require.NoError(t, err, "failed to create stub reservation")
+
+		rctx := chi.NewRouteContext()
+		ctx = context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		rctx.URLParams.Add("ID", strconv.FormatInt(reservation.ID, 10))
+		return ctx, reservation
+	}
+
+	cancel := func(t *testing.T, ctx context.Context) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, "DELETE", "/api/provisioning/v1/reservations/1", nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CancelReservation)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("pending reservation", func(t *testing.T) {
+		ctx, reservation := prepareContext(t)
+
+		rr := cancel(t, ctx)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Wrong status code")
+		assert.True(t, reservation.CancelledAt.Valid, "expected reservation to be cancelled")
+
+		rr = cancel(t, ctx)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Repeated cancel should be no-op")
+	})
+
+	t.Run("finished reservation", func(t *testing.T) {
+		ctx, reservation := prepareContext(t)
+		reservation.FinishedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		reservation.Success = sql.NullBool{Bool: true, Valid: true}
+
+		rr := cancel(t, ctx)
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+		assert.False(t, reservation.CancelledAt.Valid, "finished reservation must not be cancelled")
+	})
+}
+
+func TestCreateReservationIdempotency(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = clientStubs.WithSourcesClient(ctx)
+	ctx = clientStubs.WithImageBuilderClient(ctx)
+	ctx = stubs.WithPubkeyDao(ctx)
+	ctx = stubs.WithReservationDao(ctx)
+	ctx = rbac.WithAcl(ctx, clients.AllPermissionsRbacAcl)
+
+	pk := factories.NewPubkeyRSA()
+	err := stubs.AddPubkey(ctx, pk)
+	require.NoError(t, err, "failed to add stubbed key")
+
+	create := func(t *testing.T, provider, key string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		values := map[string]interface{}{
+			"source_id":     "1",
+			"image_id":      "2bc640f6-927a-404a-9594-5b2da7e06608",
+			"amount":        1,
+			"instance_type": "t1.micro",
+			"pubkey_id":     pk.ID,
+		}
+		jsonData, err := json.Marshal(values)
+		require.NoError(t, err, "unable to marshal values to json")
+
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("TYPE", provider)
+		reqCtx := context.WithValue(ctx, chi.RouteCtxKey, rctx)
+		req, err := http.NewRequestWithContext(reqCtx, "POST", "/api/provisioning/v1/reservations/"+provider, bytes.NewBuffer(jsonData))
+		require.NoError(t, err, "failed to create request")
+		req.Header.Add("Content-Type", "application/json")
+		req.Header.Add(services.IdempotencyKeyHeader, key)
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.CreateReservation)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("repeated request", func(t *testing.T) {
+		rr := create(t, "aws", "retry-1")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+		var first payloads.AWSReservationResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&first), "failed to decode response body")
+
+		rr = create(t, "aws", "retry-1")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+		var second payloads.AWSReservationResponse
+		require.NoError(t, json.NewDecoder(rr.Body).Decode(&second), "failed to decode response body")
+
+		assert.Equal(t, first.ID, second.ID, "expected the original reservation")
+		assert.Equal(t, 1, stubs.AWSReservationStubCount(ctx), "expected a single reservation")
+	})
+
+	t.Run("different provider", func(t *testing.T) {
+		rr := create(t, "gcp", "retry-1")
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+
+	t.Run("too long key", func(t *testing.T) {
+		rr := create(t, "aws", strings.Repeat("x", 256))
+		require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+	})
+}
+
+func TestListReservationsFilter(t *testing.T) {
+	ctx := stubs.WithAccountDaoOne(context.Background())
+	ctx = tidentity.WithTenant(t, ctx)
+	ctx = stubs.WithReservationDao(ctx)
+
+	list := func(t *testing.T, query string) *httptest.ResponseRecorder {
+		t.Helper()
+
+		req, err := http.NewRequestWithContext(ctx, "GET", "/api/provisioning/v1/reservations?"+query, nil)
+		require.NoError(t, err, "failed to create request")
+
+		rr := httptest.NewRecorder()
+		handler := http.HandlerFunc(services.ListReservations)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("valid filter", func(t *testing.T) {
+		rr := list(t, "provider=aws&status=pending&source_id=1&created_after=2023-01-01T00:00:00Z&sort=-created_at")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+	})
+
+	t.Run("filtered and sorted", func(t *testing.T) {
+		for _, sourceID := range []string{"1", "2", "2"} {
+			reservation := &models.AWSReservation{SourceID: sourceID, Detail: &models.AWSDetail{}}
+			reservation.Provider = models.ProviderTypeAWS
+			err := stubs.AddAWSReservation(ctx, reservation)
+			require.NoError(t, err, "failed to create stub reservation")
+		}
+
+		rr := list(t, "source_id=2&sort=-id")
+		require.Equal(t, http.StatusOK, rr.Code, "Wrong status code")
+
+		var response payloads.GenericReservationListResponse
+		err := json.NewDecoder(rr.Body).Decode(&response)
+		require.NoError(t, err, "failed to decode response body")
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, int64(3), response.Data[0].ID)
+		assert.Equal(t, int64(2), response.Data[1].ID)
+	})
+
+	for name, query := range map[string]string{
+		"unknown provider": "provider=xyz",
+		"unknown status":   "status=done",
+		"invalid time":     "created_before=yesterday",
+		"unknown sort":     "sort=-source_id",
+	} {
+		query := query
+		t.Run(name, func(t *testing.T) {
+			rr := list(t, query)
+			require.Equal(t, http.StatusBadRequest, rr.Code, "Wrong status code")
+		})
+	}
+}
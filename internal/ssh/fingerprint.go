@@ -4,16 +4,21 @@ import (
 	"crypto/md5" //#nosec
 	"crypto/x509"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 
 	"golang.org/x/crypto/ssh"
 )
 
+var ErrNoAWSFingerprint = errors.New("AWS fingerprint cannot be calculated for key type")
+
 // OpenSSHFingerprints is the de-facto standard OpenSSH fingerprints for SSH public keys:
 // SHA256 (used for ED type keys) and MD5 (used for RSA keys). Fingerprints are returned as
 // string encoded into base64 or hex respectively. Additionally, type and comment are also
-// returned. Type as one of the: "ssh-ed25519" or "ssh-rsa".
+// returned. Type as one of the: "ssh-ed25519", "ssh-rsa", "ecdsa-sha2-nistp256" and others.
+// Fingerprints of certificates are calculated from the certified key like OpenSSH does, type
+// is the certificate type (e.g. "ssh-ed25519-cert-v01@openssh.com").
 type OpenSSHFingerprints struct {
 	Type    string
 	SHA256  string
@@ -52,8 +57,8 @@ func GenerateOpenSSHFingerprints(pubkeyBody []byte) (OpenSSHFingerprints, error)
 
 	fps.Comment = cmt
 	fps.Type = pkey.Type()
-	fps.SHA256 = strings.TrimLeft(ssh.FingerprintSHA256(pkey), "SHA256:") + "="
-	fps.MD5 = strings.TrimLeft(ssh.FingerprintLegacyMD5(pkey), "MD5:")
+	fps.SHA256 = strings.TrimLeft(ssh.FingerprintSHA256(plainKey(pkey)), "SHA256:") + "="
+	fps.MD5 = strings.TrimLeft(ssh.FingerprintLegacyMD5(plainKey(pkey)), "MD5:")
 
 	return fps, nil
 }
@@ -71,7 +76,11 @@ func GenerateAWSFingerprint(pubkeyBody []byte) (AWSFingerprint, error) {
 		return "", fmt.Errorf("unable to parse public key %s: %w", pubkeyBody, err)
 	}
 
-	parsedCryptoKey := pkey.(ssh.CryptoPublicKey)
+	// security keys carry application string and cannot be converted into PKIX
+	parsedCryptoKey, ok := plainKey(pkey).(ssh.CryptoPublicKey)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoAWSFingerprint, pkey.Type())
+	}
 	pub := parsedCryptoKey.CryptoPublicKey()
 	der, err := x509.MarshalPKIXPublicKey(pub)
 	if err != nil {
@@ -37,3 +37,38 @@ func TestFingerprintUnsupported(t *testing.T) {
 	_, err := ssh.GenerateAWSFingerprint([]byte(pk.Body))
 	require.ErrorContains(t, err, "x509: unsupported public key")
 }
+
+func TestOpenSSHFingerprints(t *testing.T) {
+	type test struct {
+		name    string
+		pubkey  *models.Pubkey
+		keyType string
+		sha256  string
+		md5     string
+	}
+
+	tests := []test{
+		{"ecdsa", factories.NewPubkeyECDSA(), "ecdsa-sha2-nistp256",
+			"i2SD7CQSFn/jesN7jfPEkMTxOQKatfdM3jy8Q92IC5c=", "a1:e4:56:47:d7:31:4d:09:05:58:fe:d5:77:4b:d2:a1"},
+		{"sk-ed25519", factories.NewPubkeySKED25519(), "sk-ssh-ed25519@openssh.com",
+			"/gWgX26zD+upPbyzQKTEWo4dzqMcHwOp4v/KTVQdYBU=", "99:3e:be:c9:4e:53:97:7a:5e:31:76:e0:34:2a:f9:ed"},
+		{"ed25519-cert", factories.NewPubkeyED25519Cert(), "ssh-ed25519-cert-v01@openssh.com",
+			"d+u9GGspoOa+iAKxAD2umBCMouHpmMByWlbeGSDIvNY=", "f3:00:7e:c2:dd:c2:5e:a9:ee:95:39:99:fa:2f:b0:38"},
+	}
+
+	for _, td := range tests {
+		t.Run(td.name, func(t *testing.T) {
+			fps, err := ssh.GenerateOpenSSHFingerprints([]byte(td.pubkey.Body))
+			require.NoError(t, err)
+			assert.Equal(t, td.keyType, fps.Type)
+			assert.Equal(t, td.sha256, fps.SHA256)
+			assert.Equal(t, td.md5, fps.MD5)
+		})
+	}
+}
+
+func TestFingerprintSecurityKey(t *testing.T) {
+	pk := factories.NewPubkeySKED25519()
+	_, err := ssh.GenerateAWSFingerprint([]byte(pk.Body))
+	require.ErrorIs(t, err, ssh.ErrNoAWSFingerprint)
+}
@@ -0,0 +1,178 @@
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// KeyType is a type of generated SSH key pair.
+type KeyType string
+
+const (
+	// KeyTypeED25519 is the default type of generated SSH key pairs.
+	KeyTypeED25519 KeyType = "ssh-ed25519"
+
+	// KeyTypeRSA is RSA key with RSABits length, useful for systems not supporting ED25519.
+	KeyTypeRSA KeyType = "ssh-rsa"
+)
+
+// RSABits is the length of generated RSA keys, the same as ssh-keygen default.
+const RSABits = 3072
+
+var (
+	ErrUnsupportedKeyType    = fmt.Errorf("unsupported key type, must be %s or %s", KeyTypeED25519, KeyTypeRSA)
+	ErrUnsupportedPrivateKey = errors.New("unsupported private key")
+)
+
+// KeyPair is a newly generated SSH key pair. Public key is in the authorized_keys (.pub) format,
+// private key is PEM encoded in the OpenSSH format as written by ssh-keygen.
+type KeyPair struct {
+	Type       KeyType
+	PublicKey  string
+	PrivateKey string
+}
+
+// GenerateKeyPair generates a new unencrypted SSH key pair of the given type. Empty type stands
+// for ED25519. The comment is stored in both public and private key.
+func GenerateKeyPair(keyType KeyType, comment string) (*KeyPair, error) {
+	var private any
+	var public any
+	switch keyType {
+	case "", KeyTypeED25519:
+		keyType = KeyTypeED25519
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate ed25519 key: %w", err)
+		}
+		private, public = priv, pub
+	case KeyTypeRSA:
+		priv, err := rsa.GenerateKey(rand.Reader, RSABits)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate rsa key: %w", err)
+		}
+		private, public = priv, &priv.PublicKey
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedKeyType, keyType)
+	}
+
+	sshPublic, err := ssh.NewPublicKey(public)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create public key: %w", err)
+	}
+
+	block, err := marshalOpenSSHPrivateKey(private, sshPublic, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizedKey := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(sshPublic)), "\n")
+	if comment != "" {
+		authorizedKey = authorizedKey + " " + comment
+	}
+
+	return &KeyPair{
+		Type:       keyType,
+		PublicKey:  authorizedKey,
+		PrivateKey: string(pem.EncodeToMemory(block)),
+	}, nil
+}
+
+const openSSHMagic = "openssh-key-v1\x00"
+
+type openSSHKey struct {
+	CipherName   string
+	KdfName      string
+	KdfOpts      string
+	NumKeys      uint32
+	PubKey       []byte
+	PrivKeyBlock []byte
+}
+
+type openSSHPrivateKey struct {
+	Check1  uint32
+	Check2  uint32
+	Keytype string
+	Rest    []byte `ssh:"rest"`
+}
+
+type openSSHED25519PrivateKey struct {
+	Pub     []byte
+	Priv    []byte
+	Comment string
+}
+
+type openSSHRSAPrivateKey struct {
+	N       *big.Int
+	E       *big.Int
+	D       *big.Int
+	Iqmp    *big.Int
+	P       *big.Int
+	Q       *big.Int
+	Comment string
+}
+
+// marshalOpenSSHPrivateKey encodes unencrypted private key in the "openssh-key-v1" format which
+// is not available in the x/crypto version in use.
+func marshalOpenSSHPrivateKey(key any, public ssh.PublicKey, comment string) (*pem.Block, error) {
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, fmt.Errorf("unable to generate check bytes: %w", err)
+	}
+	checkInt := binary.BigEndian.Uint32(check[:])
+
+	pk := openSSHPrivateKey{
+		Check1:  checkInt,
+		Check2:  checkInt,
+		Keytype: public.Type(),
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		pk.Rest = ssh.Marshal(openSSHED25519PrivateKey{
+			Pub:     k.Public().(ed25519.PublicKey),
+			Priv:    k,
+			Comment: comment,
+		})
+	case *rsa.PrivateKey:
+		k.Precompute()
+		pk.Rest = ssh.Marshal(openSSHRSAPrivateKey{
+			N:       k.N,
+			E:       big.NewInt(int64(k.E)),
+			D:       k.D,
+			Iqmp:    k.Precomputed.Qinv,
+			P:       k.Primes[0],
+			Q:       k.Primes[1],
+			Comment: comment,
+		})
+	default:
+		return nil, ErrUnsupportedPrivateKey
+	}
+
+	// unencrypted keys are padded to the block size of 8 bytes
+	block := ssh.Marshal(pk)
+	for i := 1; len(block)%8 != 0; i++ {
+		block = append(block, byte(i))
+	}
+
+	envelope := openSSHKey{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       public.Marshal(),
+		PrivKeyBlock: block,
+	}
+
+	return &pem.Block{
+		Type:  "OPENSSH PRIVATE KEY",
+		Bytes: append([]byte(openSSHMagic), ssh.Marshal(envelope)...),
+	}, nil
+}
@@ -0,0 +1,61 @@
+package ssh_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+func TestGenerateKeyPair(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType ssh.KeyType
+		result  ssh.KeyType
+	}{
+		{"default", "", ssh.KeyTypeED25519},
+		{"ed25519", ssh.KeyTypeED25519, ssh.KeyTypeED25519},
+		{"rsa", ssh.KeyTypeRSA, ssh.KeyTypeRSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kp, err := ssh.GenerateKeyPair(tt.keyType, "generated")
+			require.NoError(t, err)
+			assert.Equal(t, tt.result, kp.Type)
+
+			public, comment, _, _, err := cryptossh.ParseAuthorizedKey([]byte(kp.PublicKey))
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.result), public.Type())
+			assert.Equal(t, "generated", comment)
+
+			private, err := cryptossh.ParseRawPrivateKey([]byte(kp.PrivateKey))
+			require.NoError(t, err)
+			switch k := private.(type) {
+			case *ed25519.PrivateKey:
+				assert.Equal(t, ssh.KeyTypeED25519, tt.result)
+			case *rsa.PrivateKey:
+				require.NoError(t, k.Validate())
+				assert.Equal(t, ssh.RSABits, k.N.BitLen())
+			default:
+				t.Fatalf("unexpected private key type %T", private)
+			}
+
+			signer, err := cryptossh.NewSignerFromKey(private)
+			require.NoError(t, err)
+			assert.Equal(t, public.Marshal(), signer.PublicKey().Marshal(), "private key does not match public key")
+
+			_, err = ssh.GenerateOpenSSHFingerprints([]byte(kp.PublicKey))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGenerateKeyPairUnsupported(t *testing.T) {
+	_, err := ssh.GenerateKeyPair("ssh-dss", "")
+	require.ErrorIs(t, err, ssh.ErrUnsupportedKeyType)
+}
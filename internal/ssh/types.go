@@ -0,0 +1,66 @@
+package ssh
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// certificateSuffix is appended to the key type of OpenSSH certificates, security key types
+// are the exception as they also move the "@openssh.com" domain after the suffix.
+const certificateSuffix = "-cert-v01@openssh.com"
+
+// supportedKeyTypes are types of public keys which can be stored. Certificates of these key
+// types are supported too. DSA keys are deprecated and not supported.
+var supportedKeyTypes = []string{
+	ssh.KeyAlgoRSA,
+	ssh.KeyAlgoED25519,
+	ssh.KeyAlgoECDSA256,
+	ssh.KeyAlgoECDSA384,
+	ssh.KeyAlgoECDSA521,
+	ssh.KeyAlgoSKED25519,
+	ssh.KeyAlgoSKECDSA256,
+}
+
+// IsCertificateType returns true for OpenSSH certificate types, e.g. "ssh-ed25519-cert-v01@openssh.com".
+func IsCertificateType(keyType string) bool {
+	return strings.HasSuffix(keyType, certificateSuffix)
+}
+
+// IsSecurityKeyType returns true for hardware-backed (FIDO) key types and their certificates.
+func IsSecurityKeyType(keyType string) bool {
+	return strings.HasPrefix(keyType, "sk-")
+}
+
+// BaseKeyType returns type of the certified key for certificate types, other types are returned
+// unchanged. For example "sk-ssh-ed25519-cert-v01@openssh.com" becomes "sk-ssh-ed25519@openssh.com".
+func BaseKeyType(keyType string) string {
+	if !IsCertificateType(keyType) {
+		return keyType
+	}
+
+	base := strings.TrimSuffix(keyType, certificateSuffix)
+	if IsSecurityKeyType(base) {
+		base = base + "@openssh.com"
+	}
+	return base
+}
+
+// IsSupportedKeyType returns true when public keys or certificates of the type can be stored.
+func IsSupportedKeyType(keyType string) bool {
+	base := BaseKeyType(keyType)
+	for _, t := range supportedKeyTypes {
+		if t == base {
+			return true
+		}
+	}
+	return false
+}
+
+// plainKey returns the certified key for certificates, other keys are returned unchanged.
+func plainKey(pkey ssh.PublicKey) ssh.PublicKey {
+	if cert, ok := pkey.(*ssh.Certificate); ok {
+		return cert.Key
+	}
+	return pkey
+}
@@ -0,0 +1,35 @@
+package ssh_test
+
+import (
+	"testing"
+
+	"github.com/RHEnVision/provisioning-backend/internal/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseKeyType(t *testing.T) {
+	assert.Equal(t, "ssh-rsa", ssh.BaseKeyType("ssh-rsa"))
+	assert.Equal(t, "ssh-ed25519", ssh.BaseKeyType("ssh-ed25519-cert-v01@openssh.com"))
+	assert.Equal(t, "ecdsa-sha2-nistp384", ssh.BaseKeyType("ecdsa-sha2-nistp384-cert-v01@openssh.com"))
+	assert.Equal(t, "sk-ssh-ed25519@openssh.com", ssh.BaseKeyType("sk-ssh-ed25519-cert-v01@openssh.com"))
+}
+
+func TestIsSupportedKeyType(t *testing.T) {
+	for _, keyType := range []string{
+		"ssh-rsa",
+		"ssh-ed25519",
+		"ecdsa-sha2-nistp256",
+		"ecdsa-sha2-nistp384",
+		"ecdsa-sha2-nistp521",
+		"sk-ssh-ed25519@openssh.com",
+		"sk-ecdsa-sha2-nistp256@openssh.com",
+		"ssh-rsa-cert-v01@openssh.com",
+		"sk-ssh-ed25519-cert-v01@openssh.com",
+	} {
+		assert.True(t, ssh.IsSupportedKeyType(keyType), keyType)
+	}
+
+	for _, keyType := range []string{"", "ssh-dss", "ssh-dss-cert-v01@openssh.com", "test"} {
+		assert.False(t, ssh.IsSupportedKeyType(keyType), keyType)
+	}
+}
@@ -94,6 +94,31 @@ func NewPubkeyDSS() *models.Pubkey {
 	}
 }
 
+// NewPubkeySKED25519 returns a hardware-backed (FIDO) ED25519 key
+func NewPubkeySKED25519() *models.Pubkey {
+	return &models.Pubkey{
+		AccountID: 1,
+		Name:      SeqNameWithPrefix("lzap-yubikey-2023"),
+		Body: "sk-ssh-ed25519@openssh.com AAAAGnNrLXNzaC1lZDI1NTE5QG9wZW5zc2guY29tAAAAIEhnn80ZywmjeBFFOGm+" +
+			"cm+5HUwm62qTVnjKlOdYFLHNAAAABHNzaDo= lzap-yubikey-2023",
+	}
+}
+
+// NewPubkeyED25519Cert returns an ED25519 key signed by a certificate authority
+func NewPubkeyED25519Cert() *models.Pubkey {
+	return &models.Pubkey{
+		AccountID: 1,
+		Name:      SeqNameWithPrefix("user-cert-2026"),
+		Body: "ssh-ed25519-cert-v01@openssh.com AAAAIHNzaC1lZDI1NTE5LWNlcnQtdjAxQG9wZW5zc2guY29tAAAAIGs9MnHN" +
+			"ur7ItA6U17+L440aFvPleXYOQXEouD0KEwZMAAAAIAONLkPdfXLEntAqmLSRs9F+DzreG+Ac3hNs9MSRzD+rAAAAAAAAAAAAAAABAAAA" +
+			"EHVzZXJAZXhhbXBsZS5jb20AAAAOAAAACmNsb3VkLXVzZXIAAAAAaVW5AAAAAAB8JF8AAAAAAAAAAIIAAAAVcGVybWl0LVgxMS1mb3J3" +
+			"YXJkaW5nAAAAAAAAABdwZXJtaXQtYWdlbnQtZm9yd2FyZGluZwAAAAAAAAAWcGVybWl0LXBvcnQtZm9yd2FyZGluZwAAAAAAAAAKcGVy" +
+			"bWl0LXB0eQAAAAAAAAAOcGVybWl0LXVzZXItcmMAAAAAAAAAAAAAADMAAAALc3NoLWVkMjU1MTkAAAAgTBkpYcqB1DI4aG9cVRg1+men" +
+			"EfgJOrVh3VQYV30eIcIAAABTAAAAC3NzaC1lZDI1NTE5AAAAQAoWAovOZz/K//5GlmHWAY/XOvlQzqAIJgewUZ7hyPO94LR19MA1M/9G" +
+			"agNIuEtc+G9dt26+XKC0hVhUx0u+Wg8= user",
+	}
+}
+
 func NewPubkeyECDSA() *models.Pubkey {
 	return &models.Pubkey{
 		AccountID: 1,
@@ -0,0 +1,158 @@
+package userdata
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"path"
+	"strings"
+
+	"github.com/RHEnVision/provisioning-backend/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// MaxCustomSize is the maximum size of custom user data in bytes. AWS has the lowest limit
+// of 16 kB, some space is reserved for the generated user data.
+const MaxCustomSize = 12 * 1024
+
+// maxSize is the maximum size of the final user data for each provider in bytes (before base64 encoding).
+var maxSize = map[models.ProviderType]int{
+	models.ProviderTypeAWS:   16 * 1024,
+	models.ProviderTypeAzure: 64 * 1024 * 3 / 4,
+	models.ProviderTypeGCP:   256 * 1024,
+}
+
+const (
+	cloudConfigHeader = "#cloud-config"
+	shebang           = "#!"
+
+	// cloud-init merges lists (e.g. write_files or runcmd) of both parts instead of replacing them
+	mergeType = "list(append)+dict(no_replace,recurse_list)+str()"
+)
+
+var (
+	ErrCustomTooLarge     = fmt.Errorf("custom user data must not be larger than %d bytes", MaxCustomSize)
+	ErrUserDataTooLarge   = errors.New("user data exceeds the provider limit")
+	ErrInvalidCloudConfig = errors.New("custom user data is not a valid cloud-config YAML document")
+	ErrCustomNotScript    = errors.New("custom user data must be a shell script starting with #! for GCP")
+	ErrCustomNotShell     = errors.New("custom user data script must be interpreted by sh or bash for GCP")
+)
+
+// isScript returns true when custom user data is a shell script.
+func isScript(custom string) bool {
+	return strings.HasPrefix(custom, shebang)
+}
+
+// isShellScript returns true when custom user data is a script interpreted by sh or bash,
+// either directly or via env.
+func isShellScript(custom string) bool {
+	line, _, _ := strings.Cut(strings.TrimPrefix(custom, shebang), "\n")
+	fields := strings.Fields(line)
+	if len(fields) > 1 && path.Base(fields[0]) == "env" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	interpreter := path.Base(fields[0])
+	return interpreter == "sh" || interpreter == "bash"
+}
+
+// ValidateCustom checks size and format of custom user data. For AWS and Azure, it must be either
+// a cloud-config YAML mapping or a shell script. For GCP, only sh and bash scripts are supported
+// because their body is appended to the bash startup script. Empty custom user data is valid.
+func ValidateCustom(provider models.ProviderType, custom string) error {
+	if custom == "" {
+		return nil
+	}
+
+	if len(custom) > MaxCustomSize {
+		return ErrCustomTooLarge
+	}
+
+	if provider == models.ProviderTypeGCP {
+		if !isScript(custom) {
+			return ErrCustomNotScript
+		}
+		if !isShellScript(custom) {
+			return ErrCustomNotShell
+		}
+		return nil
+	}
+
+	if isScript(custom) {
+		return nil
+	}
+
+	var document map[string]any
+	if err := yaml.Unmarshal([]byte(custom), &document); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidCloudConfig, err.Error())
+	}
+	if document == nil {
+		return ErrInvalidCloudConfig
+	}
+
+	return nil
+}
+
+// mergeMultipart returns generated cloud-config and custom user data as a multipart MIME document
+// which is processed by cloud-init.
+func mergeMultipart(generated []byte, custom string) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+
+	fmt.Fprintf(&buffer, "Content-Type: multipart/mixed; boundary=\"%s\"\r\nMIME-Version: 1.0\r\n\r\n", writer.Boundary())
+
+	parts := []struct {
+		contentType string
+		filename    string
+		content     string
+	}{
+		{"text/cloud-config", "provisioning.yaml", string(generated)},
+		{"text/cloud-config", "user-data.yaml", custom},
+	}
+	if isScript(custom) {
+		parts[1].contentType = "text/x-shellscript"
+		parts[1].filename = "user-data.sh"
+	} else if !strings.HasPrefix(custom, cloudConfigHeader) {
+		parts[1].content = cloudConfigHeader + "\n" + custom
+	}
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType+"; charset=\"utf-8\"")
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "8bit")
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", part.filename))
+		if part.contentType == "text/cloud-config" {
+			header.Set("Merge-Type", mergeType)
+		}
+
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create user data part: %w", err)
+		}
+		if _, err = w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("cannot write user data part: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("cannot close user data: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// scriptBody returns custom shell script without the shebang line, so it can be appended to the
+// generated bash script. Only sh and bash scripts are accepted for GCP, see ValidateCustom.
+func scriptBody(custom string) string {
+	if !isScript(custom) {
+		return custom
+	}
+	if idx := strings.IndexByte(custom, '\n'); idx >= 0 {
+		return custom[idx+1:]
+	}
+	return ""
+}
@@ -33,6 +33,16 @@ type UserData struct {
 
 	// InsightsTags renders a first-boot script which populates /etc/insights-client/tags.yaml
 	InsightsTags bool
+
+	// Custom user data provided by the user. For AWS and Azure, it is merged with the generated
+	// cloud-config as a multipart MIME document, for GCP it is appended to the startup script.
+	// See ValidateCustom for supported formats.
+	Custom string
+}
+
+// CustomScript returns custom shell script body to be appended to the GCP startup script.
+func (ud UserData) CustomScript() string {
+	return scriptBody(ud.Custom)
 }
 
 func (ud UserData) IsAWS() bool {
@@ -90,6 +100,17 @@ func GenerateUserData(ctx context.Context, userData *UserData) ([]byte, error) {
 	}
 
 	udBytes := buffer.Bytes()
+	if userData.Custom != "" && userData.Type != models.ProviderTypeGCP {
+		udBytes, err = mergeMultipart(udBytes, userData.Custom)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if limit, ok := maxSize[userData.Type]; ok && len(udBytes) > limit {
+		return nil, fmt.Errorf("%w: %d bytes, maximum is %d bytes", ErrUserDataTooLarge, len(udBytes), limit)
+	}
+
 	logger.Trace().Bytes("payload", udBytes).Msg("Generated userdata")
 	return udBytes, nil
 }
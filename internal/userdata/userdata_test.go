@@ -1,8 +1,14 @@
 package userdata
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
 	"regexp"
 	"strings"
 	"testing"
@@ -141,3 +147,108 @@ power_state:
 	assert.NoError(t, validateYAML(userData))
 	assert.Equal(t, expected, strings.Trim(trimRe.ReplaceAllString(string(userData), "\n"), "\n"))
 }
+
+func TestGenerateCustomCloudConfig(t *testing.T) {
+	userDataInput := UserData{
+		Type:     models.ProviderTypeAWS,
+		PowerOff: true,
+		Custom:   "packages:\n- tmux\n",
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(userData))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var contents []string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(part.Header.Get("Content-Type"), "text/cloud-config"))
+		assert.NotEmpty(t, part.Header.Get("Merge-Type"))
+
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.NoError(t, validateYAML(content))
+		contents = append(contents, string(content))
+	}
+
+	require.Len(t, contents, 2)
+	assert.Contains(t, contents[0], "power_state:")
+	assert.Equal(t, "#cloud-config\npackages:\n- tmux\n", contents[1])
+}
+
+func TestGenerateCustomShellScript(t *testing.T) {
+	userDataInput := UserData{
+		Type:   models.ProviderTypeAzure,
+		Custom: "#!/bin/sh\necho hello\n",
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(userData), "Content-Type: text/x-shellscript")
+	assert.Contains(t, string(userData), "#!/bin/sh\necho hello\n")
+}
+
+func TestGenerateGCPCustomScript(t *testing.T) {
+	userDataInput := UserData{
+		Type:   models.ProviderTypeGCP,
+		Custom: "#!/bin/bash\ndnf -y install tmux",
+	}
+	userData, err := GenerateUserData(context.Background(), &userDataInput)
+	require.NoError(t, err)
+	expected := `#! /bin/bash
+dnf -y install tmux
+exit 0`
+
+	assert.Equal(t, expected, strings.Trim(trimRe.ReplaceAllString(string(userData), "\n"), "\n"))
+}
+
+func TestGenerateTooLarge(t *testing.T) {
+	userDataInput := UserData{
+		Type:   models.ProviderTypeAWS,
+		Custom: "#!/bin/sh\n" + strings.Repeat("#", 16*1024),
+	}
+	_, err := GenerateUserData(context.Background(), &userDataInput)
+	require.ErrorIs(t, err, ErrUserDataTooLarge)
+}
+
+func TestValidateCustom(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider models.ProviderType
+		custom   string
+		err      error
+	}{
+		{"empty", models.ProviderTypeAWS, "", nil},
+		{"cloud-config", models.ProviderTypeAWS, "#cloud-config\npackages:\n- tmux\n", nil},
+		{"script", models.ProviderTypeAzure, "#!/bin/sh\necho hello\n", nil},
+		{"GCP script", models.ProviderTypeGCP, "#!/bin/sh\necho hello\n", nil},
+		{"invalid YAML", models.ProviderTypeAWS, "packages: [tmux\n", ErrInvalidCloudConfig},
+		{"not a mapping", models.ProviderTypeAWS, "- tmux\n", ErrInvalidCloudConfig},
+		{"only comment", models.ProviderTypeAzure, "#cloud-config\n", ErrInvalidCloudConfig},
+		{"GCP cloud-config", models.ProviderTypeGCP, "#cloud-config\npackages:\n- tmux\n", ErrCustomNotScript},
+		{"GCP env bash", models.ProviderTypeGCP, "#!/usr/bin/env bash\necho hello\n", nil},
+		{"GCP python", models.ProviderTypeGCP, "#!/usr/bin/python3\nprint('hello')\n", ErrCustomNotShell},
+		{"GCP env python", models.ProviderTypeGCP, "#!/usr/bin/env python3\nprint('hello')\n", ErrCustomNotShell},
+		{"python", models.ProviderTypeAWS, "#!/usr/bin/python3\nprint('hello')\n", nil},
+		{"too large", models.ProviderTypeAWS, "#!/bin/sh\n" + strings.Repeat("#", MaxCustomSize), ErrCustomTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCustom(tt.provider, tt.custom)
+			if tt.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -1,13 +1,22 @@
 package worker
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
 
+	"github.com/RHEnVision/provisioning-backend/internal/config"
 	"github.com/RHEnVision/provisioning-backend/internal/identity"
 	"github.com/RHEnVision/provisioning-backend/internal/logging"
+	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog"
 )
 
@@ -18,7 +27,9 @@ func init() {
 
 type JobType string
 
-type JobHandler func(ctx context.Context, job *Job)
+// JobHandler processes a job. Returned error marks the job as failed, failed jobs are retried
+// according to the retry policy of the job type and then moved into the dead-letter list.
+type JobHandler func(ctx context.Context, job *Job) error
 
 type Job struct {
 	// Random UUID for logging and tracing. It is generated randomly by Enqueue function when blank.
@@ -41,21 +52,71 @@ type Job struct {
 
 	// Job arguments.
 	Args any
+
+	// Number of failed attempts to process the job. Only incremented by workers which support retries.
+	Attempt int
+}
+
+// RetryPolicy configures how many times a failed job is processed and how long to wait before
+// each retry. Job is considered failed when its handler returns an error or panics.
+type RetryPolicy struct {
+	// Total number of attempts including the first one, values lower than 2 mean no retries.
+	MaxAttempts int
+
+	// Delay before the first retry, it doubles with every further attempt.
+	Backoff time.Duration
+}
+
+// DefaultRetryPolicy is used for job types without explicit policy: jobs are never retried.
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// Delay returns backoff duration for the given attempt (starting from 1).
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	return p.Backoff * time.Duration(1<<(attempt-1))
 }
 
 var ErrHandlerNotFound = errors.New("handler not registered")
 
+type lastAttemptCtxKeyType int
+
+const lastAttemptCtxKey lastAttemptCtxKeyType = iota
+
+func withLastAttempt(ctx context.Context, last bool) context.Context {
+	return context.WithValue(ctx, lastAttemptCtxKey, last)
+}
+
+// IsLastAttempt returns false when a failed job is going to be retried, handlers use it to decide
+// whether to store the failure or leave it for the next attempt. Returns true when called outside
+// of a worker.
+func IsLastAttempt(ctx context.Context) bool {
+	last, ok := ctx.Value(lastAttemptCtxKey).(bool)
+	return !ok || last
+}
+
 // JobEnqueuer sends Job messages into worker queue.
 type JobEnqueuer interface {
 	// Enqueue delivers a job to one of the backend workers.
 	Enqueue(context.Context, *Job) error
 }
 
+// TxEnqueuer sends Job messages into worker queue within a database transaction. The job is only
+// delivered when the transaction is committed.
+type TxEnqueuer interface {
+	// EnqueueTx delivers a job to one of the backend workers within the transaction.
+	EnqueueTx(context.Context, pgx.Tx, *Job) error
+}
+
 // JobWorker receives and handles Job messages.
 type JobWorker interface {
 	// RegisterHandler registers an event listener for a particular type with an associated handler.
 	RegisterHandler(JobType, JobHandler, any)
 
+	// SetRetryPolicy sets retry policy for a particular type, workers without retry support ignore it.
+	SetRetryPolicy(JobType, RetryPolicy)
+
 	// DequeueLoop starts one or more goroutines to dispatch incoming jobs.
 	DequeueLoop(ctx context.Context)
 
@@ -66,6 +127,16 @@ type JobWorker interface {
 	Stats(ctx context.Context) (Stats, error)
 }
 
+// DeadLetterQueue provides access to jobs which failed after all retry attempts.
+type DeadLetterQueue interface {
+	// DeadLetters returns all jobs from the dead-letter list.
+	DeadLetters(ctx context.Context) ([]*Job, error)
+
+	// Redrive moves a job with the given ID from the dead-letter list back into the queue and resets
+	// its attempt counter. All jobs are moved when the ID is uuid.Nil. Returns number of moved jobs.
+	Redrive(ctx context.Context, id uuid.UUID) (int, error)
+}
+
 func (jt JobType) String() string {
 	return string(jt)
 }
@@ -77,6 +148,12 @@ type Stats struct {
 
 	// Number of jobs currently being processed. Local value - each client has its own number.
 	InFlight int64
+
+	// Number of failed jobs waiting for a retry. This is a global value.
+	DelayedJobs uint64
+
+	// Number of jobs in the dead-letter list. This is a global value.
+	DeadJobs uint64
 }
 
 func contextLogger(origCtx context.Context, job *Job) (context.Context, *zerolog.Logger) {
@@ -106,3 +183,63 @@ func contextLogger(origCtx context.Context, job *Job) (context.Context, *zerolog
 		Logger())
 	return logger.WithContext(ctx), logger
 }
+
+func encodeJob(job *Job) ([]byte, error) {
+	var buffer bytes.Buffer
+	enc := gob.NewEncoder(&buffer)
+	err := enc.Encode(&job)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode args: %w", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+func decodeJob(payload string) (*Job, error) {
+	var job Job
+	dec := gob.NewDecoder(strings.NewReader(payload))
+	err := dec.Decode(&job)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode job: %w", err)
+	}
+	return &job, nil
+}
+
+// runHandler calls the job handler with timeout and returns false when the handler returned an error
+// or panicked. Jobs without registered handler are logged and considered successful.
+func runHandler(origCtx context.Context, handlers map[JobType]JobHandler, job *Job, policy RetryPolicy, backend string) (success bool) {
+	ctx, logger := contextLogger(origCtx, job)
+	defer func() {
+		if rec := recover(); rec != nil {
+			success = false
+			zerolog.Ctx(ctx).Error().
+				Bool("panic", true).
+				Bytes("stacktrace", debug.Stack()).
+				Msgf("Unhandled panic in worker: %s", rec)
+		}
+	}()
+	logger.Info().Int("attempt", job.Attempt).Msgf("Dequeued job %s %s from %s", job.Type.String(), job.ID, backend)
+
+	h, ok := handlers[job.Type]
+	if !ok {
+		// handler not found
+		zerolog.Ctx(ctx).Warn().Msgf("%s worker handler not found for job type: %s", backend, job.Type)
+		return true
+	}
+
+	cCtx, cFunc := context.WithTimeout(withLastAttempt(ctx, job.Attempt+1 >= policy.MaxAttempts), config.Worker.Timeout)
+	defer func() {
+		if c := cCtx.Err(); c != nil {
+			zerolog.Ctx(ctx).Error().Err(c).Msg("Job was either cancelled or timeout occurred")
+		}
+		cFunc()
+	}()
+	var err error
+	metrics.ObserveBackgroundJobDuration(job.Type.String(), func() {
+		err = h(cCtx, job)
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Int("attempt", job.Attempt).Msgf("Job %s failed", job.ID)
+		return false
+	}
+	return true
+}
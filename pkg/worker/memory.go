@@ -25,6 +25,10 @@ func (w *MemoryWorker) RegisterHandler(jtype JobType, handler JobHandler, _ any)
 	w.handlers[jtype] = handler
 }
 
+// SetRetryPolicy does nothing, memory worker does not support retries.
+func (w *MemoryWorker) SetRetryPolicy(_ JobType, _ RetryPolicy) {
+}
+
 func (w *MemoryWorker) Enqueue(ctx context.Context, job *Job) error {
 	var err error
 	if job == nil {
@@ -67,7 +71,9 @@ func (w *MemoryWorker) processJob(ctx context.Context, job *Job) {
 		ctx, _ = contextLogger(ctx, job)
 		cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
 		defer cFunc()
-		h(cCtx, job)
+		if err := h(cCtx, job); err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msgf("Job %s failed, memory worker does not support retries", job.ID)
+		}
 	} else {
 		zerolog.Ctx(ctx).Warn().Msgf("Memory worker handler not found for job type: %s", job.Type)
 	}
@@ -0,0 +1,362 @@
+package worker
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RHEnVision/provisioning-backend/internal/config"
+	"github.com/RHEnVision/provisioning-backend/internal/ptr"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+)
+
+var ErrPoolNotInitialized = errors.New("database connection pool not initialized")
+
+// execer is implemented by both pgxpool.Pool and pgx.Tx
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// PostgresWorker keeps all jobs in the job_queue table. Jobs are fetched via SELECT FOR UPDATE
+// SKIP LOCKED and each job in progress holds a lease (locked_until column), jobs with expired
+// lease (e.g. the worker crashed) are fetched again. Failed jobs are retried according to the
+// retry policy of the job type and then marked as dead. Jobs can be enqueued in the same
+// transaction as other database changes via EnqueueTx.
+type PostgresWorker struct {
+	// connection pool - safe for concurrent use
+	pool *pgxpool.Pool
+
+	// handler functions
+	handlers map[JobType]JobHandler
+
+	// retry policies
+	policies map[JobType]RetryPolicy
+
+	// close channel
+	closeCh chan interface{}
+
+	// polling and wait groups
+	pollInterval time.Duration
+	concurrency  int
+	loopWG       sync.WaitGroup
+
+	// number of in-flight jobs (must be used via atomic functions)
+	inFlight int64
+}
+
+var (
+	_ JobWorker       = &PostgresWorker{}
+	_ TxEnqueuer      = &PostgresWorker{}
+	_ DeadLetterQueue = &PostgresWorker{}
+)
+
+// NewPostgresWorker creates new worker that keeps all jobs in the job_queue table, starts N polling
+// goroutines which fetch jobs from the table and process them in the same goroutine. The database
+// connection pool must be initialized.
+func NewPostgresWorker(pool *pgxpool.Pool, pollInterval time.Duration, concurrency int) (*PostgresWorker, error) {
+	if pool == nil {
+		return nil, ErrPoolNotInitialized
+	}
+
+	return &PostgresWorker{
+		pool:         pool,
+		handlers:     make(map[JobType]JobHandler),
+		policies:     make(map[JobType]RetryPolicy),
+		pollInterval: pollInterval,
+		concurrency:  concurrency,
+		closeCh:      make(chan interface{}),
+	}, nil
+}
+
+func (w *PostgresWorker) RegisterHandler(jtype JobType, handler JobHandler, args any) {
+	w.handlers[jtype] = handler
+	gob.Register(args)
+}
+
+func (w *PostgresWorker) SetRetryPolicy(jtype JobType, policy RetryPolicy) {
+	w.policies[jtype] = policy
+}
+
+func (w *PostgresWorker) retryPolicy(jtype JobType) RetryPolicy {
+	if policy, ok := w.policies[jtype]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
+func (w *PostgresWorker) Enqueue(ctx context.Context, job *Job) error {
+	return w.enqueue(ctx, w.pool, job)
+}
+
+// EnqueueTx inserts the job within the transaction, the job is only visible to workers once the
+// transaction is committed.
+func (w *PostgresWorker) EnqueueTx(ctx context.Context, tx pgx.Tx, job *Job) error {
+	return w.enqueue(ctx, tx, job)
+}
+
+func (w *PostgresWorker) enqueue(ctx context.Context, db execer, job *Job) error {
+	var err error
+	if job == nil {
+		return fmt.Errorf("unable to enqueue job: %w", ErrJobNotFound)
+	}
+
+	if job.ID == uuid.Nil {
+		job.ID, err = uuid.NewRandom()
+		if err != nil {
+			return fmt.Errorf("unable to generate UUID: %w", err)
+		}
+	}
+
+	logger := ptr.To(zerolog.Ctx(ctx).With().
+		Str("job_id", job.ID.String()).
+		Str("job_type", job.Type.String()).
+		Interface("job_args", job.Args).
+		Logger())
+	logger.Info().Msgf("Enqueuing job type %s via Postgres", job.Type)
+
+	payload, err := encodeJob(job)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO job_queue (id, type, payload, attempt) VALUES ($1, $2, $3, $4)`
+	_, err = db.Exec(ctx, query, job.ID, job.Type.String(), payload, job.Attempt)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to insert job into Postgres")
+		return fmt.Errorf("unable to insert job into Postgres: %w", err)
+	}
+
+	logger.Info().Msg("Inserted job successfully")
+	return nil
+}
+
+func (w *PostgresWorker) Stop(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	close(w.closeCh)
+	logger.Info().Msg("Waiting for all workers to finish")
+	w.loopWG.Wait()
+	logger.Info().Msg("Done waiting for all workers to finish")
+}
+
+func (w *PostgresWorker) DequeueLoop(ctx context.Context) {
+	logger := zerolog.Ctx(ctx)
+	logger.Info().Msgf("Starting Postgres dequeuer with %d polling goroutines", w.concurrency)
+	for i := 1; i <= w.concurrency; i++ {
+		w.loopWG.Add(1)
+		go w.dequeueLoop(ctx, i, w.concurrency)
+	}
+}
+
+func (w *PostgresWorker) dequeueLoop(ctx context.Context, i, total int) {
+	defer w.loopWG.Done()
+	logger := zerolog.Ctx(ctx)
+
+	// do not crash the program on fatal errors
+	debug.SetPanicOnFault(true)
+
+	// spread polling intervals
+	delayMs := (int(w.pollInterval.Milliseconds()) / total) * (i - 1)
+	logger.Debug().Msgf("Worker start delay %dms", delayMs)
+	time.Sleep(time.Duration(delayMs) * time.Millisecond)
+
+	for {
+		select {
+		case <-w.closeCh:
+			logger.Info().Msg("Shutting down a Postgres poller (stop)")
+			return
+		case <-ctx.Done():
+			logger.Info().Msg("Shutting down a Postgres poller (cancel)")
+			return
+		default:
+			if !w.fetchJob(ctx) {
+				w.sleep(ctx)
+			}
+		}
+	}
+}
+
+// sleep waits for the poll interval unless the worker is stopped
+func (w *PostgresWorker) sleep(ctx context.Context) {
+	timer := time.NewTimer(w.pollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-w.closeCh:
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// fetchJob takes a lease of one job which is ready to run and processes it. Returns false when
+// there was no job in the queue or when an error occurred.
+func (w *PostgresWorker) fetchJob(ctx context.Context) (found bool) {
+	defer recoverAndLog(ctx)
+	logger := zerolog.Ctx(ctx)
+
+	query := `UPDATE job_queue
+		SET locked_until = (now() AT TIME ZONE 'UTC') + make_interval(secs => $1), attempt = attempt + 1
+		WHERE id = (
+			SELECT id FROM job_queue
+			WHERE NOT dead AND run_at <= (now() AT TIME ZONE 'UTC')
+				AND (locked_until IS NULL OR locked_until < (now() AT TIME ZONE 'UTC'))
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED)
+		RETURNING id, payload, attempt`
+	lease := (config.Worker.Timeout + leaseMargin).Seconds()
+
+	var id uuid.UUID
+	var payload []byte
+	var attempt int
+	err := w.pool.QueryRow(ctx, query, lease).Scan(&id, &payload, &attempt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false
+	} else if err != nil {
+		logger.Error().Err(err).Msg("Error consuming from Postgres queue")
+		return false
+	}
+
+	job, err := decodeJob(string(payload))
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to unmarshal job payload, marking as dead")
+		w.bury(ctx, id)
+		return true
+	}
+	// attempt column counts started attempts while the job field counts failed attempts
+	job.Attempt = attempt - 1
+
+	if attempt > w.retryPolicy(job.Type).MaxAttempts {
+		// the lease expired (e.g. worker crashed) after the last attempt
+		logger.Warn().Str("job_id", job.ID.String()).Msgf("Job %s has no attempts left, marking as dead", job.ID)
+		w.bury(ctx, id)
+		return true
+	}
+
+	atomic.AddInt64(&w.inFlight, 1)
+	if w.processJob(ctx, job) {
+		w.ack(ctx, job)
+	} else {
+		w.fail(ctx, job)
+	}
+	return true
+}
+
+// ack deletes a processed job.
+func (w *PostgresWorker) ack(ctx context.Context, job *Job) {
+	_, err := w.pool.Exec(ctx, `DELETE FROM job_queue WHERE id = $1`, job.ID)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Unable to acknowledge Postgres job")
+	}
+}
+
+// fail releases the lease of a failed job and schedules a retry or marks the job as dead when there
+// are no attempts left.
+func (w *PostgresWorker) fail(ctx context.Context, job *Job) {
+	logger := zerolog.Ctx(ctx).With().Str("job_id", job.ID.String()).Logger()
+	policy := w.retryPolicy(job.Type)
+	job.Attempt++
+
+	if job.Attempt >= policy.MaxAttempts {
+		logger.Warn().Int("attempt", job.Attempt).Msgf("Job %s failed %d time(s), marking as dead", job.ID, job.Attempt)
+		w.bury(ctx, job.ID)
+		return
+	}
+
+	delay := policy.Delay(job.Attempt)
+	query := `UPDATE job_queue
+		SET locked_until = NULL, run_at = (now() AT TIME ZONE 'UTC') + make_interval(secs => $2)
+		WHERE id = $1`
+	_, err := w.pool.Exec(ctx, query, job.ID, delay.Seconds())
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to schedule Postgres job retry")
+		return
+	}
+	logger.Warn().Int("attempt", job.Attempt).Msgf("Job %s failed, retrying in %s", job.ID, delay.String())
+}
+
+// bury marks a job as dead.
+func (w *PostgresWorker) bury(ctx context.Context, id uuid.UUID) {
+	_, err := w.pool.Exec(ctx, `UPDATE job_queue SET locked_until = NULL, dead = TRUE WHERE id = $1`, id)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", id.String()).Msg("Unable to mark Postgres job as dead")
+	}
+}
+
+// processJob calls the job handler and returns false when the handler returned an error or panicked.
+func (w *PostgresWorker) processJob(ctx context.Context, job *Job) bool {
+	defer atomic.AddInt64(&w.inFlight, -1)
+
+	return runHandler(ctx, w.handlers, job, w.retryPolicy(job.Type), "Postgres")
+}
+
+// DeadLetters returns all dead jobs, payloads which cannot be decoded are skipped.
+func (w *PostgresWorker) DeadLetters(ctx context.Context) ([]*Job, error) {
+	query := `SELECT payload, attempt FROM job_queue WHERE dead ORDER BY enqueued_at`
+	rows, err := w.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list dead-letter jobs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]*Job, 0)
+	for rows.Next() {
+		var payload []byte
+		var attempt int
+		if err := rows.Scan(&payload, &attempt); err != nil {
+			return nil, fmt.Errorf("unable to scan dead-letter job: %w", err)
+		}
+
+		job, err := decodeJob(string(payload))
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to unmarshal dead-letter job payload, skipping")
+			continue
+		}
+		job.Attempt = attempt
+		result = append(result, job)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("unable to list dead-letter jobs: %w", rows.Err())
+	}
+	return result, nil
+}
+
+func (w *PostgresWorker) Redrive(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `UPDATE job_queue
+		SET dead = FALSE, attempt = 0, locked_until = NULL, run_at = (now() AT TIME ZONE 'UTC')
+		WHERE dead AND ($1 OR id = $2)`
+	tag, err := w.pool.Exec(ctx, query, id == uuid.Nil, id)
+	if err != nil {
+		return 0, fmt.Errorf("unable to redrive dead-letter jobs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (w *PostgresWorker) Stats(ctx context.Context) (Stats, error) {
+	query := `SELECT
+			COUNT(*) FILTER (WHERE NOT dead AND run_at <= (now() AT TIME ZONE 'UTC') AND locked_until IS NULL),
+			COUNT(*) FILTER (WHERE NOT dead AND run_at > (now() AT TIME ZONE 'UTC')),
+			COUNT(*) FILTER (WHERE dead)
+		FROM job_queue`
+
+	var enqueued, delayed, dead int64
+	err := w.pool.QueryRow(ctx, query).Scan(&enqueued, &delayed, &dead)
+	if err != nil {
+		return Stats{}, fmt.Errorf("unable to get queue stats: %w", err)
+	}
+
+	return Stats{
+		EnqueuedJobs: uint64(enqueued),
+		InFlight:     atomic.LoadInt64(&w.inFlight),
+		DelayedJobs:  uint64(delayed),
+		DeadJobs:     uint64(dead),
+	}, nil
+}
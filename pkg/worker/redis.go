@@ -1,25 +1,73 @@
 package worker
 
 import (
-	"bytes"
 	"context"
 	"encoding/gob"
 	"errors"
 	"fmt"
 	"runtime/debug"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/RHEnVision/provisioning-backend/internal/config"
-	"github.com/RHEnVision/provisioning-backend/internal/metrics"
 	"github.com/RHEnVision/provisioning-backend/internal/ptr"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 )
 
+// leaseMargin is added to the job timeout, jobs in the processing list with expired lease are
+// considered lost (e.g. worker crashed) and are retried.
+const leaseMargin = time.Minute
+
+// failScript atomically removes a job from the processing list and puts it either into the delayed
+// set (retry) or the dead-letter list. Returns 0 when the job was already removed by someone else.
+//
+// KEYS: processing list, delayed set, dead-letter list, leases hash
+// ARGV: original payload, new payload, job id, retry time (unix ms) or -1 for the dead-letter list
+var failScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+  return 0
+end
+redis.call('HDEL', KEYS[4], ARGV[3])
+if tonumber(ARGV[4]) < 0 then
+  redis.call('LPUSH', KEYS[3], ARGV[2])
+else
+  redis.call('ZADD', KEYS[2], ARGV[4], ARGV[2])
+end
+return 1
+`)
+
+// promoteScript atomically moves delayed jobs which are ready for retry back into the queue.
+//
+// KEYS: delayed set, queue list
+// ARGV: current time (unix ms)
+var promoteScript = redis.NewScript(`
+local jobs = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 100)
+for _, job in ipairs(jobs) do
+  redis.call('ZREM', KEYS[1], job)
+  redis.call('LPUSH', KEYS[2], job)
+end
+return #jobs
+`)
+
+// redriveScript atomically moves a job from the dead-letter list back into the queue.
+//
+// KEYS: dead-letter list, queue list
+// ARGV: original payload, new payload
+var redriveScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+  return 0
+end
+redis.call('LPUSH', KEYS[2], ARGV[2])
+return 1
+`)
+
+// RedisWorker is a reliable queue: dequeued jobs are atomically moved into a processing list
+// and only removed from there after they are processed. Each job in progress holds a lease,
+// jobs with expired lease (e.g. the worker crashed) are recovered. Failed jobs are retried
+// according to the retry policy of the job type and then moved into a dead-letter list.
 type RedisWorker struct {
 	// the main client for enqueue and dequeue workers - safe for concurrent use
 	client *redis.Client
@@ -27,9 +75,18 @@ type RedisWorker struct {
 	// handler functions
 	handlers map[JobType]JobHandler
 
+	// retry policies
+	policies map[JobType]RetryPolicy
+
 	// queue for all jobs
 	queueName string
 
+	// list of jobs in progress, hash of their lease deadlines, set of jobs waiting for retry and dead-letter list
+	processingName string
+	leasesName     string
+	delayedName    string
+	deadName       string
+
 	// close channel
 	closeCh chan interface{}
 
@@ -42,7 +99,10 @@ type RedisWorker struct {
 	inFlight int64
 }
 
-var _ JobWorker = &RedisWorker{}
+var (
+	_ JobWorker       = &RedisWorker{}
+	_ DeadLetterQueue = &RedisWorker{}
+)
 
 // NewRedisWorker creates new worker that keeps all jobs in a single queue (list), starts N polling
 // goroutines which fetch jobs from the queue and process them in the same goroutine. Use the
@@ -56,12 +116,17 @@ func NewRedisWorker(address, username, password string, db int, queueName string
 		PoolSize: concurrency + 2, // number of polling goroutines + room for Stats call
 	})
 	return &RedisWorker{
-		handlers:     make(map[JobType]JobHandler),
-		client:       rdb,
-		queueName:    queueName,
-		pollInterval: pollInterval,
-		concurrency:  concurrency,
-		closeCh:      make(chan interface{}),
+		handlers:       make(map[JobType]JobHandler),
+		policies:       make(map[JobType]RetryPolicy),
+		client:         rdb,
+		queueName:      queueName,
+		processingName: queueName + ":processing",
+		leasesName:     queueName + ":leases",
+		delayedName:    queueName + ":delayed",
+		deadName:       queueName + ":dead",
+		pollInterval:   pollInterval,
+		concurrency:    concurrency,
+		closeCh:        make(chan interface{}),
 	}, nil
 }
 
@@ -70,6 +135,17 @@ func (w *RedisWorker) RegisterHandler(jtype JobType, handler JobHandler, args an
 	gob.Register(args)
 }
 
+func (w *RedisWorker) SetRetryPolicy(jtype JobType, policy RetryPolicy) {
+	w.policies[jtype] = policy
+}
+
+func (w *RedisWorker) retryPolicy(jtype JobType) RetryPolicy {
+	if policy, ok := w.policies[jtype]; ok {
+		return policy
+	}
+	return DefaultRetryPolicy
+}
+
 func (w *RedisWorker) Enqueue(ctx context.Context, job *Job) error {
 	var err error
 	if job == nil {
@@ -90,14 +166,12 @@ func (w *RedisWorker) Enqueue(ctx context.Context, job *Job) error {
 		Logger())
 	logger.Info().Msgf("Enqueuing job type %s via Redis", job.Type)
 
-	var buffer bytes.Buffer
-	enc := gob.NewEncoder(&buffer)
-	err = enc.Encode(&job)
+	payload, err := encodeJob(job)
 	if err != nil {
-		return fmt.Errorf("unable to encode args: %w", err)
+		return err
 	}
 
-	cmd := w.client.LPush(ctx, w.queueName, buffer.Bytes())
+	cmd := w.client.LPush(ctx, w.queueName, payload)
 	if cmd.Err() != nil {
 		logger.Error().Err(err).Msg("Unable to push job into Redis")
 		return fmt.Errorf("unable to push job into Redis: %w", cmd.Err())
@@ -126,6 +200,87 @@ func (w *RedisWorker) DequeueLoop(ctx context.Context) {
 		w.loopWG.Add(1)
 		go w.dequeueLoop(ctx, i, w.concurrency)
 	}
+
+	w.loopWG.Add(1)
+	go w.maintenanceLoop(ctx)
+}
+
+// maintenanceLoop periodically moves jobs ready for retry back into the queue and recovers
+// jobs with expired lease. It runs in all worker processes, all operations are atomic.
+func (w *RedisWorker) maintenanceLoop(ctx context.Context) {
+	defer w.loopWG.Done()
+	logger := zerolog.Ctx(ctx)
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.closeCh:
+			logger.Info().Msg("Shutting down a Redis maintenance loop (stop)")
+			return
+		case <-ctx.Done():
+			logger.Info().Msg("Shutting down a Redis maintenance loop (cancel)")
+			return
+		case <-ticker.C:
+			w.promoteDelayed(ctx)
+			w.recoverExpired(ctx)
+		}
+	}
+}
+
+func (w *RedisWorker) promoteDelayed(ctx context.Context) {
+	defer recoverAndLog(ctx)
+	logger := zerolog.Ctx(ctx)
+
+	now := time.Now().UnixMilli()
+	count, err := promoteScript.Run(ctx, w.client, []string{w.delayedName, w.queueName}, now).Int()
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to move delayed jobs into Redis queue")
+		return
+	}
+	if count > 0 {
+		logger.Debug().Msgf("Moved %d delayed job(s) back into Redis queue", count)
+	}
+}
+
+func (w *RedisWorker) recoverExpired(ctx context.Context) {
+	defer recoverAndLog(ctx)
+	logger := zerolog.Ctx(ctx)
+
+	payloads, err := w.client.LRange(ctx, w.processingName, 0, -1).Result()
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to list Redis processing jobs")
+		return
+	}
+
+	now := time.Now()
+	for _, payload := range payloads {
+		job, err := decodeJob(payload)
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to unmarshal processing job payload, moving to dead-letter list")
+			w.bury(ctx, payload, payload, "")
+			continue
+		}
+
+		deadline, err := w.client.HGet(ctx, w.leasesName, job.ID.String()).Int64()
+		if errors.Is(err, redis.Nil) {
+			// worker crashed before the lease was taken, start the lease now
+			w.client.HSetNX(ctx, w.leasesName, job.ID.String(), w.leaseDeadline(now))
+			continue
+		} else if err != nil {
+			logger.Error().Err(err).Msg("Unable to get Redis job lease")
+			continue
+		}
+
+		if now.UnixMilli() > deadline {
+			logger.Warn().Str("job_id", job.ID.String()).Msgf("Lease of job %s expired, recovering", job.ID)
+			w.fail(ctx, job, payload)
+		}
+	}
+}
+
+func (w *RedisWorker) leaseDeadline(now time.Time) int64 {
+	return now.Add(config.Worker.Timeout + leaseMargin).UnixMilli()
 }
 
 func (w *RedisWorker) dequeueLoop(ctx context.Context, i, total int) {
@@ -166,7 +321,7 @@ func recoverAndLog(ctx context.Context) {
 func (w *RedisWorker) fetchJob(ctx context.Context) {
 	defer recoverAndLog(ctx)
 
-	res, err := w.client.BLPop(ctx, w.pollInterval, w.queueName).Result()
+	payload, err := w.client.BLMove(ctx, w.queueName, w.processingName, "RIGHT", "LEFT", w.pollInterval).Result()
 
 	if errors.Is(err, redis.Nil) {
 		// timeout occurred
@@ -177,47 +332,134 @@ func (w *RedisWorker) fetchJob(ctx context.Context) {
 		return
 	}
 
-	var job Job
-	dec := gob.NewDecoder(strings.NewReader(res[1]))
-	err = dec.Decode(&job)
-	logger := ptr.To(zerolog.Ctx(ctx).With().
-		Str("job_id", job.ID.String()).
-		Str("job_type", job.Type.String()).
-		Interface("job_args", job.Args).
-		Logger())
+	job, err := decodeJob(payload)
+	if err != nil {
+		logger := zerolog.Ctx(ctx)
+		logger.Error().Err(err).Msg("Unable to unmarshal job payload, moving to dead-letter list")
+		w.bury(ctx, payload, payload, "")
+		return
+	}
+
+	err = w.client.HSet(ctx, w.leasesName, job.ID.String(), w.leaseDeadline(time.Now())).Err()
 	if err != nil {
-		logger.Error().Err(err).Msg("Unable to unmarshal job payload, skipping")
+		zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to take Redis job lease")
 	}
 
 	atomic.AddInt64(&w.inFlight, 1)
-	w.processJob(ctx, &job)
+	if w.processJob(ctx, job) {
+		w.ack(ctx, job, payload)
+	} else {
+		w.fail(ctx, job, payload)
+	}
 }
 
-func (w *RedisWorker) processJob(origCtx context.Context, job *Job) {
-	if job == nil {
+// ack removes a processed job from the processing list.
+func (w *RedisWorker) ack(ctx context.Context, job *Job, payload string) {
+	_, err := w.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, w.processingName, 1, payload)
+		pipe.HDel(ctx, w.leasesName, job.ID.String())
+		return nil
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("job_id", job.ID.String()).Msg("Unable to acknowledge Redis job")
+	}
+}
+
+// fail removes a failed job from the processing list and schedules a retry or moves the job into
+// the dead-letter list when there are no attempts left.
+func (w *RedisWorker) fail(ctx context.Context, job *Job, payload string) {
+	logger := zerolog.Ctx(ctx).With().Str("job_id", job.ID.String()).Logger()
+	policy := w.retryPolicy(job.Type)
+	job.Attempt++
+
+	newPayload, err := encodeJob(job)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to encode failed job, moving to dead-letter list")
+		w.bury(ctx, payload, payload, job.ID.String())
+		return
+	}
+
+	if job.Attempt >= policy.MaxAttempts {
+		logger.Warn().Int("attempt", job.Attempt).Msgf("Job %s failed %d time(s), moving to dead-letter list", job.ID, job.Attempt)
+		w.bury(ctx, payload, string(newPayload), job.ID.String())
 		return
 	}
+
+	delay := policy.Delay(job.Attempt)
+	retryAt := time.Now().Add(delay).UnixMilli()
+	keys := []string{w.processingName, w.delayedName, w.deadName, w.leasesName}
+	err = failScript.Run(ctx, w.client, keys, payload, newPayload, job.ID.String(), retryAt).Err()
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to schedule Redis job retry")
+		return
+	}
+	logger.Warn().Int("attempt", job.Attempt).Msgf("Job %s failed, retrying in %s", job.ID, delay.String())
+}
+
+// bury moves a job from the processing list into the dead-letter list.
+func (w *RedisWorker) bury(ctx context.Context, payload, newPayload, id string) {
+	keys := []string{w.processingName, w.delayedName, w.deadName, w.leasesName}
+	err := failScript.Run(ctx, w.client, keys, payload, newPayload, id, -1).Err()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Unable to move Redis job into dead-letter list")
+	}
+}
+
+// processJob calls the job handler and returns false when the handler returned an error or panicked.
+func (w *RedisWorker) processJob(ctx context.Context, job *Job) bool {
+	if job == nil {
+		return true
+	}
 	defer atomic.AddInt64(&w.inFlight, -1)
 
-	ctx, logger := contextLogger(origCtx, job)
-	defer recoverAndLog(ctx)
-	logger.Info().Msgf("Dequeued job %s %s from Redis", job.Type.String(), job.ID)
-
-	if h, ok := w.handlers[job.Type]; ok {
-		cCtx, cFunc := context.WithTimeout(ctx, config.Worker.Timeout)
-		defer func() {
-			if c := cCtx.Err(); c != nil {
-				zerolog.Ctx(ctx).Error().Err(c).Msg("Job was either cancelled or timeout occurred")
-			}
-			cFunc()
-		}()
-		metrics.ObserveBackgroundJobDuration(job.Type.String(), func() {
-			h(cCtx, job)
-		})
-	} else {
-		// handler not found
-		zerolog.Ctx(ctx).Warn().Msgf("Redis worker handler not found for job type: %s", job.Type)
+	return runHandler(ctx, w.handlers, job, w.retryPolicy(job.Type), "Redis")
+}
+
+// DeadLetters returns all jobs from the dead-letter list, payloads which cannot be decoded are skipped.
+func (w *RedisWorker) DeadLetters(ctx context.Context) ([]*Job, error) {
+	payloads, err := w.client.LRange(ctx, w.deadName, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list dead-letter jobs: %w", err)
+	}
+
+	result := make([]*Job, 0, len(payloads))
+	for _, payload := range payloads {
+		job, err := decodeJob(payload)
+		if err != nil {
+			zerolog.Ctx(ctx).Warn().Err(err).Msg("Unable to unmarshal dead-letter job payload, skipping")
+			continue
+		}
+		result = append(result, job)
+	}
+	return result, nil
+}
+
+func (w *RedisWorker) Redrive(ctx context.Context, id uuid.UUID) (int, error) {
+	payloads, err := w.client.LRange(ctx, w.deadName, 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("unable to list dead-letter jobs: %w", err)
+	}
+
+	count := 0
+	for _, payload := range payloads {
+		job, err := decodeJob(payload)
+		if err != nil || (id != uuid.Nil && job.ID != id) {
+			continue
+		}
+
+		job.Attempt = 0
+		newPayload, err := encodeJob(job)
+		if err != nil {
+			return count, err
+		}
+
+		moved, err := redriveScript.Run(ctx, w.client, []string{w.deadName, w.queueName}, payload, newPayload).Int()
+		if err != nil {
+			return count, fmt.Errorf("unable to redrive job %s: %w", job.ID, err)
+		}
+		count += moved
 	}
+	return count, nil
 }
 
 func (w *RedisWorker) Stats(ctx context.Context) (Stats, error) {
@@ -226,8 +468,20 @@ func (w *RedisWorker) Stats(ctx context.Context) (Stats, error) {
 		return Stats{}, fmt.Errorf("unable to get queue len: %w", err)
 	}
 
+	delayed, err := w.client.ZCard(ctx, w.delayedName).Result()
+	if err != nil {
+		return Stats{}, fmt.Errorf("unable to get delayed set len: %w", err)
+	}
+
+	dead, err := w.client.LLen(ctx, w.deadName).Result()
+	if err != nil {
+		return Stats{}, fmt.Errorf("unable to get dead-letter list len: %w", err)
+	}
+
 	return Stats{
 		EnqueuedJobs: uint64(count),
 		InFlight:     atomic.LoadInt64(&w.inFlight),
+		DelayedJobs:  uint64(delayed),
+		DeadJobs:     uint64(dead),
 	}, nil
 }